@@ -37,6 +37,7 @@ func getRootCmd() *cobra.Command {
 		getSeriesCommand(),
 		getInstancesCommand(),
 		getDicomWebCommand(),
+		getWorklistCommand(),
 	)
 
 	return cmd
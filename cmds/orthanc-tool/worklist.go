@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	customerv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/customer/v1"
+	dicomv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/dicom/v1"
+	bridgev1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1/bridgev1connect"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func getWorklistCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "worklist",
+	}
+
+	cmd.AddCommand(getTestRulesCommand())
+
+	return cmd
+}
+
+func getTestRulesCommand() *cobra.Command {
+	var (
+		bridge       string
+		token        string
+		scriptFile   string
+		customerFile string
+		patientFile  string
+		scheduled    string
+	)
+
+	req := new(bridgev1.TestWorklistRulesRequest)
+
+	cmd := &cobra.Command{
+		Use:   "test-rules [flags]",
+		Short: "Execute worklist rules against a sample patient without creating a worklist entry",
+		Run: func(cmd *cobra.Command, args []string) {
+			if scriptFile != "" {
+				content, err := os.ReadFile(scriptFile)
+				if err != nil {
+					logrus.Fatalf("failed to read --script: %s", err)
+				}
+
+				req.Script = string(content)
+			}
+
+			if customerFile != "" {
+				req.Customer = new(customerv1.Customer)
+				readProtoJSON(customerFile, req.Customer)
+			}
+
+			if patientFile != "" {
+				req.Patient = new(customerv1.Patient)
+				readProtoJSON(patientFile, req.Patient)
+			}
+
+			if scheduled != "" {
+				t, err := time.ParseInLocation("2006-01-02 15:04", scheduled, time.Local)
+				if err != nil {
+					logrus.Fatalf("invalid value for --scheduled: %s", err)
+				}
+
+				req.ScheduledTime = timestamppb.New(t)
+			}
+
+			cli := bridgev1connect.NewWorklistServiceClient(http.DefaultClient, bridge)
+
+			connectReq := connect.NewRequest(req)
+			if token != "" {
+				connectReq.Header().Set("Authorization", "Bearer "+token)
+			}
+
+			res, err := cli.TestWorklistRules(context.Background(), connectReq)
+			if err != nil {
+				logrus.Fatalf("TestWorklistRules: %s", err)
+			}
+
+			printElements(res.Msg.Elements, "")
+
+			if res.Msg.ReloadError != "" {
+				logrus.Warnf("installed rules could not be reloaded, an older version is still active: %s", res.Msg.ReloadError)
+			}
+
+			for _, e := range res.Msg.Errors {
+				logrus.Errorf("rule error: %s", e)
+			}
+
+			if len(res.Msg.Errors) > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+
+	f := cmd.Flags()
+	{
+		f.StringVar(&bridge, "bridge", os.Getenv("ORTHANC_BRIDGE_URL"), "The address of the orthanc-bridge")
+		f.StringVar(&token, "token", os.Getenv("ORTHANC_BRIDGE_TOKEN"), "The access token used to authenticate against the orthanc-bridge")
+		f.StringVar(&scriptFile, "script", "", "Path to a rule file to test. Defaults to the installed rule set")
+		f.StringVar(&req.CustomerId, "customer-id", "", "The ID of the customer")
+		f.StringVar(&req.PatientId, "patient-id", "", "The ID of the patient")
+		f.StringVar(&customerFile, "customer", "", "Path to a JSON file with a sample customer")
+		f.StringVar(&patientFile, "patient", "", "Path to a JSON file with a sample patient")
+		f.StringVar(&req.Modality, "modality", "CR", "The requested modality")
+		f.StringVar(&scheduled, "scheduled", "", "The scheduled time in the format 2006-01-02 15:04. Defaults to now")
+		f.StringVar(&req.ScheduledStationAeTitle, "station", "", "The AE title of the scheduled station")
+		f.StringVar(&req.Description, "description", "", "The description of the requested procedure")
+	}
+
+	return cmd
+}
+
+func readProtoJSON(path string, msg proto.Message) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		logrus.Fatalf("failed to read %q: %s", path, err)
+	}
+
+	if err := protojson.Unmarshal(content, msg); err != nil {
+		logrus.Fatalf("failed to parse %q: %s", path, err)
+	}
+}
+
+func printElements(elements []*dicomv1.Element, indent string) {
+	for _, el := range elements {
+		prefix := fmt.Sprintf("%s(%04X,%04X) %-2s %s", indent, el.Tag>>16, el.Tag&0xffff, el.RawVr, el.TagName)
+
+		switch v := el.Value.GetValue().(type) {
+		case *dicomv1.Value_Strings:
+			fmt.Printf("%s = %s\n", prefix, strings.Join(v.Strings.GetValues(), `\`))
+
+		case *dicomv1.Value_Ints:
+			fmt.Printf("%s = %v\n", prefix, v.Ints.GetValues())
+
+		case *dicomv1.Value_Floats:
+			fmt.Printf("%s = %v\n", prefix, v.Floats.GetValues())
+
+		case *dicomv1.Value_Bytes:
+			fmt.Printf("%s = <%d bytes>\n", prefix, len(v.Bytes))
+
+		case *dicomv1.Value_Sequences:
+			fmt.Println(prefix)
+
+			for idx, item := range v.Sequences.GetValues() {
+				fmt.Printf("%s  > item %d\n", indent, idx+1)
+				printElements(item.GetValues(), indent+"    ")
+			}
+
+		default:
+			fmt.Println(prefix)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/tierklinik-dobersberg/apis/pkg/log"
 	"github.com/tierklinik-dobersberg/apis/pkg/server"
 	"github.com/tierklinik-dobersberg/apis/pkg/validator"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1/bridgev1connect"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/config"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dicomweb/proxy"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/service"
@@ -51,13 +52,16 @@ func main() {
 		os.Exit(1)
 	}
 
+	roleResolver := auth.NewIDMRoleResolver(providers.Clients.RoleService)
+
 	authInterceptor := auth.NewAuthAnnotationInterceptor(
 		protoregistry.GlobalFiles,
-		auth.NewIDMRoleResolver(providers.Clients.RoleService),
+		roleResolver,
 		auth.RemoteHeaderExtractor)
 
 	interceptors := connect.WithInterceptors(
 		log.NewLoggingInterceptor(),
+		service.NewAuditInterceptor(providers),
 		authInterceptor,
 		validator.NewInterceptor(protoValidator),
 	)
@@ -81,16 +85,19 @@ func main() {
 	}
 
 	// setup reverse proxy routes for each orthanc instance
+	var proxies []service.TokenInvalidator
 	for name, instance := range cfg.Instances {
-		prefix := "/bridge/" + name + "/"
+		prefix := proxy.Prefix(name)
 
-		proxy, err := proxy.New(name, providers.Repo, prefix, publicURL, instance, providers.Clients.AuthService)
+		proxy, err := proxy.New(name, name == cfg.DefaultInstance, providers.Repo, prefix, publicURL, instance, providers.Clients.AuthService, roleResolver, providers.Audit)
 		if err != nil {
 			logger.Error("failed to create dicomweb-proxy", "name", name, "error", err)
 			os.Exit(-1)
 		}
 
 		serveMux.Handle(prefix, http.StripPrefix(prefix, proxy))
+
+		proxies = append(proxies, proxy)
 	}
 
 	// create a new CallService and add it to the mux.
@@ -99,7 +106,26 @@ func main() {
 	path, handler := orthanc_bridgev1connect.NewOrthancBridgeHandler(svc, interceptors)
 	serveMux.Handle(path, handler)
 
+	path, handler = bridgev1connect.NewStudySearchServiceHandler(service.NewStudySearchService(svc), interceptors)
+	serveMux.Handle(path, handler)
+
+	path, handler = bridgev1connect.NewWorklistServiceHandler(service.NewWorklistService(providers), interceptors)
+	serveMux.Handle(path, handler)
+
+	path, handler = bridgev1connect.NewShareServiceHandler(service.NewShareService(providers, proxies...), interceptors)
+	serveMux.Handle(path, handler)
+
+	path, handler = bridgev1connect.NewAuditServiceHandler(service.NewAuditService(providers), interceptors)
+	serveMux.Handle(path, handler)
+
+	path, handler = bridgev1connect.NewDownloadServiceHandler(service.NewDownloadService(providers), interceptors)
+	serveMux.Handle(path, handler)
+
+	path, handler = bridgev1connect.NewExportServiceHandler(service.NewExportService(providers), interceptors)
+	serveMux.Handle(path, handler)
+
 	serveMux.Handle("/download/{id}", providers.Artifacts)
+	serveMux.HandleFunc("/archive/{instance}/{study}", providers.Artifacts.ServeArchive)
 
 	// Create the server
 	srv, err := server.CreateWithOptions(cfg.PublicListenAddress, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,426 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: tkd/orthanc_bridge/v1/audit.proto
+
+package bridgev1
+
+import (
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	_ "github.com/tierklinik-dobersberg/apis/gen/go/tkd/common/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AuditOutcome int32
+
+const (
+	AuditOutcome_AUDIT_OUTCOME_UNSPECIFIED AuditOutcome = 0
+	AuditOutcome_AUDIT_OUTCOME_SUCCESS     AuditOutcome = 1
+	AuditOutcome_AUDIT_OUTCOME_DENIED      AuditOutcome = 2
+	AuditOutcome_AUDIT_OUTCOME_FAILED      AuditOutcome = 3
+)
+
+// Enum value maps for AuditOutcome.
+var (
+	AuditOutcome_name = map[int32]string{
+		0: "AUDIT_OUTCOME_UNSPECIFIED",
+		1: "AUDIT_OUTCOME_SUCCESS",
+		2: "AUDIT_OUTCOME_DENIED",
+		3: "AUDIT_OUTCOME_FAILED",
+	}
+	AuditOutcome_value = map[string]int32{
+		"AUDIT_OUTCOME_UNSPECIFIED": 0,
+		"AUDIT_OUTCOME_SUCCESS":     1,
+		"AUDIT_OUTCOME_DENIED":      2,
+		"AUDIT_OUTCOME_FAILED":      3,
+	}
+)
+
+func (x AuditOutcome) Enum() *AuditOutcome {
+	p := new(AuditOutcome)
+	*p = x
+	return p
+}
+
+func (x AuditOutcome) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AuditOutcome) Descriptor() protoreflect.EnumDescriptor {
+	return file_tkd_orthanc_bridge_v1_audit_proto_enumTypes[0].Descriptor()
+}
+
+func (AuditOutcome) Type() protoreflect.EnumType {
+	return &file_tkd_orthanc_bridge_v1_audit_proto_enumTypes[0]
+}
+
+func (x AuditOutcome) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AuditOutcome.Descriptor instead.
+func (AuditOutcome) EnumDescriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_audit_proto_rawDescGZIP(), []int{0}
+}
+
+type AuditEntry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	// User is the ID of the user that performed the action. It is empty for
+	// requests authorized by a study share and for artifact downloads.
+	User string `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	// ShareToken is set for requests authorized by a study share.
+	ShareToken string `protobuf:"bytes,3,opt,name=share_token,json=shareToken,proto3" json:"share_token,omitempty"`
+	// Action is one of listStudies, downloadStudy, shareStudy, updateShare,
+	// downloadArtifact or proxyRequest.
+	Action        string       `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	Instance      string       `protobuf:"bytes,5,opt,name=instance,proto3" json:"instance,omitempty"`
+	StudyUids     []string     `protobuf:"bytes,6,rep,name=study_uids,json=studyUids,proto3" json:"study_uids,omitempty"`
+	Outcome       AuditOutcome `protobuf:"varint,7,opt,name=outcome,proto3,enum=tkd.orthanc_bridge.v1.AuditOutcome" json:"outcome,omitempty"`
+	Detail        string       `protobuf:"bytes,8,opt,name=detail,proto3" json:"detail,omitempty"`
+	ClientIp      string       `protobuf:"bytes,9,opt,name=client_ip,json=clientIp,proto3" json:"client_ip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditEntry) Reset() {
+	*x = AuditEntry{}
+	mi := &file_tkd_orthanc_bridge_v1_audit_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEntry) ProtoMessage() {}
+
+func (x *AuditEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_audit_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEntry.ProtoReflect.Descriptor instead.
+func (*AuditEntry) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_audit_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AuditEntry) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *AuditEntry) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetShareToken() string {
+	if x != nil {
+		return x.ShareToken
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetStudyUids() []string {
+	if x != nil {
+		return x.StudyUids
+	}
+	return nil
+}
+
+func (x *AuditEntry) GetOutcome() AuditOutcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return AuditOutcome_AUDIT_OUTCOME_UNSPECIFIED
+}
+
+func (x *AuditEntry) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetClientIp() string {
+	if x != nil {
+		return x.ClientIp
+	}
+	return ""
+}
+
+type ListAuditEntriesRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	User     string                 `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	StudyUid string                 `protobuf:"bytes,2,opt,name=study_uid,json=studyUid,proto3" json:"study_uid,omitempty"`
+	Action   string                 `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	// From and To may be set to limit the time range.
+	From *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`
+	// Limit defaults to 100.
+	Limit         int32 `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32 `protobuf:"varint,7,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEntriesRequest) Reset() {
+	*x = ListAuditEntriesRequest{}
+	mi := &file_tkd_orthanc_bridge_v1_audit_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEntriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEntriesRequest) ProtoMessage() {}
+
+func (x *ListAuditEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_audit_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEntriesRequest.ProtoReflect.Descriptor instead.
+func (*ListAuditEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_audit_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListAuditEntriesRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *ListAuditEntriesRequest) GetStudyUid() string {
+	if x != nil {
+		return x.StudyUid
+	}
+	return ""
+}
+
+func (x *ListAuditEntriesRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ListAuditEntriesRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ListAuditEntriesRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *ListAuditEntriesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListAuditEntriesRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListAuditEntriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*AuditEntry          `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEntriesResponse) Reset() {
+	*x = ListAuditEntriesResponse{}
+	mi := &file_tkd_orthanc_bridge_v1_audit_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEntriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEntriesResponse) ProtoMessage() {}
+
+func (x *ListAuditEntriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_audit_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEntriesResponse.ProtoReflect.Descriptor instead.
+func (*ListAuditEntriesResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_audit_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListAuditEntriesResponse) GetEntries() []*AuditEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+var File_tkd_orthanc_bridge_v1_audit_proto protoreflect.FileDescriptor
+
+const file_tkd_orthanc_bridge_v1_audit_proto_rawDesc = "" +
+	"\n" +
+	"!tkd/orthanc_bridge/v1/audit.proto\x12\x15tkd.orthanc_bridge.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1bbuf/validate/validate.proto\x1a\x1etkd/common/v1/descriptor.proto\"\xb8\x02\n" +
+	"\n" +
+	"AuditEntry\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x12\n" +
+	"\x04user\x18\x02 \x01(\tR\x04user\x12\x1f\n" +
+	"\vshare_token\x18\x03 \x01(\tR\n" +
+	"shareToken\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x1a\n" +
+	"\binstance\x18\x05 \x01(\tR\binstance\x12\x1d\n" +
+	"\n" +
+	"study_uids\x18\x06 \x03(\tR\tstudyUids\x12=\n" +
+	"\aoutcome\x18\a \x01(\x0e2#.tkd.orthanc_bridge.v1.AuditOutcomeR\aoutcome\x12\x16\n" +
+	"\x06detail\x18\b \x01(\tR\x06detail\x12\x1b\n" +
+	"\tclient_ip\x18\t \x01(\tR\bclientIp\"\x81\x02\n" +
+	"\x17ListAuditEntriesRequest\x12\x12\n" +
+	"\x04user\x18\x01 \x01(\tR\x04user\x12\x1b\n" +
+	"\tstudy_uid\x18\x02 \x01(\tR\bstudyUid\x12\x16\n" +
+	"\x06action\x18\x03 \x01(\tR\x06action\x12.\n" +
+	"\x04from\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12 \n" +
+	"\x05limit\x18\x06 \x01(\x05B\n" +
+	"\xbaH\a\x1a\x05\x18\xe8\a(\x00R\x05limit\x12\x1f\n" +
+	"\x06offset\x18\a \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\x06offset\"W\n" +
+	"\x18ListAuditEntriesResponse\x12;\n" +
+	"\aentries\x18\x01 \x03(\v2!.tkd.orthanc_bridge.v1.AuditEntryR\aentries*|\n" +
+	"\fAuditOutcome\x12\x1d\n" +
+	"\x19AUDIT_OUTCOME_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15AUDIT_OUTCOME_SUCCESS\x10\x01\x12\x18\n" +
+	"\x14AUDIT_OUTCOME_DENIED\x10\x02\x12\x18\n" +
+	"\x14AUDIT_OUTCOME_FAILED\x10\x032\x8a\x01\n" +
+	"\fAuditService\x12z\n" +
+	"\x10ListAuditEntries\x12..tkd.orthanc_bridge.v1.ListAuditEntriesRequest\x1a/.tkd.orthanc_bridge.v1.ListAuditEntriesResponse\"\x05\xb2~\x02\b\x02BWZUgithub.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1;bridgev1b\x06proto3"
+
+var (
+	file_tkd_orthanc_bridge_v1_audit_proto_rawDescOnce sync.Once
+	file_tkd_orthanc_bridge_v1_audit_proto_rawDescData []byte
+)
+
+func file_tkd_orthanc_bridge_v1_audit_proto_rawDescGZIP() []byte {
+	file_tkd_orthanc_bridge_v1_audit_proto_rawDescOnce.Do(func() {
+		file_tkd_orthanc_bridge_v1_audit_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_audit_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_audit_proto_rawDesc)))
+	})
+	return file_tkd_orthanc_bridge_v1_audit_proto_rawDescData
+}
+
+var file_tkd_orthanc_bridge_v1_audit_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_tkd_orthanc_bridge_v1_audit_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_tkd_orthanc_bridge_v1_audit_proto_goTypes = []any{
+	(AuditOutcome)(0),                // 0: tkd.orthanc_bridge.v1.AuditOutcome
+	(*AuditEntry)(nil),               // 1: tkd.orthanc_bridge.v1.AuditEntry
+	(*ListAuditEntriesRequest)(nil),  // 2: tkd.orthanc_bridge.v1.ListAuditEntriesRequest
+	(*ListAuditEntriesResponse)(nil), // 3: tkd.orthanc_bridge.v1.ListAuditEntriesResponse
+	(*timestamppb.Timestamp)(nil),    // 4: google.protobuf.Timestamp
+}
+var file_tkd_orthanc_bridge_v1_audit_proto_depIdxs = []int32{
+	4, // 0: tkd.orthanc_bridge.v1.AuditEntry.time:type_name -> google.protobuf.Timestamp
+	0, // 1: tkd.orthanc_bridge.v1.AuditEntry.outcome:type_name -> tkd.orthanc_bridge.v1.AuditOutcome
+	4, // 2: tkd.orthanc_bridge.v1.ListAuditEntriesRequest.from:type_name -> google.protobuf.Timestamp
+	4, // 3: tkd.orthanc_bridge.v1.ListAuditEntriesRequest.to:type_name -> google.protobuf.Timestamp
+	1, // 4: tkd.orthanc_bridge.v1.ListAuditEntriesResponse.entries:type_name -> tkd.orthanc_bridge.v1.AuditEntry
+	2, // 5: tkd.orthanc_bridge.v1.AuditService.ListAuditEntries:input_type -> tkd.orthanc_bridge.v1.ListAuditEntriesRequest
+	3, // 6: tkd.orthanc_bridge.v1.AuditService.ListAuditEntries:output_type -> tkd.orthanc_bridge.v1.ListAuditEntriesResponse
+	6, // [6:7] is the sub-list for method output_type
+	5, // [5:6] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_tkd_orthanc_bridge_v1_audit_proto_init() }
+func file_tkd_orthanc_bridge_v1_audit_proto_init() {
+	if File_tkd_orthanc_bridge_v1_audit_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_audit_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_audit_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tkd_orthanc_bridge_v1_audit_proto_goTypes,
+		DependencyIndexes: file_tkd_orthanc_bridge_v1_audit_proto_depIdxs,
+		EnumInfos:         file_tkd_orthanc_bridge_v1_audit_proto_enumTypes,
+		MessageInfos:      file_tkd_orthanc_bridge_v1_audit_proto_msgTypes,
+	}.Build()
+	File_tkd_orthanc_bridge_v1_audit_proto = out.File
+	file_tkd_orthanc_bridge_v1_audit_proto_goTypes = nil
+	file_tkd_orthanc_bridge_v1_audit_proto_depIdxs = nil
+}
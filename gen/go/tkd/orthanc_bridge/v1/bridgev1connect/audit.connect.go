@@ -0,0 +1,109 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: tkd/orthanc_bridge/v1/audit.proto
+
+package bridgev1connect
+
+import (
+	context "context"
+	errors "errors"
+	connect_go "github.com/bufbuild/connect-go"
+	v1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect_go.IsAtLeastVersion0_1_0
+
+const (
+	// AuditServiceName is the fully-qualified name of the AuditService service.
+	AuditServiceName = "tkd.orthanc_bridge.v1.AuditService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// AuditServiceListAuditEntriesProcedure is the fully-qualified name of the AuditService's
+	// ListAuditEntries RPC.
+	AuditServiceListAuditEntriesProcedure = "/tkd.orthanc_bridge.v1.AuditService/ListAuditEntries"
+)
+
+// AuditServiceClient is a client for the tkd.orthanc_bridge.v1.AuditService service.
+type AuditServiceClient interface {
+	// ListAuditEntries returns all audit entries matching the request, most
+	// recent first.
+	ListAuditEntries(context.Context, *connect_go.Request[v1.ListAuditEntriesRequest]) (*connect_go.Response[v1.ListAuditEntriesResponse], error)
+}
+
+// NewAuditServiceClient constructs a client for the tkd.orthanc_bridge.v1.AuditService service. By
+// default, it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses,
+// and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the
+// connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewAuditServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) AuditServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &auditServiceClient{
+		listAuditEntries: connect_go.NewClient[v1.ListAuditEntriesRequest, v1.ListAuditEntriesResponse](
+			httpClient,
+			baseURL+AuditServiceListAuditEntriesProcedure,
+			opts...,
+		),
+	}
+}
+
+// auditServiceClient implements AuditServiceClient.
+type auditServiceClient struct {
+	listAuditEntries *connect_go.Client[v1.ListAuditEntriesRequest, v1.ListAuditEntriesResponse]
+}
+
+// ListAuditEntries calls tkd.orthanc_bridge.v1.AuditService.ListAuditEntries.
+func (c *auditServiceClient) ListAuditEntries(ctx context.Context, req *connect_go.Request[v1.ListAuditEntriesRequest]) (*connect_go.Response[v1.ListAuditEntriesResponse], error) {
+	return c.listAuditEntries.CallUnary(ctx, req)
+}
+
+// AuditServiceHandler is an implementation of the tkd.orthanc_bridge.v1.AuditService service.
+type AuditServiceHandler interface {
+	// ListAuditEntries returns all audit entries matching the request, most
+	// recent first.
+	ListAuditEntries(context.Context, *connect_go.Request[v1.ListAuditEntriesRequest]) (*connect_go.Response[v1.ListAuditEntriesResponse], error)
+}
+
+// NewAuditServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewAuditServiceHandler(svc AuditServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	auditServiceListAuditEntriesHandler := connect_go.NewUnaryHandler(
+		AuditServiceListAuditEntriesProcedure,
+		svc.ListAuditEntries,
+		opts...,
+	)
+	return "/tkd.orthanc_bridge.v1.AuditService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case AuditServiceListAuditEntriesProcedure:
+			auditServiceListAuditEntriesHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedAuditServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedAuditServiceHandler struct{}
+
+func (UnimplementedAuditServiceHandler) ListAuditEntries(context.Context, *connect_go.Request[v1.ListAuditEntriesRequest]) (*connect_go.Response[v1.ListAuditEntriesResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.orthanc_bridge.v1.AuditService.ListAuditEntries is not implemented"))
+}
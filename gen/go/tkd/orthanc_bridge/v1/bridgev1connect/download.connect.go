@@ -0,0 +1,111 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: tkd/orthanc_bridge/v1/download.proto
+
+package bridgev1connect
+
+import (
+	context "context"
+	errors "errors"
+	connect_go "github.com/bufbuild/connect-go"
+	v1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect_go.IsAtLeastVersion0_1_0
+
+const (
+	// DownloadServiceName is the fully-qualified name of the DownloadService service.
+	DownloadServiceName = "tkd.orthanc_bridge.v1.DownloadService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// DownloadServiceCreateDownloadLinkProcedure is the fully-qualified name of the DownloadService's
+	// CreateDownloadLink RPC.
+	DownloadServiceCreateDownloadLinkProcedure = "/tkd.orthanc_bridge.v1.DownloadService/CreateDownloadLink"
+)
+
+// DownloadServiceClient is a client for the tkd.orthanc_bridge.v1.DownloadService service.
+type DownloadServiceClient interface {
+	// CreateDownloadLink creates a new signed download link for an artifact
+	// created using OrthancBridge.DownloadStudy. Only the creator of the
+	// artifact may create download links.
+	CreateDownloadLink(context.Context, *connect_go.Request[v1.CreateDownloadLinkRequest]) (*connect_go.Response[v1.CreateDownloadLinkResponse], error)
+}
+
+// NewDownloadServiceClient constructs a client for the tkd.orthanc_bridge.v1.DownloadService
+// service. By default, it uses the Connect protocol with the binary Protobuf Codec, asks for
+// gzipped responses, and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply
+// the connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewDownloadServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) DownloadServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &downloadServiceClient{
+		createDownloadLink: connect_go.NewClient[v1.CreateDownloadLinkRequest, v1.CreateDownloadLinkResponse](
+			httpClient,
+			baseURL+DownloadServiceCreateDownloadLinkProcedure,
+			opts...,
+		),
+	}
+}
+
+// downloadServiceClient implements DownloadServiceClient.
+type downloadServiceClient struct {
+	createDownloadLink *connect_go.Client[v1.CreateDownloadLinkRequest, v1.CreateDownloadLinkResponse]
+}
+
+// CreateDownloadLink calls tkd.orthanc_bridge.v1.DownloadService.CreateDownloadLink.
+func (c *downloadServiceClient) CreateDownloadLink(ctx context.Context, req *connect_go.Request[v1.CreateDownloadLinkRequest]) (*connect_go.Response[v1.CreateDownloadLinkResponse], error) {
+	return c.createDownloadLink.CallUnary(ctx, req)
+}
+
+// DownloadServiceHandler is an implementation of the tkd.orthanc_bridge.v1.DownloadService service.
+type DownloadServiceHandler interface {
+	// CreateDownloadLink creates a new signed download link for an artifact
+	// created using OrthancBridge.DownloadStudy. Only the creator of the
+	// artifact may create download links.
+	CreateDownloadLink(context.Context, *connect_go.Request[v1.CreateDownloadLinkRequest]) (*connect_go.Response[v1.CreateDownloadLinkResponse], error)
+}
+
+// NewDownloadServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewDownloadServiceHandler(svc DownloadServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	downloadServiceCreateDownloadLinkHandler := connect_go.NewUnaryHandler(
+		DownloadServiceCreateDownloadLinkProcedure,
+		svc.CreateDownloadLink,
+		opts...,
+	)
+	return "/tkd.orthanc_bridge.v1.DownloadService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case DownloadServiceCreateDownloadLinkProcedure:
+			downloadServiceCreateDownloadLinkHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedDownloadServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedDownloadServiceHandler struct{}
+
+func (UnimplementedDownloadServiceHandler) CreateDownloadLink(context.Context, *connect_go.Request[v1.CreateDownloadLinkRequest]) (*connect_go.Response[v1.CreateDownloadLinkResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.orthanc_bridge.v1.DownloadService.CreateDownloadLink is not implemented"))
+}
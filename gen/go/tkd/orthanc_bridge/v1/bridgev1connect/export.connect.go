@@ -0,0 +1,169 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: tkd/orthanc_bridge/v1/export.proto
+
+package bridgev1connect
+
+import (
+	context "context"
+	errors "errors"
+	connect_go "github.com/bufbuild/connect-go"
+	v1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect_go.IsAtLeastVersion0_1_0
+
+const (
+	// ExportServiceName is the fully-qualified name of the ExportService service.
+	ExportServiceName = "tkd.orthanc_bridge.v1.ExportService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// ExportServiceCreateExportJobProcedure is the fully-qualified name of the ExportService's
+	// CreateExportJob RPC.
+	ExportServiceCreateExportJobProcedure = "/tkd.orthanc_bridge.v1.ExportService/CreateExportJob"
+	// ExportServiceGetExportJobProcedure is the fully-qualified name of the ExportService's
+	// GetExportJob RPC.
+	ExportServiceGetExportJobProcedure = "/tkd.orthanc_bridge.v1.ExportService/GetExportJob"
+	// ExportServiceWatchExportJobProcedure is the fully-qualified name of the ExportService's
+	// WatchExportJob RPC.
+	ExportServiceWatchExportJobProcedure = "/tkd.orthanc_bridge.v1.ExportService/WatchExportJob"
+)
+
+// ExportServiceClient is a client for the tkd.orthanc_bridge.v1.ExportService service.
+type ExportServiceClient interface {
+	// CreateExportJob queues a new export job and returns immediately. An
+	// ExportJobEvent is published once the job has finished.
+	CreateExportJob(context.Context, *connect_go.Request[v1.CreateExportJobRequest]) (*connect_go.Response[v1.CreateExportJobResponse], error)
+	// GetExportJob returns the current state of an export job.
+	GetExportJob(context.Context, *connect_go.Request[v1.GetExportJobRequest]) (*connect_go.Response[v1.GetExportJobResponse], error)
+	// WatchExportJob streams the state of an export job whenever it changes
+	// until the job has finished.
+	WatchExportJob(context.Context, *connect_go.Request[v1.WatchExportJobRequest]) (*connect_go.ServerStreamForClient[v1.WatchExportJobResponse], error)
+}
+
+// NewExportServiceClient constructs a client for the tkd.orthanc_bridge.v1.ExportService service.
+// By default, it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped
+// responses, and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the
+// connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewExportServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) ExportServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &exportServiceClient{
+		createExportJob: connect_go.NewClient[v1.CreateExportJobRequest, v1.CreateExportJobResponse](
+			httpClient,
+			baseURL+ExportServiceCreateExportJobProcedure,
+			opts...,
+		),
+		getExportJob: connect_go.NewClient[v1.GetExportJobRequest, v1.GetExportJobResponse](
+			httpClient,
+			baseURL+ExportServiceGetExportJobProcedure,
+			opts...,
+		),
+		watchExportJob: connect_go.NewClient[v1.WatchExportJobRequest, v1.WatchExportJobResponse](
+			httpClient,
+			baseURL+ExportServiceWatchExportJobProcedure,
+			opts...,
+		),
+	}
+}
+
+// exportServiceClient implements ExportServiceClient.
+type exportServiceClient struct {
+	createExportJob *connect_go.Client[v1.CreateExportJobRequest, v1.CreateExportJobResponse]
+	getExportJob    *connect_go.Client[v1.GetExportJobRequest, v1.GetExportJobResponse]
+	watchExportJob  *connect_go.Client[v1.WatchExportJobRequest, v1.WatchExportJobResponse]
+}
+
+// CreateExportJob calls tkd.orthanc_bridge.v1.ExportService.CreateExportJob.
+func (c *exportServiceClient) CreateExportJob(ctx context.Context, req *connect_go.Request[v1.CreateExportJobRequest]) (*connect_go.Response[v1.CreateExportJobResponse], error) {
+	return c.createExportJob.CallUnary(ctx, req)
+}
+
+// GetExportJob calls tkd.orthanc_bridge.v1.ExportService.GetExportJob.
+func (c *exportServiceClient) GetExportJob(ctx context.Context, req *connect_go.Request[v1.GetExportJobRequest]) (*connect_go.Response[v1.GetExportJobResponse], error) {
+	return c.getExportJob.CallUnary(ctx, req)
+}
+
+// WatchExportJob calls tkd.orthanc_bridge.v1.ExportService.WatchExportJob.
+func (c *exportServiceClient) WatchExportJob(ctx context.Context, req *connect_go.Request[v1.WatchExportJobRequest]) (*connect_go.ServerStreamForClient[v1.WatchExportJobResponse], error) {
+	return c.watchExportJob.CallServerStream(ctx, req)
+}
+
+// ExportServiceHandler is an implementation of the tkd.orthanc_bridge.v1.ExportService service.
+type ExportServiceHandler interface {
+	// CreateExportJob queues a new export job and returns immediately. An
+	// ExportJobEvent is published once the job has finished.
+	CreateExportJob(context.Context, *connect_go.Request[v1.CreateExportJobRequest]) (*connect_go.Response[v1.CreateExportJobResponse], error)
+	// GetExportJob returns the current state of an export job.
+	GetExportJob(context.Context, *connect_go.Request[v1.GetExportJobRequest]) (*connect_go.Response[v1.GetExportJobResponse], error)
+	// WatchExportJob streams the state of an export job whenever it changes
+	// until the job has finished.
+	WatchExportJob(context.Context, *connect_go.Request[v1.WatchExportJobRequest], *connect_go.ServerStream[v1.WatchExportJobResponse]) error
+}
+
+// NewExportServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewExportServiceHandler(svc ExportServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	exportServiceCreateExportJobHandler := connect_go.NewUnaryHandler(
+		ExportServiceCreateExportJobProcedure,
+		svc.CreateExportJob,
+		opts...,
+	)
+	exportServiceGetExportJobHandler := connect_go.NewUnaryHandler(
+		ExportServiceGetExportJobProcedure,
+		svc.GetExportJob,
+		opts...,
+	)
+	exportServiceWatchExportJobHandler := connect_go.NewServerStreamHandler(
+		ExportServiceWatchExportJobProcedure,
+		svc.WatchExportJob,
+		opts...,
+	)
+	return "/tkd.orthanc_bridge.v1.ExportService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ExportServiceCreateExportJobProcedure:
+			exportServiceCreateExportJobHandler.ServeHTTP(w, r)
+		case ExportServiceGetExportJobProcedure:
+			exportServiceGetExportJobHandler.ServeHTTP(w, r)
+		case ExportServiceWatchExportJobProcedure:
+			exportServiceWatchExportJobHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedExportServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedExportServiceHandler struct{}
+
+func (UnimplementedExportServiceHandler) CreateExportJob(context.Context, *connect_go.Request[v1.CreateExportJobRequest]) (*connect_go.Response[v1.CreateExportJobResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.orthanc_bridge.v1.ExportService.CreateExportJob is not implemented"))
+}
+
+func (UnimplementedExportServiceHandler) GetExportJob(context.Context, *connect_go.Request[v1.GetExportJobRequest]) (*connect_go.Response[v1.GetExportJobResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.orthanc_bridge.v1.ExportService.GetExportJob is not implemented"))
+}
+
+func (UnimplementedExportServiceHandler) WatchExportJob(context.Context, *connect_go.Request[v1.WatchExportJobRequest], *connect_go.ServerStream[v1.WatchExportJobResponse]) error {
+	return connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.orthanc_bridge.v1.ExportService.WatchExportJob is not implemented"))
+}
@@ -0,0 +1,113 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: tkd/orthanc_bridge/v1/search.proto
+
+package bridgev1connect
+
+import (
+	context "context"
+	errors "errors"
+	connect_go "github.com/bufbuild/connect-go"
+	v1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1"
+	v11 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect_go.IsAtLeastVersion0_1_0
+
+const (
+	// StudySearchServiceName is the fully-qualified name of the StudySearchService service.
+	StudySearchServiceName = "tkd.orthanc_bridge.v1.StudySearchService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// StudySearchServiceListFederatedStudiesProcedure is the fully-qualified name of the
+	// StudySearchService's ListFederatedStudies RPC.
+	StudySearchServiceListFederatedStudiesProcedure = "/tkd.orthanc_bridge.v1.StudySearchService/ListFederatedStudies"
+)
+
+// StudySearchServiceClient is a client for the tkd.orthanc_bridge.v1.StudySearchService service.
+type StudySearchServiceClient interface {
+	// ListFederatedStudies searches all configured orthanc instances at once
+	// and returns the merged results, most recent first. Studies available
+	// on more than one instance are only returned once.
+	ListFederatedStudies(context.Context, *connect_go.Request[v1.ListStudiesRequest]) (*connect_go.Response[v11.ListFederatedStudiesResponse], error)
+}
+
+// NewStudySearchServiceClient constructs a client for the tkd.orthanc_bridge.v1.StudySearchService
+// service. By default, it uses the Connect protocol with the binary Protobuf Codec, asks for
+// gzipped responses, and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply
+// the connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewStudySearchServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) StudySearchServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &studySearchServiceClient{
+		listFederatedStudies: connect_go.NewClient[v1.ListStudiesRequest, v11.ListFederatedStudiesResponse](
+			httpClient,
+			baseURL+StudySearchServiceListFederatedStudiesProcedure,
+			opts...,
+		),
+	}
+}
+
+// studySearchServiceClient implements StudySearchServiceClient.
+type studySearchServiceClient struct {
+	listFederatedStudies *connect_go.Client[v1.ListStudiesRequest, v11.ListFederatedStudiesResponse]
+}
+
+// ListFederatedStudies calls tkd.orthanc_bridge.v1.StudySearchService.ListFederatedStudies.
+func (c *studySearchServiceClient) ListFederatedStudies(ctx context.Context, req *connect_go.Request[v1.ListStudiesRequest]) (*connect_go.Response[v11.ListFederatedStudiesResponse], error) {
+	return c.listFederatedStudies.CallUnary(ctx, req)
+}
+
+// StudySearchServiceHandler is an implementation of the tkd.orthanc_bridge.v1.StudySearchService
+// service.
+type StudySearchServiceHandler interface {
+	// ListFederatedStudies searches all configured orthanc instances at once
+	// and returns the merged results, most recent first. Studies available
+	// on more than one instance are only returned once.
+	ListFederatedStudies(context.Context, *connect_go.Request[v1.ListStudiesRequest]) (*connect_go.Response[v11.ListFederatedStudiesResponse], error)
+}
+
+// NewStudySearchServiceHandler builds an HTTP handler from the service implementation. It returns
+// the path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewStudySearchServiceHandler(svc StudySearchServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	studySearchServiceListFederatedStudiesHandler := connect_go.NewUnaryHandler(
+		StudySearchServiceListFederatedStudiesProcedure,
+		svc.ListFederatedStudies,
+		opts...,
+	)
+	return "/tkd.orthanc_bridge.v1.StudySearchService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case StudySearchServiceListFederatedStudiesProcedure:
+			studySearchServiceListFederatedStudiesHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedStudySearchServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedStudySearchServiceHandler struct{}
+
+func (UnimplementedStudySearchServiceHandler) ListFederatedStudies(context.Context, *connect_go.Request[v1.ListStudiesRequest]) (*connect_go.Response[v11.ListFederatedStudiesResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.orthanc_bridge.v1.StudySearchService.ListFederatedStudies is not implemented"))
+}
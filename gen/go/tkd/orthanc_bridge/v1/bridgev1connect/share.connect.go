@@ -0,0 +1,230 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: tkd/orthanc_bridge/v1/share.proto
+
+package bridgev1connect
+
+import (
+	context "context"
+	errors "errors"
+	connect_go "github.com/bufbuild/connect-go"
+	v1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect_go.IsAtLeastVersion0_1_0
+
+const (
+	// ShareServiceName is the fully-qualified name of the ShareService service.
+	ShareServiceName = "tkd.orthanc_bridge.v1.ShareService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// ShareServiceCreateShareProcedure is the fully-qualified name of the ShareService's CreateShare
+	// RPC.
+	ShareServiceCreateShareProcedure = "/tkd.orthanc_bridge.v1.ShareService/CreateShare"
+	// ShareServiceListSharesProcedure is the fully-qualified name of the ShareService's ListShares RPC.
+	ShareServiceListSharesProcedure = "/tkd.orthanc_bridge.v1.ShareService/ListShares"
+	// ShareServiceRevokeShareProcedure is the fully-qualified name of the ShareService's RevokeShare
+	// RPC.
+	ShareServiceRevokeShareProcedure = "/tkd.orthanc_bridge.v1.ShareService/RevokeShare"
+	// ShareServiceListShareAccessProcedure is the fully-qualified name of the ShareService's
+	// ListShareAccess RPC.
+	ShareServiceListShareAccessProcedure = "/tkd.orthanc_bridge.v1.ShareService/ListShareAccess"
+	// ShareServiceUpdateShareExpirationProcedure is the fully-qualified name of the ShareService's
+	// UpdateShareExpiration RPC.
+	ShareServiceUpdateShareExpirationProcedure = "/tkd.orthanc_bridge.v1.ShareService/UpdateShareExpiration"
+)
+
+// ShareServiceClient is a client for the tkd.orthanc_bridge.v1.ShareService service.
+type ShareServiceClient interface {
+	// CreateShare shares a study like OrthancBridge.ShareStudy and delivers
+	// the viewer link to all recipients.
+	CreateShare(context.Context, *connect_go.Request[v1.CreateShareRequest]) (*connect_go.Response[v1.CreateShareResponse], error)
+	// ListShares returns all study shares matching the request.
+	ListShares(context.Context, *connect_go.Request[v1.ListSharesRequest]) (*connect_go.Response[v1.ListSharesResponse], error)
+	// RevokeShare revokes a study share. The share token cannot be used
+	// anymore once this call returns.
+	RevokeShare(context.Context, *connect_go.Request[v1.RevokeShareRequest]) (*connect_go.Response[v1.RevokeShareResponse], error)
+	// ListShareAccess returns the access history of a study share, most
+	// recent first.
+	ListShareAccess(context.Context, *connect_go.Request[v1.ListShareAccessRequest]) (*connect_go.Response[v1.ListShareAccessResponse], error)
+	// UpdateShareExpiration extends or shortens the validity of a study
+	// share.
+	UpdateShareExpiration(context.Context, *connect_go.Request[v1.UpdateShareExpirationRequest]) (*connect_go.Response[v1.UpdateShareExpirationResponse], error)
+}
+
+// NewShareServiceClient constructs a client for the tkd.orthanc_bridge.v1.ShareService service. By
+// default, it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses,
+// and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the
+// connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewShareServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) ShareServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &shareServiceClient{
+		createShare: connect_go.NewClient[v1.CreateShareRequest, v1.CreateShareResponse](
+			httpClient,
+			baseURL+ShareServiceCreateShareProcedure,
+			opts...,
+		),
+		listShares: connect_go.NewClient[v1.ListSharesRequest, v1.ListSharesResponse](
+			httpClient,
+			baseURL+ShareServiceListSharesProcedure,
+			opts...,
+		),
+		revokeShare: connect_go.NewClient[v1.RevokeShareRequest, v1.RevokeShareResponse](
+			httpClient,
+			baseURL+ShareServiceRevokeShareProcedure,
+			opts...,
+		),
+		listShareAccess: connect_go.NewClient[v1.ListShareAccessRequest, v1.ListShareAccessResponse](
+			httpClient,
+			baseURL+ShareServiceListShareAccessProcedure,
+			opts...,
+		),
+		updateShareExpiration: connect_go.NewClient[v1.UpdateShareExpirationRequest, v1.UpdateShareExpirationResponse](
+			httpClient,
+			baseURL+ShareServiceUpdateShareExpirationProcedure,
+			opts...,
+		),
+	}
+}
+
+// shareServiceClient implements ShareServiceClient.
+type shareServiceClient struct {
+	createShare           *connect_go.Client[v1.CreateShareRequest, v1.CreateShareResponse]
+	listShares            *connect_go.Client[v1.ListSharesRequest, v1.ListSharesResponse]
+	revokeShare           *connect_go.Client[v1.RevokeShareRequest, v1.RevokeShareResponse]
+	listShareAccess       *connect_go.Client[v1.ListShareAccessRequest, v1.ListShareAccessResponse]
+	updateShareExpiration *connect_go.Client[v1.UpdateShareExpirationRequest, v1.UpdateShareExpirationResponse]
+}
+
+// CreateShare calls tkd.orthanc_bridge.v1.ShareService.CreateShare.
+func (c *shareServiceClient) CreateShare(ctx context.Context, req *connect_go.Request[v1.CreateShareRequest]) (*connect_go.Response[v1.CreateShareResponse], error) {
+	return c.createShare.CallUnary(ctx, req)
+}
+
+// ListShares calls tkd.orthanc_bridge.v1.ShareService.ListShares.
+func (c *shareServiceClient) ListShares(ctx context.Context, req *connect_go.Request[v1.ListSharesRequest]) (*connect_go.Response[v1.ListSharesResponse], error) {
+	return c.listShares.CallUnary(ctx, req)
+}
+
+// RevokeShare calls tkd.orthanc_bridge.v1.ShareService.RevokeShare.
+func (c *shareServiceClient) RevokeShare(ctx context.Context, req *connect_go.Request[v1.RevokeShareRequest]) (*connect_go.Response[v1.RevokeShareResponse], error) {
+	return c.revokeShare.CallUnary(ctx, req)
+}
+
+// ListShareAccess calls tkd.orthanc_bridge.v1.ShareService.ListShareAccess.
+func (c *shareServiceClient) ListShareAccess(ctx context.Context, req *connect_go.Request[v1.ListShareAccessRequest]) (*connect_go.Response[v1.ListShareAccessResponse], error) {
+	return c.listShareAccess.CallUnary(ctx, req)
+}
+
+// UpdateShareExpiration calls tkd.orthanc_bridge.v1.ShareService.UpdateShareExpiration.
+func (c *shareServiceClient) UpdateShareExpiration(ctx context.Context, req *connect_go.Request[v1.UpdateShareExpirationRequest]) (*connect_go.Response[v1.UpdateShareExpirationResponse], error) {
+	return c.updateShareExpiration.CallUnary(ctx, req)
+}
+
+// ShareServiceHandler is an implementation of the tkd.orthanc_bridge.v1.ShareService service.
+type ShareServiceHandler interface {
+	// CreateShare shares a study like OrthancBridge.ShareStudy and delivers
+	// the viewer link to all recipients.
+	CreateShare(context.Context, *connect_go.Request[v1.CreateShareRequest]) (*connect_go.Response[v1.CreateShareResponse], error)
+	// ListShares returns all study shares matching the request.
+	ListShares(context.Context, *connect_go.Request[v1.ListSharesRequest]) (*connect_go.Response[v1.ListSharesResponse], error)
+	// RevokeShare revokes a study share. The share token cannot be used
+	// anymore once this call returns.
+	RevokeShare(context.Context, *connect_go.Request[v1.RevokeShareRequest]) (*connect_go.Response[v1.RevokeShareResponse], error)
+	// ListShareAccess returns the access history of a study share, most
+	// recent first.
+	ListShareAccess(context.Context, *connect_go.Request[v1.ListShareAccessRequest]) (*connect_go.Response[v1.ListShareAccessResponse], error)
+	// UpdateShareExpiration extends or shortens the validity of a study
+	// share.
+	UpdateShareExpiration(context.Context, *connect_go.Request[v1.UpdateShareExpirationRequest]) (*connect_go.Response[v1.UpdateShareExpirationResponse], error)
+}
+
+// NewShareServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewShareServiceHandler(svc ShareServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	shareServiceCreateShareHandler := connect_go.NewUnaryHandler(
+		ShareServiceCreateShareProcedure,
+		svc.CreateShare,
+		opts...,
+	)
+	shareServiceListSharesHandler := connect_go.NewUnaryHandler(
+		ShareServiceListSharesProcedure,
+		svc.ListShares,
+		opts...,
+	)
+	shareServiceRevokeShareHandler := connect_go.NewUnaryHandler(
+		ShareServiceRevokeShareProcedure,
+		svc.RevokeShare,
+		opts...,
+	)
+	shareServiceListShareAccessHandler := connect_go.NewUnaryHandler(
+		ShareServiceListShareAccessProcedure,
+		svc.ListShareAccess,
+		opts...,
+	)
+	shareServiceUpdateShareExpirationHandler := connect_go.NewUnaryHandler(
+		ShareServiceUpdateShareExpirationProcedure,
+		svc.UpdateShareExpiration,
+		opts...,
+	)
+	return "/tkd.orthanc_bridge.v1.ShareService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ShareServiceCreateShareProcedure:
+			shareServiceCreateShareHandler.ServeHTTP(w, r)
+		case ShareServiceListSharesProcedure:
+			shareServiceListSharesHandler.ServeHTTP(w, r)
+		case ShareServiceRevokeShareProcedure:
+			shareServiceRevokeShareHandler.ServeHTTP(w, r)
+		case ShareServiceListShareAccessProcedure:
+			shareServiceListShareAccessHandler.ServeHTTP(w, r)
+		case ShareServiceUpdateShareExpirationProcedure:
+			shareServiceUpdateShareExpirationHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedShareServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedShareServiceHandler struct{}
+
+func (UnimplementedShareServiceHandler) CreateShare(context.Context, *connect_go.Request[v1.CreateShareRequest]) (*connect_go.Response[v1.CreateShareResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.orthanc_bridge.v1.ShareService.CreateShare is not implemented"))
+}
+
+func (UnimplementedShareServiceHandler) ListShares(context.Context, *connect_go.Request[v1.ListSharesRequest]) (*connect_go.Response[v1.ListSharesResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.orthanc_bridge.v1.ShareService.ListShares is not implemented"))
+}
+
+func (UnimplementedShareServiceHandler) RevokeShare(context.Context, *connect_go.Request[v1.RevokeShareRequest]) (*connect_go.Response[v1.RevokeShareResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.orthanc_bridge.v1.ShareService.RevokeShare is not implemented"))
+}
+
+func (UnimplementedShareServiceHandler) ListShareAccess(context.Context, *connect_go.Request[v1.ListShareAccessRequest]) (*connect_go.Response[v1.ListShareAccessResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.orthanc_bridge.v1.ShareService.ListShareAccess is not implemented"))
+}
+
+func (UnimplementedShareServiceHandler) UpdateShareExpiration(context.Context, *connect_go.Request[v1.UpdateShareExpirationRequest]) (*connect_go.Response[v1.UpdateShareExpirationResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.orthanc_bridge.v1.ShareService.UpdateShareExpiration is not implemented"))
+}
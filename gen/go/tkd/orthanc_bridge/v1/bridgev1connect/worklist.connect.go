@@ -0,0 +1,205 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: tkd/orthanc_bridge/v1/worklist.proto
+
+package bridgev1connect
+
+import (
+	context "context"
+	errors "errors"
+	connect_go "github.com/bufbuild/connect-go"
+	v1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect_go.IsAtLeastVersion0_1_0
+
+const (
+	// WorklistServiceName is the fully-qualified name of the WorklistService service.
+	WorklistServiceName = "tkd.orthanc_bridge.v1.WorklistService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// WorklistServiceCreateWorklistEntryProcedure is the fully-qualified name of the WorklistService's
+	// CreateWorklistEntry RPC.
+	WorklistServiceCreateWorklistEntryProcedure = "/tkd.orthanc_bridge.v1.WorklistService/CreateWorklistEntry"
+	// WorklistServiceDeleteWorklistEntryProcedure is the fully-qualified name of the WorklistService's
+	// DeleteWorklistEntry RPC.
+	WorklistServiceDeleteWorklistEntryProcedure = "/tkd.orthanc_bridge.v1.WorklistService/DeleteWorklistEntry"
+	// WorklistServiceUpdateWorklistEntryProcedure is the fully-qualified name of the WorklistService's
+	// UpdateWorklistEntry RPC.
+	WorklistServiceUpdateWorklistEntryProcedure = "/tkd.orthanc_bridge.v1.WorklistService/UpdateWorklistEntry"
+	// WorklistServiceTestWorklistRulesProcedure is the fully-qualified name of the WorklistService's
+	// TestWorklistRules RPC.
+	WorklistServiceTestWorklistRulesProcedure = "/tkd.orthanc_bridge.v1.WorklistService/TestWorklistRules"
+)
+
+// WorklistServiceClient is a client for the tkd.orthanc_bridge.v1.WorklistService service.
+type WorklistServiceClient interface {
+	// CreateWorklistEntry creates a new DICOM Modality Worklist entry for a
+	// patient. The DICOM dataset is generated by the configured worklist
+	// rules.
+	CreateWorklistEntry(context.Context, *connect_go.Request[v1.CreateWorklistEntryRequest]) (*connect_go.Response[v1.CreateWorklistEntryResponse], error)
+	// DeleteWorklistEntry removes a worklist entry.
+	DeleteWorklistEntry(context.Context, *connect_go.Request[v1.DeleteWorklistEntryRequest]) (*connect_go.Response[emptypb.Empty], error)
+	// UpdateWorklistEntry replaces selected elements of an existing worklist
+	// entry.
+	UpdateWorklistEntry(context.Context, *connect_go.Request[v1.UpdateWorklistEntryRequest]) (*connect_go.Response[v1.UpdateWorklistEntryResponse], error)
+	// TestWorklistRules executes a rule script, or the installed rule set,
+	// for a customer and patient and returns the generated dataset. Nothing
+	// is written to the worklist directory.
+	TestWorklistRules(context.Context, *connect_go.Request[v1.TestWorklistRulesRequest]) (*connect_go.Response[v1.TestWorklistRulesResponse], error)
+}
+
+// NewWorklistServiceClient constructs a client for the tkd.orthanc_bridge.v1.WorklistService
+// service. By default, it uses the Connect protocol with the binary Protobuf Codec, asks for
+// gzipped responses, and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply
+// the connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewWorklistServiceClient(httpClient connect_go.HTTPClient, baseURL string, opts ...connect_go.ClientOption) WorklistServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &worklistServiceClient{
+		createWorklistEntry: connect_go.NewClient[v1.CreateWorklistEntryRequest, v1.CreateWorklistEntryResponse](
+			httpClient,
+			baseURL+WorklistServiceCreateWorklistEntryProcedure,
+			opts...,
+		),
+		deleteWorklistEntry: connect_go.NewClient[v1.DeleteWorklistEntryRequest, emptypb.Empty](
+			httpClient,
+			baseURL+WorklistServiceDeleteWorklistEntryProcedure,
+			opts...,
+		),
+		updateWorklistEntry: connect_go.NewClient[v1.UpdateWorklistEntryRequest, v1.UpdateWorklistEntryResponse](
+			httpClient,
+			baseURL+WorklistServiceUpdateWorklistEntryProcedure,
+			opts...,
+		),
+		testWorklistRules: connect_go.NewClient[v1.TestWorklistRulesRequest, v1.TestWorklistRulesResponse](
+			httpClient,
+			baseURL+WorklistServiceTestWorklistRulesProcedure,
+			opts...,
+		),
+	}
+}
+
+// worklistServiceClient implements WorklistServiceClient.
+type worklistServiceClient struct {
+	createWorklistEntry *connect_go.Client[v1.CreateWorklistEntryRequest, v1.CreateWorklistEntryResponse]
+	deleteWorklistEntry *connect_go.Client[v1.DeleteWorklistEntryRequest, emptypb.Empty]
+	updateWorklistEntry *connect_go.Client[v1.UpdateWorklistEntryRequest, v1.UpdateWorklistEntryResponse]
+	testWorklistRules   *connect_go.Client[v1.TestWorklistRulesRequest, v1.TestWorklistRulesResponse]
+}
+
+// CreateWorklistEntry calls tkd.orthanc_bridge.v1.WorklistService.CreateWorklistEntry.
+func (c *worklistServiceClient) CreateWorklistEntry(ctx context.Context, req *connect_go.Request[v1.CreateWorklistEntryRequest]) (*connect_go.Response[v1.CreateWorklistEntryResponse], error) {
+	return c.createWorklistEntry.CallUnary(ctx, req)
+}
+
+// DeleteWorklistEntry calls tkd.orthanc_bridge.v1.WorklistService.DeleteWorklistEntry.
+func (c *worklistServiceClient) DeleteWorklistEntry(ctx context.Context, req *connect_go.Request[v1.DeleteWorklistEntryRequest]) (*connect_go.Response[emptypb.Empty], error) {
+	return c.deleteWorklistEntry.CallUnary(ctx, req)
+}
+
+// UpdateWorklistEntry calls tkd.orthanc_bridge.v1.WorklistService.UpdateWorklistEntry.
+func (c *worklistServiceClient) UpdateWorklistEntry(ctx context.Context, req *connect_go.Request[v1.UpdateWorklistEntryRequest]) (*connect_go.Response[v1.UpdateWorklistEntryResponse], error) {
+	return c.updateWorklistEntry.CallUnary(ctx, req)
+}
+
+// TestWorklistRules calls tkd.orthanc_bridge.v1.WorklistService.TestWorklistRules.
+func (c *worklistServiceClient) TestWorklistRules(ctx context.Context, req *connect_go.Request[v1.TestWorklistRulesRequest]) (*connect_go.Response[v1.TestWorklistRulesResponse], error) {
+	return c.testWorklistRules.CallUnary(ctx, req)
+}
+
+// WorklistServiceHandler is an implementation of the tkd.orthanc_bridge.v1.WorklistService service.
+type WorklistServiceHandler interface {
+	// CreateWorklistEntry creates a new DICOM Modality Worklist entry for a
+	// patient. The DICOM dataset is generated by the configured worklist
+	// rules.
+	CreateWorklistEntry(context.Context, *connect_go.Request[v1.CreateWorklistEntryRequest]) (*connect_go.Response[v1.CreateWorklistEntryResponse], error)
+	// DeleteWorklistEntry removes a worklist entry.
+	DeleteWorklistEntry(context.Context, *connect_go.Request[v1.DeleteWorklistEntryRequest]) (*connect_go.Response[emptypb.Empty], error)
+	// UpdateWorklistEntry replaces selected elements of an existing worklist
+	// entry.
+	UpdateWorklistEntry(context.Context, *connect_go.Request[v1.UpdateWorklistEntryRequest]) (*connect_go.Response[v1.UpdateWorklistEntryResponse], error)
+	// TestWorklistRules executes a rule script, or the installed rule set,
+	// for a customer and patient and returns the generated dataset. Nothing
+	// is written to the worklist directory.
+	TestWorklistRules(context.Context, *connect_go.Request[v1.TestWorklistRulesRequest]) (*connect_go.Response[v1.TestWorklistRulesResponse], error)
+}
+
+// NewWorklistServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewWorklistServiceHandler(svc WorklistServiceHandler, opts ...connect_go.HandlerOption) (string, http.Handler) {
+	worklistServiceCreateWorklistEntryHandler := connect_go.NewUnaryHandler(
+		WorklistServiceCreateWorklistEntryProcedure,
+		svc.CreateWorklistEntry,
+		opts...,
+	)
+	worklistServiceDeleteWorklistEntryHandler := connect_go.NewUnaryHandler(
+		WorklistServiceDeleteWorklistEntryProcedure,
+		svc.DeleteWorklistEntry,
+		opts...,
+	)
+	worklistServiceUpdateWorklistEntryHandler := connect_go.NewUnaryHandler(
+		WorklistServiceUpdateWorklistEntryProcedure,
+		svc.UpdateWorklistEntry,
+		opts...,
+	)
+	worklistServiceTestWorklistRulesHandler := connect_go.NewUnaryHandler(
+		WorklistServiceTestWorklistRulesProcedure,
+		svc.TestWorklistRules,
+		opts...,
+	)
+	return "/tkd.orthanc_bridge.v1.WorklistService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case WorklistServiceCreateWorklistEntryProcedure:
+			worklistServiceCreateWorklistEntryHandler.ServeHTTP(w, r)
+		case WorklistServiceDeleteWorklistEntryProcedure:
+			worklistServiceDeleteWorklistEntryHandler.ServeHTTP(w, r)
+		case WorklistServiceUpdateWorklistEntryProcedure:
+			worklistServiceUpdateWorklistEntryHandler.ServeHTTP(w, r)
+		case WorklistServiceTestWorklistRulesProcedure:
+			worklistServiceTestWorklistRulesHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedWorklistServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedWorklistServiceHandler struct{}
+
+func (UnimplementedWorklistServiceHandler) CreateWorklistEntry(context.Context, *connect_go.Request[v1.CreateWorklistEntryRequest]) (*connect_go.Response[v1.CreateWorklistEntryResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.orthanc_bridge.v1.WorklistService.CreateWorklistEntry is not implemented"))
+}
+
+func (UnimplementedWorklistServiceHandler) DeleteWorklistEntry(context.Context, *connect_go.Request[v1.DeleteWorklistEntryRequest]) (*connect_go.Response[emptypb.Empty], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.orthanc_bridge.v1.WorklistService.DeleteWorklistEntry is not implemented"))
+}
+
+func (UnimplementedWorklistServiceHandler) UpdateWorklistEntry(context.Context, *connect_go.Request[v1.UpdateWorklistEntryRequest]) (*connect_go.Response[v1.UpdateWorklistEntryResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.orthanc_bridge.v1.WorklistService.UpdateWorklistEntry is not implemented"))
+}
+
+func (UnimplementedWorklistServiceHandler) TestWorklistRules(context.Context, *connect_go.Request[v1.TestWorklistRulesRequest]) (*connect_go.Response[v1.TestWorklistRulesResponse], error) {
+	return nil, connect_go.NewError(connect_go.CodeUnimplemented, errors.New("tkd.orthanc_bridge.v1.WorklistService.TestWorklistRules is not implemented"))
+}
@@ -0,0 +1,340 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: tkd/orthanc_bridge/v1/download.proto
+
+package bridgev1
+
+import (
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	_ "github.com/tierklinik-dobersberg/apis/gen/go/tkd/common/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Artifact struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreateTime *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	ExpireTime *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expire_time,json=expireTime,proto3" json:"expire_time,omitempty"`
+	Creator    string                 `protobuf:"bytes,4,opt,name=creator,proto3" json:"creator,omitempty"`
+	Instance   string                 `protobuf:"bytes,5,opt,name=instance,proto3" json:"instance,omitempty"`
+	StudyUid   string                 `protobuf:"bytes,6,opt,name=study_uid,json=studyUid,proto3" json:"study_uid,omitempty"`
+	// Downloads counts how often the artifact has been downloaded.
+	Downloads        int32                  `protobuf:"varint,7,opt,name=downloads,proto3" json:"downloads,omitempty"`
+	LastDownloadTime *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=last_download_time,json=lastDownloadTime,proto3" json:"last_download_time,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Artifact) Reset() {
+	*x = Artifact{}
+	mi := &file_tkd_orthanc_bridge_v1_download_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Artifact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Artifact) ProtoMessage() {}
+
+func (x *Artifact) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_download_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Artifact.ProtoReflect.Descriptor instead.
+func (*Artifact) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_download_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Artifact) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Artifact) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *Artifact) GetExpireTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpireTime
+	}
+	return nil
+}
+
+func (x *Artifact) GetCreator() string {
+	if x != nil {
+		return x.Creator
+	}
+	return ""
+}
+
+func (x *Artifact) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *Artifact) GetStudyUid() string {
+	if x != nil {
+		return x.StudyUid
+	}
+	return ""
+}
+
+func (x *Artifact) GetDownloads() int32 {
+	if x != nil {
+		return x.Downloads
+	}
+	return 0
+}
+
+func (x *Artifact) GetLastDownloadTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastDownloadTime
+	}
+	return nil
+}
+
+type CreateDownloadLinkRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Artifact holds either the artifact ID or a download link returned by
+	// OrthancBridge.DownloadStudy.
+	Artifact string `protobuf:"bytes,1,opt,name=artifact,proto3" json:"artifact,omitempty"`
+	// ValidDuration limits the validity of the link. Links never outlive
+	// the artifact.
+	ValidDuration *durationpb.Duration `protobuf:"bytes,2,opt,name=valid_duration,json=validDuration,proto3" json:"valid_duration,omitempty"`
+	// OneTime creates a link that can only be used for a single download.
+	OneTime       bool `protobuf:"varint,3,opt,name=one_time,json=oneTime,proto3" json:"one_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDownloadLinkRequest) Reset() {
+	*x = CreateDownloadLinkRequest{}
+	mi := &file_tkd_orthanc_bridge_v1_download_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDownloadLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDownloadLinkRequest) ProtoMessage() {}
+
+func (x *CreateDownloadLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_download_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDownloadLinkRequest.ProtoReflect.Descriptor instead.
+func (*CreateDownloadLinkRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_download_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateDownloadLinkRequest) GetArtifact() string {
+	if x != nil {
+		return x.Artifact
+	}
+	return ""
+}
+
+func (x *CreateDownloadLinkRequest) GetValidDuration() *durationpb.Duration {
+	if x != nil {
+		return x.ValidDuration
+	}
+	return nil
+}
+
+func (x *CreateDownloadLinkRequest) GetOneTime() bool {
+	if x != nil {
+		return x.OneTime
+	}
+	return false
+}
+
+type CreateDownloadLinkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DownloadLink  string                 `protobuf:"bytes,1,opt,name=download_link,json=downloadLink,proto3" json:"download_link,omitempty"`
+	ExpireTime    *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expire_time,json=expireTime,proto3" json:"expire_time,omitempty"`
+	Artifact      *Artifact              `protobuf:"bytes,3,opt,name=artifact,proto3" json:"artifact,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDownloadLinkResponse) Reset() {
+	*x = CreateDownloadLinkResponse{}
+	mi := &file_tkd_orthanc_bridge_v1_download_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDownloadLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDownloadLinkResponse) ProtoMessage() {}
+
+func (x *CreateDownloadLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_download_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDownloadLinkResponse.ProtoReflect.Descriptor instead.
+func (*CreateDownloadLinkResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_download_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateDownloadLinkResponse) GetDownloadLink() string {
+	if x != nil {
+		return x.DownloadLink
+	}
+	return ""
+}
+
+func (x *CreateDownloadLinkResponse) GetExpireTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpireTime
+	}
+	return nil
+}
+
+func (x *CreateDownloadLinkResponse) GetArtifact() *Artifact {
+	if x != nil {
+		return x.Artifact
+	}
+	return nil
+}
+
+var File_tkd_orthanc_bridge_v1_download_proto protoreflect.FileDescriptor
+
+const file_tkd_orthanc_bridge_v1_download_proto_rawDesc = "" +
+	"\n" +
+	"$tkd/orthanc_bridge/v1/download.proto\x12\x15tkd.orthanc_bridge.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1bbuf/validate/validate.proto\x1a\x1etkd/common/v1/descriptor.proto\"\xcf\x02\n" +
+	"\bArtifact\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12;\n" +
+	"\vcreate_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createTime\x12;\n" +
+	"\vexpire_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"expireTime\x12\x18\n" +
+	"\acreator\x18\x04 \x01(\tR\acreator\x12\x1a\n" +
+	"\binstance\x18\x05 \x01(\tR\binstance\x12\x1b\n" +
+	"\tstudy_uid\x18\x06 \x01(\tR\bstudyUid\x12\x1c\n" +
+	"\tdownloads\x18\a \x01(\x05R\tdownloads\x12H\n" +
+	"\x12last_download_time\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\x10lastDownloadTime\"\x9d\x01\n" +
+	"\x19CreateDownloadLinkRequest\x12#\n" +
+	"\bartifact\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\bartifact\x12@\n" +
+	"\x0evalid_duration\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\rvalidDuration\x12\x19\n" +
+	"\bone_time\x18\x03 \x01(\bR\aoneTime\"\xbb\x01\n" +
+	"\x1aCreateDownloadLinkResponse\x12#\n" +
+	"\rdownload_link\x18\x01 \x01(\tR\fdownloadLink\x12;\n" +
+	"\vexpire_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"expireTime\x12;\n" +
+	"\bartifact\x18\x03 \x01(\v2\x1f.tkd.orthanc_bridge.v1.ArtifactR\bartifact2\x94\x01\n" +
+	"\x0fDownloadService\x12\x80\x01\n" +
+	"\x12CreateDownloadLink\x120.tkd.orthanc_bridge.v1.CreateDownloadLinkRequest\x1a1.tkd.orthanc_bridge.v1.CreateDownloadLinkResponse\"\x05\xb2~\x02\b\x01BWZUgithub.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1;bridgev1b\x06proto3"
+
+var (
+	file_tkd_orthanc_bridge_v1_download_proto_rawDescOnce sync.Once
+	file_tkd_orthanc_bridge_v1_download_proto_rawDescData []byte
+)
+
+func file_tkd_orthanc_bridge_v1_download_proto_rawDescGZIP() []byte {
+	file_tkd_orthanc_bridge_v1_download_proto_rawDescOnce.Do(func() {
+		file_tkd_orthanc_bridge_v1_download_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_download_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_download_proto_rawDesc)))
+	})
+	return file_tkd_orthanc_bridge_v1_download_proto_rawDescData
+}
+
+var file_tkd_orthanc_bridge_v1_download_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_tkd_orthanc_bridge_v1_download_proto_goTypes = []any{
+	(*Artifact)(nil),                   // 0: tkd.orthanc_bridge.v1.Artifact
+	(*CreateDownloadLinkRequest)(nil),  // 1: tkd.orthanc_bridge.v1.CreateDownloadLinkRequest
+	(*CreateDownloadLinkResponse)(nil), // 2: tkd.orthanc_bridge.v1.CreateDownloadLinkResponse
+	(*timestamppb.Timestamp)(nil),      // 3: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),        // 4: google.protobuf.Duration
+}
+var file_tkd_orthanc_bridge_v1_download_proto_depIdxs = []int32{
+	3, // 0: tkd.orthanc_bridge.v1.Artifact.create_time:type_name -> google.protobuf.Timestamp
+	3, // 1: tkd.orthanc_bridge.v1.Artifact.expire_time:type_name -> google.protobuf.Timestamp
+	3, // 2: tkd.orthanc_bridge.v1.Artifact.last_download_time:type_name -> google.protobuf.Timestamp
+	4, // 3: tkd.orthanc_bridge.v1.CreateDownloadLinkRequest.valid_duration:type_name -> google.protobuf.Duration
+	3, // 4: tkd.orthanc_bridge.v1.CreateDownloadLinkResponse.expire_time:type_name -> google.protobuf.Timestamp
+	0, // 5: tkd.orthanc_bridge.v1.CreateDownloadLinkResponse.artifact:type_name -> tkd.orthanc_bridge.v1.Artifact
+	1, // 6: tkd.orthanc_bridge.v1.DownloadService.CreateDownloadLink:input_type -> tkd.orthanc_bridge.v1.CreateDownloadLinkRequest
+	2, // 7: tkd.orthanc_bridge.v1.DownloadService.CreateDownloadLink:output_type -> tkd.orthanc_bridge.v1.CreateDownloadLinkResponse
+	7, // [7:8] is the sub-list for method output_type
+	6, // [6:7] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_tkd_orthanc_bridge_v1_download_proto_init() }
+func file_tkd_orthanc_bridge_v1_download_proto_init() {
+	if File_tkd_orthanc_bridge_v1_download_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_download_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_download_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tkd_orthanc_bridge_v1_download_proto_goTypes,
+		DependencyIndexes: file_tkd_orthanc_bridge_v1_download_proto_depIdxs,
+		MessageInfos:      file_tkd_orthanc_bridge_v1_download_proto_msgTypes,
+	}.Build()
+	File_tkd_orthanc_bridge_v1_download_proto = out.File
+	file_tkd_orthanc_bridge_v1_download_proto_goTypes = nil
+	file_tkd_orthanc_bridge_v1_download_proto_depIdxs = nil
+}
@@ -0,0 +1,778 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: tkd/orthanc_bridge/v1/export.proto
+
+package bridgev1
+
+import (
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	_ "github.com/tierklinik-dobersberg/apis/gen/go/tkd/common/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ExportFormat int32
+
+const (
+	ExportFormat_EXPORT_FORMAT_UNSPECIFIED ExportFormat = 0
+	ExportFormat_EXPORT_FORMAT_DICOM       ExportFormat = 1
+	ExportFormat_EXPORT_FORMAT_JPEG        ExportFormat = 2
+	ExportFormat_EXPORT_FORMAT_PNG         ExportFormat = 3
+	ExportFormat_EXPORT_FORMAT_AVI         ExportFormat = 4
+	// EXPORT_FORMAT_DICOMDIR creates a ZIP archive laid out as DICOM media
+	// with a DICOMDIR index that can be copied to a CD or USB drive. It
+	// cannot be combined with other formats.
+	ExportFormat_EXPORT_FORMAT_DICOMDIR ExportFormat = 5
+	// EXPORT_FORMAT_PDF creates a PDF report with a cover page and a grid
+	// of previews for each series. It cannot be combined with other
+	// formats.
+	ExportFormat_EXPORT_FORMAT_PDF ExportFormat = 6
+)
+
+// Enum value maps for ExportFormat.
+var (
+	ExportFormat_name = map[int32]string{
+		0: "EXPORT_FORMAT_UNSPECIFIED",
+		1: "EXPORT_FORMAT_DICOM",
+		2: "EXPORT_FORMAT_JPEG",
+		3: "EXPORT_FORMAT_PNG",
+		4: "EXPORT_FORMAT_AVI",
+		5: "EXPORT_FORMAT_DICOMDIR",
+		6: "EXPORT_FORMAT_PDF",
+	}
+	ExportFormat_value = map[string]int32{
+		"EXPORT_FORMAT_UNSPECIFIED": 0,
+		"EXPORT_FORMAT_DICOM":       1,
+		"EXPORT_FORMAT_JPEG":        2,
+		"EXPORT_FORMAT_PNG":         3,
+		"EXPORT_FORMAT_AVI":         4,
+		"EXPORT_FORMAT_DICOMDIR":    5,
+		"EXPORT_FORMAT_PDF":         6,
+	}
+)
+
+func (x ExportFormat) Enum() *ExportFormat {
+	p := new(ExportFormat)
+	*p = x
+	return p
+}
+
+func (x ExportFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExportFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_tkd_orthanc_bridge_v1_export_proto_enumTypes[0].Descriptor()
+}
+
+func (ExportFormat) Type() protoreflect.EnumType {
+	return &file_tkd_orthanc_bridge_v1_export_proto_enumTypes[0]
+}
+
+func (x ExportFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExportFormat.Descriptor instead.
+func (ExportFormat) EnumDescriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_export_proto_rawDescGZIP(), []int{0}
+}
+
+type ExportJobState int32
+
+const (
+	ExportJobState_EXPORT_JOB_STATE_UNSPECIFIED ExportJobState = 0
+	ExportJobState_EXPORT_JOB_STATE_PENDING     ExportJobState = 1
+	ExportJobState_EXPORT_JOB_STATE_RUNNING     ExportJobState = 2
+	ExportJobState_EXPORT_JOB_STATE_COMPLETED   ExportJobState = 3
+	ExportJobState_EXPORT_JOB_STATE_FAILED      ExportJobState = 4
+)
+
+// Enum value maps for ExportJobState.
+var (
+	ExportJobState_name = map[int32]string{
+		0: "EXPORT_JOB_STATE_UNSPECIFIED",
+		1: "EXPORT_JOB_STATE_PENDING",
+		2: "EXPORT_JOB_STATE_RUNNING",
+		3: "EXPORT_JOB_STATE_COMPLETED",
+		4: "EXPORT_JOB_STATE_FAILED",
+	}
+	ExportJobState_value = map[string]int32{
+		"EXPORT_JOB_STATE_UNSPECIFIED": 0,
+		"EXPORT_JOB_STATE_PENDING":     1,
+		"EXPORT_JOB_STATE_RUNNING":     2,
+		"EXPORT_JOB_STATE_COMPLETED":   3,
+		"EXPORT_JOB_STATE_FAILED":      4,
+	}
+)
+
+func (x ExportJobState) Enum() *ExportJobState {
+	p := new(ExportJobState)
+	*p = x
+	return p
+}
+
+func (x ExportJobState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExportJobState) Descriptor() protoreflect.EnumDescriptor {
+	return file_tkd_orthanc_bridge_v1_export_proto_enumTypes[1].Descriptor()
+}
+
+func (ExportJobState) Type() protoreflect.EnumType {
+	return &file_tkd_orthanc_bridge_v1_export_proto_enumTypes[1]
+}
+
+func (x ExportJobState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExportJobState.Descriptor instead.
+func (ExportJobState) EnumDescriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_export_proto_rawDescGZIP(), []int{1}
+}
+
+type ExportJob struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	State    ExportJobState         `protobuf:"varint,2,opt,name=state,proto3,enum=tkd.orthanc_bridge.v1.ExportJobState" json:"state,omitempty"`
+	Creator  string                 `protobuf:"bytes,3,opt,name=creator,proto3" json:"creator,omitempty"`
+	Instance string                 `protobuf:"bytes,4,opt,name=instance,proto3" json:"instance,omitempty"`
+	StudyUid string                 `protobuf:"bytes,5,opt,name=study_uid,json=studyUid,proto3" json:"study_uid,omitempty"`
+	// TotalInstances and DoneInstances report the progress of the job.
+	TotalInstances int32 `protobuf:"varint,6,opt,name=total_instances,json=totalInstances,proto3" json:"total_instances,omitempty"`
+	DoneInstances  int32 `protobuf:"varint,7,opt,name=done_instances,json=doneInstances,proto3" json:"done_instances,omitempty"`
+	// DownloadLink and ExpireTime are set once the job has completed.
+	DownloadLink string                 `protobuf:"bytes,8,opt,name=download_link,json=downloadLink,proto3" json:"download_link,omitempty"`
+	ExpireTime   *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=expire_time,json=expireTime,proto3" json:"expire_time,omitempty"`
+	// Error is set if the job failed.
+	Error         string                 `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	CreateTime    *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	CompleteTime  *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=complete_time,json=completeTime,proto3" json:"complete_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportJob) Reset() {
+	*x = ExportJob{}
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportJob) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportJob) ProtoMessage() {}
+
+func (x *ExportJob) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportJob.ProtoReflect.Descriptor instead.
+func (*ExportJob) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_export_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ExportJob) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ExportJob) GetState() ExportJobState {
+	if x != nil {
+		return x.State
+	}
+	return ExportJobState_EXPORT_JOB_STATE_UNSPECIFIED
+}
+
+func (x *ExportJob) GetCreator() string {
+	if x != nil {
+		return x.Creator
+	}
+	return ""
+}
+
+func (x *ExportJob) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *ExportJob) GetStudyUid() string {
+	if x != nil {
+		return x.StudyUid
+	}
+	return ""
+}
+
+func (x *ExportJob) GetTotalInstances() int32 {
+	if x != nil {
+		return x.TotalInstances
+	}
+	return 0
+}
+
+func (x *ExportJob) GetDoneInstances() int32 {
+	if x != nil {
+		return x.DoneInstances
+	}
+	return 0
+}
+
+func (x *ExportJob) GetDownloadLink() string {
+	if x != nil {
+		return x.DownloadLink
+	}
+	return ""
+}
+
+func (x *ExportJob) GetExpireTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpireTime
+	}
+	return nil
+}
+
+func (x *ExportJob) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ExportJob) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *ExportJob) GetCompleteTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompleteTime
+	}
+	return nil
+}
+
+type CreateExportJobRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	StudyUid string                 `protobuf:"bytes,1,opt,name=study_uid,json=studyUid,proto3" json:"study_uid,omitempty"`
+	// InstanceUids might be set to limit which instances are exported. If
+	// unset, all instances of the study are exported.
+	InstanceUids []string       `protobuf:"bytes,2,rep,name=instance_uids,json=instanceUids,proto3" json:"instance_uids,omitempty"`
+	Formats      []ExportFormat `protobuf:"varint,3,rep,packed,name=formats,proto3,enum=tkd.orthanc_bridge.v1.ExportFormat" json:"formats,omitempty"`
+	// TimeToLive specifies how long the export is kept. Defaults to 30
+	// minutes.
+	TimeToLive *durationpb.Duration `protobuf:"bytes,4,opt,name=time_to_live,json=timeToLive,proto3" json:"time_to_live,omitempty"`
+	// IncludeViewer adds the configured portable DICOM viewer to
+	// EXPORT_FORMAT_DICOMDIR exports.
+	IncludeViewer bool `protobuf:"varint,5,opt,name=include_viewer,json=includeViewer,proto3" json:"include_viewer,omitempty"`
+	// Instance is the name of the orthanc instance that holds the study.
+	// Defaults to the instance selected by the X-Orthanc-Instance header or
+	// the default instance.
+	Instance      string `protobuf:"bytes,6,opt,name=instance,proto3" json:"instance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateExportJobRequest) Reset() {
+	*x = CreateExportJobRequest{}
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateExportJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateExportJobRequest) ProtoMessage() {}
+
+func (x *CreateExportJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateExportJobRequest.ProtoReflect.Descriptor instead.
+func (*CreateExportJobRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_export_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateExportJobRequest) GetStudyUid() string {
+	if x != nil {
+		return x.StudyUid
+	}
+	return ""
+}
+
+func (x *CreateExportJobRequest) GetInstanceUids() []string {
+	if x != nil {
+		return x.InstanceUids
+	}
+	return nil
+}
+
+func (x *CreateExportJobRequest) GetFormats() []ExportFormat {
+	if x != nil {
+		return x.Formats
+	}
+	return nil
+}
+
+func (x *CreateExportJobRequest) GetTimeToLive() *durationpb.Duration {
+	if x != nil {
+		return x.TimeToLive
+	}
+	return nil
+}
+
+func (x *CreateExportJobRequest) GetIncludeViewer() bool {
+	if x != nil {
+		return x.IncludeViewer
+	}
+	return false
+}
+
+func (x *CreateExportJobRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+type CreateExportJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Job           *ExportJob             `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateExportJobResponse) Reset() {
+	*x = CreateExportJobResponse{}
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateExportJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateExportJobResponse) ProtoMessage() {}
+
+func (x *CreateExportJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateExportJobResponse.ProtoReflect.Descriptor instead.
+func (*CreateExportJobResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_export_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateExportJobResponse) GetJob() *ExportJob {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+type GetExportJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetExportJobRequest) Reset() {
+	*x = GetExportJobRequest{}
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetExportJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetExportJobRequest) ProtoMessage() {}
+
+func (x *GetExportJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetExportJobRequest.ProtoReflect.Descriptor instead.
+func (*GetExportJobRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_export_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetExportJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetExportJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Job           *ExportJob             `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetExportJobResponse) Reset() {
+	*x = GetExportJobResponse{}
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetExportJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetExportJobResponse) ProtoMessage() {}
+
+func (x *GetExportJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetExportJobResponse.ProtoReflect.Descriptor instead.
+func (*GetExportJobResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_export_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetExportJobResponse) GetJob() *ExportJob {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+type WatchExportJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchExportJobRequest) Reset() {
+	*x = WatchExportJobRequest{}
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchExportJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchExportJobRequest) ProtoMessage() {}
+
+func (x *WatchExportJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchExportJobRequest.ProtoReflect.Descriptor instead.
+func (*WatchExportJobRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_export_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WatchExportJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type WatchExportJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Job           *ExportJob             `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchExportJobResponse) Reset() {
+	*x = WatchExportJobResponse{}
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchExportJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchExportJobResponse) ProtoMessage() {}
+
+func (x *WatchExportJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchExportJobResponse.ProtoReflect.Descriptor instead.
+func (*WatchExportJobResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_export_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WatchExportJobResponse) GetJob() *ExportJob {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+// ExportJobEvent is published when an export job has completed or failed.
+type ExportJobEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Job           *ExportJob             `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportJobEvent) Reset() {
+	*x = ExportJobEvent{}
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportJobEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportJobEvent) ProtoMessage() {}
+
+func (x *ExportJobEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_export_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportJobEvent.ProtoReflect.Descriptor instead.
+func (*ExportJobEvent) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_export_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ExportJobEvent) GetJob() *ExportJob {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+var File_tkd_orthanc_bridge_v1_export_proto protoreflect.FileDescriptor
+
+const file_tkd_orthanc_bridge_v1_export_proto_rawDesc = "" +
+	"\n" +
+	"\"tkd/orthanc_bridge/v1/export.proto\x12\x15tkd.orthanc_bridge.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1bbuf/validate/validate.proto\x1a\x1etkd/common/v1/descriptor.proto\"\xf1\x03\n" +
+	"\tExportJob\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12;\n" +
+	"\x05state\x18\x02 \x01(\x0e2%.tkd.orthanc_bridge.v1.ExportJobStateR\x05state\x12\x18\n" +
+	"\acreator\x18\x03 \x01(\tR\acreator\x12\x1a\n" +
+	"\binstance\x18\x04 \x01(\tR\binstance\x12\x1b\n" +
+	"\tstudy_uid\x18\x05 \x01(\tR\bstudyUid\x12'\n" +
+	"\x0ftotal_instances\x18\x06 \x01(\x05R\x0etotalInstances\x12%\n" +
+	"\x0edone_instances\x18\a \x01(\x05R\rdoneInstances\x12#\n" +
+	"\rdownload_link\x18\b \x01(\tR\fdownloadLink\x12;\n" +
+	"\vexpire_time\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"expireTime\x12\x14\n" +
+	"\x05error\x18\n" +
+	" \x01(\tR\x05error\x12;\n" +
+	"\vcreate_time\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createTime\x12?\n" +
+	"\rcomplete_time\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\fcompleteTime\"\xac\x02\n" +
+	"\x16CreateExportJobRequest\x12$\n" +
+	"\tstudy_uid\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\bstudyUid\x12#\n" +
+	"\rinstance_uids\x18\x02 \x03(\tR\finstanceUids\x12G\n" +
+	"\aformats\x18\x03 \x03(\x0e2#.tkd.orthanc_bridge.v1.ExportFormatB\b\xbaH\x05\x92\x01\x02\b\x01R\aformats\x12;\n" +
+	"\ftime_to_live\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"timeToLive\x12%\n" +
+	"\x0einclude_viewer\x18\x05 \x01(\bR\rincludeViewer\x12\x1a\n" +
+	"\binstance\x18\x06 \x01(\tR\binstance\"M\n" +
+	"\x17CreateExportJobResponse\x122\n" +
+	"\x03job\x18\x01 \x01(\v2 .tkd.orthanc_bridge.v1.ExportJobR\x03job\".\n" +
+	"\x13GetExportJobRequest\x12\x17\n" +
+	"\x02id\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x02id\"J\n" +
+	"\x14GetExportJobResponse\x122\n" +
+	"\x03job\x18\x01 \x01(\v2 .tkd.orthanc_bridge.v1.ExportJobR\x03job\"0\n" +
+	"\x15WatchExportJobRequest\x12\x17\n" +
+	"\x02id\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x02id\"L\n" +
+	"\x16WatchExportJobResponse\x122\n" +
+	"\x03job\x18\x01 \x01(\v2 .tkd.orthanc_bridge.v1.ExportJobR\x03job\"D\n" +
+	"\x0eExportJobEvent\x122\n" +
+	"\x03job\x18\x01 \x01(\v2 .tkd.orthanc_bridge.v1.ExportJobR\x03job*\xbf\x01\n" +
+	"\fExportFormat\x12\x1d\n" +
+	"\x19EXPORT_FORMAT_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13EXPORT_FORMAT_DICOM\x10\x01\x12\x16\n" +
+	"\x12EXPORT_FORMAT_JPEG\x10\x02\x12\x15\n" +
+	"\x11EXPORT_FORMAT_PNG\x10\x03\x12\x15\n" +
+	"\x11EXPORT_FORMAT_AVI\x10\x04\x12\x1a\n" +
+	"\x16EXPORT_FORMAT_DICOMDIR\x10\x05\x12\x15\n" +
+	"\x11EXPORT_FORMAT_PDF\x10\x06*\xab\x01\n" +
+	"\x0eExportJobState\x12 \n" +
+	"\x1cEXPORT_JOB_STATE_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18EXPORT_JOB_STATE_PENDING\x10\x01\x12\x1c\n" +
+	"\x18EXPORT_JOB_STATE_RUNNING\x10\x02\x12\x1e\n" +
+	"\x1aEXPORT_JOB_STATE_COMPLETED\x10\x03\x12\x1b\n" +
+	"\x17EXPORT_JOB_STATE_FAILED\x10\x042\xf0\x02\n" +
+	"\rExportService\x12w\n" +
+	"\x0fCreateExportJob\x12-.tkd.orthanc_bridge.v1.CreateExportJobRequest\x1a..tkd.orthanc_bridge.v1.CreateExportJobResponse\"\x05\xb2~\x02\b\x01\x12n\n" +
+	"\fGetExportJob\x12*.tkd.orthanc_bridge.v1.GetExportJobRequest\x1a+.tkd.orthanc_bridge.v1.GetExportJobResponse\"\x05\xb2~\x02\b\x01\x12v\n" +
+	"\x0eWatchExportJob\x12,.tkd.orthanc_bridge.v1.WatchExportJobRequest\x1a-.tkd.orthanc_bridge.v1.WatchExportJobResponse\"\x05\xb2~\x02\b\x010\x01BWZUgithub.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1;bridgev1b\x06proto3"
+
+var (
+	file_tkd_orthanc_bridge_v1_export_proto_rawDescOnce sync.Once
+	file_tkd_orthanc_bridge_v1_export_proto_rawDescData []byte
+)
+
+func file_tkd_orthanc_bridge_v1_export_proto_rawDescGZIP() []byte {
+	file_tkd_orthanc_bridge_v1_export_proto_rawDescOnce.Do(func() {
+		file_tkd_orthanc_bridge_v1_export_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_export_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_export_proto_rawDesc)))
+	})
+	return file_tkd_orthanc_bridge_v1_export_proto_rawDescData
+}
+
+var file_tkd_orthanc_bridge_v1_export_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_tkd_orthanc_bridge_v1_export_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_tkd_orthanc_bridge_v1_export_proto_goTypes = []any{
+	(ExportFormat)(0),               // 0: tkd.orthanc_bridge.v1.ExportFormat
+	(ExportJobState)(0),             // 1: tkd.orthanc_bridge.v1.ExportJobState
+	(*ExportJob)(nil),               // 2: tkd.orthanc_bridge.v1.ExportJob
+	(*CreateExportJobRequest)(nil),  // 3: tkd.orthanc_bridge.v1.CreateExportJobRequest
+	(*CreateExportJobResponse)(nil), // 4: tkd.orthanc_bridge.v1.CreateExportJobResponse
+	(*GetExportJobRequest)(nil),     // 5: tkd.orthanc_bridge.v1.GetExportJobRequest
+	(*GetExportJobResponse)(nil),    // 6: tkd.orthanc_bridge.v1.GetExportJobResponse
+	(*WatchExportJobRequest)(nil),   // 7: tkd.orthanc_bridge.v1.WatchExportJobRequest
+	(*WatchExportJobResponse)(nil),  // 8: tkd.orthanc_bridge.v1.WatchExportJobResponse
+	(*ExportJobEvent)(nil),          // 9: tkd.orthanc_bridge.v1.ExportJobEvent
+	(*timestamppb.Timestamp)(nil),   // 10: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),     // 11: google.protobuf.Duration
+}
+var file_tkd_orthanc_bridge_v1_export_proto_depIdxs = []int32{
+	1,  // 0: tkd.orthanc_bridge.v1.ExportJob.state:type_name -> tkd.orthanc_bridge.v1.ExportJobState
+	10, // 1: tkd.orthanc_bridge.v1.ExportJob.expire_time:type_name -> google.protobuf.Timestamp
+	10, // 2: tkd.orthanc_bridge.v1.ExportJob.create_time:type_name -> google.protobuf.Timestamp
+	10, // 3: tkd.orthanc_bridge.v1.ExportJob.complete_time:type_name -> google.protobuf.Timestamp
+	0,  // 4: tkd.orthanc_bridge.v1.CreateExportJobRequest.formats:type_name -> tkd.orthanc_bridge.v1.ExportFormat
+	11, // 5: tkd.orthanc_bridge.v1.CreateExportJobRequest.time_to_live:type_name -> google.protobuf.Duration
+	2,  // 6: tkd.orthanc_bridge.v1.CreateExportJobResponse.job:type_name -> tkd.orthanc_bridge.v1.ExportJob
+	2,  // 7: tkd.orthanc_bridge.v1.GetExportJobResponse.job:type_name -> tkd.orthanc_bridge.v1.ExportJob
+	2,  // 8: tkd.orthanc_bridge.v1.WatchExportJobResponse.job:type_name -> tkd.orthanc_bridge.v1.ExportJob
+	2,  // 9: tkd.orthanc_bridge.v1.ExportJobEvent.job:type_name -> tkd.orthanc_bridge.v1.ExportJob
+	3,  // 10: tkd.orthanc_bridge.v1.ExportService.CreateExportJob:input_type -> tkd.orthanc_bridge.v1.CreateExportJobRequest
+	5,  // 11: tkd.orthanc_bridge.v1.ExportService.GetExportJob:input_type -> tkd.orthanc_bridge.v1.GetExportJobRequest
+	7,  // 12: tkd.orthanc_bridge.v1.ExportService.WatchExportJob:input_type -> tkd.orthanc_bridge.v1.WatchExportJobRequest
+	4,  // 13: tkd.orthanc_bridge.v1.ExportService.CreateExportJob:output_type -> tkd.orthanc_bridge.v1.CreateExportJobResponse
+	6,  // 14: tkd.orthanc_bridge.v1.ExportService.GetExportJob:output_type -> tkd.orthanc_bridge.v1.GetExportJobResponse
+	8,  // 15: tkd.orthanc_bridge.v1.ExportService.WatchExportJob:output_type -> tkd.orthanc_bridge.v1.WatchExportJobResponse
+	13, // [13:16] is the sub-list for method output_type
+	10, // [10:13] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_tkd_orthanc_bridge_v1_export_proto_init() }
+func file_tkd_orthanc_bridge_v1_export_proto_init() {
+	if File_tkd_orthanc_bridge_v1_export_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_export_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_export_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tkd_orthanc_bridge_v1_export_proto_goTypes,
+		DependencyIndexes: file_tkd_orthanc_bridge_v1_export_proto_depIdxs,
+		EnumInfos:         file_tkd_orthanc_bridge_v1_export_proto_enumTypes,
+		MessageInfos:      file_tkd_orthanc_bridge_v1_export_proto_msgTypes,
+	}.Build()
+	File_tkd_orthanc_bridge_v1_export_proto = out.File
+	file_tkd_orthanc_bridge_v1_export_proto_goTypes = nil
+	file_tkd_orthanc_bridge_v1_export_proto_depIdxs = nil
+}
@@ -0,0 +1,212 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: tkd/orthanc_bridge/v1/search.proto
+
+package bridgev1
+
+import (
+	_ "github.com/tierklinik-dobersberg/apis/gen/go/tkd/common/v1"
+	v1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FederatedStudy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Study *v1.Study              `protobuf:"bytes,1,opt,name=study,proto3" json:"study,omitempty"`
+	// Instance is the name of the orthanc instance that holds the study.
+	Instance      string `protobuf:"bytes,2,opt,name=instance,proto3" json:"instance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FederatedStudy) Reset() {
+	*x = FederatedStudy{}
+	mi := &file_tkd_orthanc_bridge_v1_search_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FederatedStudy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FederatedStudy) ProtoMessage() {}
+
+func (x *FederatedStudy) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_search_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FederatedStudy.ProtoReflect.Descriptor instead.
+func (*FederatedStudy) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_search_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FederatedStudy) GetStudy() *v1.Study {
+	if x != nil {
+		return x.Study
+	}
+	return nil
+}
+
+func (x *FederatedStudy) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+type ListFederatedStudiesResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Studies []*FederatedStudy      `protobuf:"bytes,1,rep,name=studies,proto3" json:"studies,omitempty"`
+	// TotalCount holds the total count of matched studies. Only the subset
+	// requested by the pagination of the request is returned in studies.
+	TotalCount int64 `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	// FailedInstances lists the orthanc instances that could not be
+	// searched. The response holds partial results in this case.
+	FailedInstances []string `protobuf:"bytes,3,rep,name=failed_instances,json=failedInstances,proto3" json:"failed_instances,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ListFederatedStudiesResponse) Reset() {
+	*x = ListFederatedStudiesResponse{}
+	mi := &file_tkd_orthanc_bridge_v1_search_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFederatedStudiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFederatedStudiesResponse) ProtoMessage() {}
+
+func (x *ListFederatedStudiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_search_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFederatedStudiesResponse.ProtoReflect.Descriptor instead.
+func (*ListFederatedStudiesResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_search_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListFederatedStudiesResponse) GetStudies() []*FederatedStudy {
+	if x != nil {
+		return x.Studies
+	}
+	return nil
+}
+
+func (x *ListFederatedStudiesResponse) GetTotalCount() int64 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *ListFederatedStudiesResponse) GetFailedInstances() []string {
+	if x != nil {
+		return x.FailedInstances
+	}
+	return nil
+}
+
+var File_tkd_orthanc_bridge_v1_search_proto protoreflect.FileDescriptor
+
+const file_tkd_orthanc_bridge_v1_search_proto_rawDesc = "" +
+	"\n" +
+	"\"tkd/orthanc_bridge/v1/search.proto\x12\x15tkd.orthanc_bridge.v1\x1a\x1etkd/common/v1/descriptor.proto\x1a*tkd/orthanc_bridge/v1/orthanc-bridge.proto\"`\n" +
+	"\x0eFederatedStudy\x122\n" +
+	"\x05study\x18\x01 \x01(\v2\x1c.tkd.orthanc_bridge.v1.StudyR\x05study\x12\x1a\n" +
+	"\binstance\x18\x02 \x01(\tR\binstance\"\xab\x01\n" +
+	"\x1cListFederatedStudiesResponse\x12?\n" +
+	"\astudies\x18\x01 \x03(\v2%.tkd.orthanc_bridge.v1.FederatedStudyR\astudies\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x03R\n" +
+	"totalCount\x12)\n" +
+	"\x10failed_instances\x18\x03 \x03(\tR\x0ffailedInstances2\x93\x01\n" +
+	"\x12StudySearchService\x12}\n" +
+	"\x14ListFederatedStudies\x12).tkd.orthanc_bridge.v1.ListStudiesRequest\x1a3.tkd.orthanc_bridge.v1.ListFederatedStudiesResponse\"\x05\xb2~\x02\b\x01BWZUgithub.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1;bridgev1b\x06proto3"
+
+var (
+	file_tkd_orthanc_bridge_v1_search_proto_rawDescOnce sync.Once
+	file_tkd_orthanc_bridge_v1_search_proto_rawDescData []byte
+)
+
+func file_tkd_orthanc_bridge_v1_search_proto_rawDescGZIP() []byte {
+	file_tkd_orthanc_bridge_v1_search_proto_rawDescOnce.Do(func() {
+		file_tkd_orthanc_bridge_v1_search_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_search_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_search_proto_rawDesc)))
+	})
+	return file_tkd_orthanc_bridge_v1_search_proto_rawDescData
+}
+
+var file_tkd_orthanc_bridge_v1_search_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_tkd_orthanc_bridge_v1_search_proto_goTypes = []any{
+	(*FederatedStudy)(nil),               // 0: tkd.orthanc_bridge.v1.FederatedStudy
+	(*ListFederatedStudiesResponse)(nil), // 1: tkd.orthanc_bridge.v1.ListFederatedStudiesResponse
+	(*v1.Study)(nil),                     // 2: tkd.orthanc_bridge.v1.Study
+	(*v1.ListStudiesRequest)(nil),        // 3: tkd.orthanc_bridge.v1.ListStudiesRequest
+}
+var file_tkd_orthanc_bridge_v1_search_proto_depIdxs = []int32{
+	2, // 0: tkd.orthanc_bridge.v1.FederatedStudy.study:type_name -> tkd.orthanc_bridge.v1.Study
+	0, // 1: tkd.orthanc_bridge.v1.ListFederatedStudiesResponse.studies:type_name -> tkd.orthanc_bridge.v1.FederatedStudy
+	3, // 2: tkd.orthanc_bridge.v1.StudySearchService.ListFederatedStudies:input_type -> tkd.orthanc_bridge.v1.ListStudiesRequest
+	1, // 3: tkd.orthanc_bridge.v1.StudySearchService.ListFederatedStudies:output_type -> tkd.orthanc_bridge.v1.ListFederatedStudiesResponse
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_tkd_orthanc_bridge_v1_search_proto_init() }
+func file_tkd_orthanc_bridge_v1_search_proto_init() {
+	if File_tkd_orthanc_bridge_v1_search_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_search_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_search_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tkd_orthanc_bridge_v1_search_proto_goTypes,
+		DependencyIndexes: file_tkd_orthanc_bridge_v1_search_proto_depIdxs,
+		MessageInfos:      file_tkd_orthanc_bridge_v1_search_proto_msgTypes,
+	}.Build()
+	File_tkd_orthanc_bridge_v1_search_proto = out.File
+	file_tkd_orthanc_bridge_v1_search_proto_goTypes = nil
+	file_tkd_orthanc_bridge_v1_search_proto_depIdxs = nil
+}
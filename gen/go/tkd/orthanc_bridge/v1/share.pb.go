@@ -0,0 +1,1550 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: tkd/orthanc_bridge/v1/share.proto
+
+package bridgev1
+
+import (
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	_ "github.com/tierklinik-dobersberg/apis/gen/go/tkd/common/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ShareProtection int32
+
+const (
+	ShareProtection_SHARE_PROTECTION_NONE ShareProtection = 0
+	// PIN requires a PIN before the share can be used.
+	ShareProtection_SHARE_PROTECTION_PIN ShareProtection = 1
+	// BIRTH_DATE requires the patient's date of birth before the share
+	// can be used.
+	ShareProtection_SHARE_PROTECTION_BIRTH_DATE ShareProtection = 2
+)
+
+// Enum value maps for ShareProtection.
+var (
+	ShareProtection_name = map[int32]string{
+		0: "SHARE_PROTECTION_NONE",
+		1: "SHARE_PROTECTION_PIN",
+		2: "SHARE_PROTECTION_BIRTH_DATE",
+	}
+	ShareProtection_value = map[string]int32{
+		"SHARE_PROTECTION_NONE":       0,
+		"SHARE_PROTECTION_PIN":        1,
+		"SHARE_PROTECTION_BIRTH_DATE": 2,
+	}
+)
+
+func (x ShareProtection) Enum() *ShareProtection {
+	p := new(ShareProtection)
+	*p = x
+	return p
+}
+
+func (x ShareProtection) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ShareProtection) Descriptor() protoreflect.EnumDescriptor {
+	return file_tkd_orthanc_bridge_v1_share_proto_enumTypes[0].Descriptor()
+}
+
+func (ShareProtection) Type() protoreflect.EnumType {
+	return &file_tkd_orthanc_bridge_v1_share_proto_enumTypes[0]
+}
+
+func (x ShareProtection) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ShareProtection.Descriptor instead.
+func (ShareProtection) EnumDescriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{0}
+}
+
+type ShareDeliveryChannel int32
+
+const (
+	ShareDeliveryChannel_SHARE_DELIVERY_CHANNEL_UNSPECIFIED ShareDeliveryChannel = 0
+	ShareDeliveryChannel_SHARE_DELIVERY_CHANNEL_EMAIL       ShareDeliveryChannel = 1
+	ShareDeliveryChannel_SHARE_DELIVERY_CHANNEL_SMS         ShareDeliveryChannel = 2
+)
+
+// Enum value maps for ShareDeliveryChannel.
+var (
+	ShareDeliveryChannel_name = map[int32]string{
+		0: "SHARE_DELIVERY_CHANNEL_UNSPECIFIED",
+		1: "SHARE_DELIVERY_CHANNEL_EMAIL",
+		2: "SHARE_DELIVERY_CHANNEL_SMS",
+	}
+	ShareDeliveryChannel_value = map[string]int32{
+		"SHARE_DELIVERY_CHANNEL_UNSPECIFIED": 0,
+		"SHARE_DELIVERY_CHANNEL_EMAIL":       1,
+		"SHARE_DELIVERY_CHANNEL_SMS":         2,
+	}
+)
+
+func (x ShareDeliveryChannel) Enum() *ShareDeliveryChannel {
+	p := new(ShareDeliveryChannel)
+	*p = x
+	return p
+}
+
+func (x ShareDeliveryChannel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ShareDeliveryChannel) Descriptor() protoreflect.EnumDescriptor {
+	return file_tkd_orthanc_bridge_v1_share_proto_enumTypes[1].Descriptor()
+}
+
+func (ShareDeliveryChannel) Type() protoreflect.EnumType {
+	return &file_tkd_orthanc_bridge_v1_share_proto_enumTypes[1]
+}
+
+func (x ShareDeliveryChannel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ShareDeliveryChannel.Descriptor instead.
+func (ShareDeliveryChannel) EnumDescriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{1}
+}
+
+type ShareDeliveryStatus int32
+
+const (
+	ShareDeliveryStatus_SHARE_DELIVERY_STATUS_UNSPECIFIED ShareDeliveryStatus = 0
+	// SENT is used if the message has been accepted by the mail server.
+	ShareDeliveryStatus_SHARE_DELIVERY_STATUS_SENT ShareDeliveryStatus = 1
+	// QUEUED is used if the message has been handed over to the
+	// notification service using a ShareDeliveryEvent.
+	ShareDeliveryStatus_SHARE_DELIVERY_STATUS_QUEUED ShareDeliveryStatus = 2
+	ShareDeliveryStatus_SHARE_DELIVERY_STATUS_FAILED ShareDeliveryStatus = 3
+)
+
+// Enum value maps for ShareDeliveryStatus.
+var (
+	ShareDeliveryStatus_name = map[int32]string{
+		0: "SHARE_DELIVERY_STATUS_UNSPECIFIED",
+		1: "SHARE_DELIVERY_STATUS_SENT",
+		2: "SHARE_DELIVERY_STATUS_QUEUED",
+		3: "SHARE_DELIVERY_STATUS_FAILED",
+	}
+	ShareDeliveryStatus_value = map[string]int32{
+		"SHARE_DELIVERY_STATUS_UNSPECIFIED": 0,
+		"SHARE_DELIVERY_STATUS_SENT":        1,
+		"SHARE_DELIVERY_STATUS_QUEUED":      2,
+		"SHARE_DELIVERY_STATUS_FAILED":      3,
+	}
+)
+
+func (x ShareDeliveryStatus) Enum() *ShareDeliveryStatus {
+	p := new(ShareDeliveryStatus)
+	*p = x
+	return p
+}
+
+func (x ShareDeliveryStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ShareDeliveryStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_tkd_orthanc_bridge_v1_share_proto_enumTypes[2].Descriptor()
+}
+
+func (ShareDeliveryStatus) Type() protoreflect.EnumType {
+	return &file_tkd_orthanc_bridge_v1_share_proto_enumTypes[2]
+}
+
+func (x ShareDeliveryStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ShareDeliveryStatus.Descriptor instead.
+func (ShareDeliveryStatus) EnumDescriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{2}
+}
+
+type ShareResource int32
+
+const (
+	ShareResource_SHARE_RESOURCE_UNSPECIFIED ShareResource = 0
+	ShareResource_SHARE_RESOURCE_QIDO        ShareResource = 1
+	ShareResource_SHARE_RESOURCE_WADO        ShareResource = 2
+	ShareResource_SHARE_RESOURCE_RENDERED    ShareResource = 3
+)
+
+// Enum value maps for ShareResource.
+var (
+	ShareResource_name = map[int32]string{
+		0: "SHARE_RESOURCE_UNSPECIFIED",
+		1: "SHARE_RESOURCE_QIDO",
+		2: "SHARE_RESOURCE_WADO",
+		3: "SHARE_RESOURCE_RENDERED",
+	}
+	ShareResource_value = map[string]int32{
+		"SHARE_RESOURCE_UNSPECIFIED": 0,
+		"SHARE_RESOURCE_QIDO":        1,
+		"SHARE_RESOURCE_WADO":        2,
+		"SHARE_RESOURCE_RENDERED":    3,
+	}
+)
+
+func (x ShareResource) Enum() *ShareResource {
+	p := new(ShareResource)
+	*p = x
+	return p
+}
+
+func (x ShareResource) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ShareResource) Descriptor() protoreflect.EnumDescriptor {
+	return file_tkd_orthanc_bridge_v1_share_proto_enumTypes[3].Descriptor()
+}
+
+func (ShareResource) Type() protoreflect.EnumType {
+	return &file_tkd_orthanc_bridge_v1_share_proto_enumTypes[3]
+}
+
+func (x ShareResource) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ShareResource.Descriptor instead.
+func (ShareResource) EnumDescriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{3}
+}
+
+type Share struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Token      string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	CreateTime *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	// ExpireTime is unset if the share never expires.
+	ExpireTime *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expire_time,json=expireTime,proto3" json:"expire_time,omitempty"`
+	// RevokeTime is set if the share has been revoked.
+	RevokeTime *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=revoke_time,json=revokeTime,proto3" json:"revoke_time,omitempty"`
+	// Creator is the ID of the user that created the share.
+	Creator string `protobuf:"bytes,5,opt,name=creator,proto3" json:"creator,omitempty"`
+	// Instance is the name of the orthanc instance that holds the study.
+	Instance     string   `protobuf:"bytes,6,opt,name=instance,proto3" json:"instance,omitempty"`
+	StudyUid     string   `protobuf:"bytes,7,opt,name=study_uid,json=studyUid,proto3" json:"study_uid,omitempty"`
+	InstanceUids []string `protobuf:"bytes,8,rep,name=instance_uids,json=instanceUids,proto3" json:"instance_uids,omitempty"`
+	Recipients   []string `protobuf:"bytes,9,rep,name=recipients,proto3" json:"recipients,omitempty"`
+	// Valid is true if the share is neither expired nor revoked.
+	Valid bool `protobuf:"varint,10,opt,name=valid,proto3" json:"valid,omitempty"`
+	// Deliveries holds the delivery status for each recipient.
+	Deliveries []*ShareDelivery `protobuf:"bytes,11,rep,name=deliveries,proto3" json:"deliveries,omitempty"`
+	Protection ShareProtection  `protobuf:"varint,12,opt,name=protection,proto3,enum=tkd.orthanc_bridge.v1.ShareProtection" json:"protection,omitempty"`
+	// MaxUses is the maximum number of viewer sessions. Zero means
+	// unlimited.
+	MaxUses int32 `protobuf:"varint,13,opt,name=max_uses,json=maxUses,proto3" json:"max_uses,omitempty"`
+	// Uses is the number of viewer sessions started so far.
+	Uses int32 `protobuf:"varint,14,opt,name=uses,proto3" json:"uses,omitempty"`
+	// SeriesUids holds the series that are shared completely.
+	SeriesUids    []string `protobuf:"bytes,15,rep,name=series_uids,json=seriesUids,proto3" json:"series_uids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Share) Reset() {
+	*x = Share{}
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Share) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Share) ProtoMessage() {}
+
+func (x *Share) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Share.ProtoReflect.Descriptor instead.
+func (*Share) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Share) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *Share) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *Share) GetExpireTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpireTime
+	}
+	return nil
+}
+
+func (x *Share) GetRevokeTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RevokeTime
+	}
+	return nil
+}
+
+func (x *Share) GetCreator() string {
+	if x != nil {
+		return x.Creator
+	}
+	return ""
+}
+
+func (x *Share) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *Share) GetStudyUid() string {
+	if x != nil {
+		return x.StudyUid
+	}
+	return ""
+}
+
+func (x *Share) GetInstanceUids() []string {
+	if x != nil {
+		return x.InstanceUids
+	}
+	return nil
+}
+
+func (x *Share) GetRecipients() []string {
+	if x != nil {
+		return x.Recipients
+	}
+	return nil
+}
+
+func (x *Share) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *Share) GetDeliveries() []*ShareDelivery {
+	if x != nil {
+		return x.Deliveries
+	}
+	return nil
+}
+
+func (x *Share) GetProtection() ShareProtection {
+	if x != nil {
+		return x.Protection
+	}
+	return ShareProtection_SHARE_PROTECTION_NONE
+}
+
+func (x *Share) GetMaxUses() int32 {
+	if x != nil {
+		return x.MaxUses
+	}
+	return 0
+}
+
+func (x *Share) GetUses() int32 {
+	if x != nil {
+		return x.Uses
+	}
+	return 0
+}
+
+func (x *Share) GetSeriesUids() []string {
+	if x != nil {
+		return x.SeriesUids
+	}
+	return nil
+}
+
+type ShareDelivery struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Recipient string                 `protobuf:"bytes,1,opt,name=recipient,proto3" json:"recipient,omitempty"`
+	Channel   ShareDeliveryChannel   `protobuf:"varint,2,opt,name=channel,proto3,enum=tkd.orthanc_bridge.v1.ShareDeliveryChannel" json:"channel,omitempty"`
+	Status    ShareDeliveryStatus    `protobuf:"varint,3,opt,name=status,proto3,enum=tkd.orthanc_bridge.v1.ShareDeliveryStatus" json:"status,omitempty"`
+	// Error holds the error message if the delivery failed.
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareDelivery) Reset() {
+	*x = ShareDelivery{}
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareDelivery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareDelivery) ProtoMessage() {}
+
+func (x *ShareDelivery) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareDelivery.ProtoReflect.Descriptor instead.
+func (*ShareDelivery) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ShareDelivery) GetRecipient() string {
+	if x != nil {
+		return x.Recipient
+	}
+	return ""
+}
+
+func (x *ShareDelivery) GetChannel() ShareDeliveryChannel {
+	if x != nil {
+		return x.Channel
+	}
+	return ShareDeliveryChannel_SHARE_DELIVERY_CHANNEL_UNSPECIFIED
+}
+
+func (x *ShareDelivery) GetStatus() ShareDeliveryStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ShareDeliveryStatus_SHARE_DELIVERY_STATUS_UNSPECIFIED
+}
+
+func (x *ShareDelivery) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ShareDelivery) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+type CreateShareRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	StudyUid string                 `protobuf:"bytes,1,opt,name=study_uid,json=studyUid,proto3" json:"study_uid,omitempty"`
+	// InstanceUids and SeriesUids may be set to restrict the share to
+	// individual instances and complete series of the study. If both are
+	// empty, the whole study is shared.
+	InstanceUids []string `protobuf:"bytes,2,rep,name=instance_uids,json=instanceUids,proto3" json:"instance_uids,omitempty"`
+	SeriesUids   []string `protobuf:"bytes,10,rep,name=series_uids,json=seriesUids,proto3" json:"series_uids,omitempty"`
+	// ValidDuration defaults to 30 days.
+	ValidDuration *durationpb.Duration `protobuf:"bytes,3,opt,name=valid_duration,json=validDuration,proto3" json:"valid_duration,omitempty"`
+	// Recipients holds e-mail addresses or phone numbers the viewer link is
+	// sent to.
+	Recipients []string `protobuf:"bytes,4,rep,name=recipients,proto3" json:"recipients,omitempty"`
+	// Subject is used for e-mail messages. Defaults to the configured
+	// subject.
+	Subject string `protobuf:"bytes,5,opt,name=subject,proto3" json:"subject,omitempty"`
+	// MessageTemplate is a Go text/template used to render the message.
+	// The template may use {{ .ViewerURL }}, {{ .ExpiresAt }},
+	// {{ .StudyUID }} and {{ .Recipient }}. Defaults to the configured
+	// template.
+	MessageTemplate string `protobuf:"bytes,6,opt,name=message_template,json=messageTemplate,proto3" json:"message_template,omitempty"`
+	// Protection may be set to require a PIN or the patient's date of
+	// birth before the share can be used. Recipients of protected shares
+	// receive a link to the unlock page instead of the viewer.
+	//
+	// Types that are valid to be assigned to Protection:
+	//
+	//	*CreateShareRequest_Pin
+	//	*CreateShareRequest_RequireBirthDate
+	Protection isCreateShareRequest_Protection `protobuf_oneof:"protection"`
+	// MaxUses limits the number of viewer sessions that may be started
+	// using the share. A viewer session is identified by the client IP and
+	// user agent and lasts until it has been idle for 12 hours.
+	MaxUses int32 `protobuf:"varint,9,opt,name=max_uses,json=maxUses,proto3" json:"max_uses,omitempty"`
+	// Instance is the name of the orthanc instance that holds the study.
+	// Defaults to the instance selected by the X-Orthanc-Instance header or
+	// the default instance.
+	Instance      string `protobuf:"bytes,11,opt,name=instance,proto3" json:"instance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateShareRequest) Reset() {
+	*x = CreateShareRequest{}
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateShareRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateShareRequest) ProtoMessage() {}
+
+func (x *CreateShareRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateShareRequest.ProtoReflect.Descriptor instead.
+func (*CreateShareRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateShareRequest) GetStudyUid() string {
+	if x != nil {
+		return x.StudyUid
+	}
+	return ""
+}
+
+func (x *CreateShareRequest) GetInstanceUids() []string {
+	if x != nil {
+		return x.InstanceUids
+	}
+	return nil
+}
+
+func (x *CreateShareRequest) GetSeriesUids() []string {
+	if x != nil {
+		return x.SeriesUids
+	}
+	return nil
+}
+
+func (x *CreateShareRequest) GetValidDuration() *durationpb.Duration {
+	if x != nil {
+		return x.ValidDuration
+	}
+	return nil
+}
+
+func (x *CreateShareRequest) GetRecipients() []string {
+	if x != nil {
+		return x.Recipients
+	}
+	return nil
+}
+
+func (x *CreateShareRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *CreateShareRequest) GetMessageTemplate() string {
+	if x != nil {
+		return x.MessageTemplate
+	}
+	return ""
+}
+
+func (x *CreateShareRequest) GetProtection() isCreateShareRequest_Protection {
+	if x != nil {
+		return x.Protection
+	}
+	return nil
+}
+
+func (x *CreateShareRequest) GetPin() string {
+	if x != nil {
+		if x, ok := x.Protection.(*CreateShareRequest_Pin); ok {
+			return x.Pin
+		}
+	}
+	return ""
+}
+
+func (x *CreateShareRequest) GetRequireBirthDate() bool {
+	if x != nil {
+		if x, ok := x.Protection.(*CreateShareRequest_RequireBirthDate); ok {
+			return x.RequireBirthDate
+		}
+	}
+	return false
+}
+
+func (x *CreateShareRequest) GetMaxUses() int32 {
+	if x != nil {
+		return x.MaxUses
+	}
+	return 0
+}
+
+func (x *CreateShareRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+type isCreateShareRequest_Protection interface {
+	isCreateShareRequest_Protection()
+}
+
+type CreateShareRequest_Pin struct {
+	Pin string `protobuf:"bytes,7,opt,name=pin,proto3,oneof"`
+}
+
+type CreateShareRequest_RequireBirthDate struct {
+	// RequireBirthDate uses the PatientBirthDate of the study.
+	RequireBirthDate bool `protobuf:"varint,8,opt,name=require_birth_date,json=requireBirthDate,proto3,oneof"`
+}
+
+func (*CreateShareRequest_Pin) isCreateShareRequest_Protection() {}
+
+func (*CreateShareRequest_RequireBirthDate) isCreateShareRequest_Protection() {}
+
+type CreateShareResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Token string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// ViewerUrl is the URL of the unlock page for protected shares.
+	ViewerUrl     string `protobuf:"bytes,2,opt,name=viewer_url,json=viewerUrl,proto3" json:"viewer_url,omitempty"`
+	Share         *Share `protobuf:"bytes,3,opt,name=share,proto3" json:"share,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateShareResponse) Reset() {
+	*x = CreateShareResponse{}
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateShareResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateShareResponse) ProtoMessage() {}
+
+func (x *CreateShareResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateShareResponse.ProtoReflect.Descriptor instead.
+func (*CreateShareResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateShareResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *CreateShareResponse) GetViewerUrl() string {
+	if x != nil {
+		return x.ViewerUrl
+	}
+	return ""
+}
+
+func (x *CreateShareResponse) GetShare() *Share {
+	if x != nil {
+		return x.Share
+	}
+	return nil
+}
+
+// ShareDeliveryEvent is published for recipients that are not reached
+// directly (e.g. via SMS) so the message can be delivered by the
+// notification service.
+type ShareDeliveryEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Recipient     string                 `protobuf:"bytes,2,opt,name=recipient,proto3" json:"recipient,omitempty"`
+	Channel       ShareDeliveryChannel   `protobuf:"varint,3,opt,name=channel,proto3,enum=tkd.orthanc_bridge.v1.ShareDeliveryChannel" json:"channel,omitempty"`
+	Subject       string                 `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
+	Body          string                 `protobuf:"bytes,5,opt,name=body,proto3" json:"body,omitempty"`
+	ViewerUrl     string                 `protobuf:"bytes,6,opt,name=viewer_url,json=viewerUrl,proto3" json:"viewer_url,omitempty"`
+	ExpireTime    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=expire_time,json=expireTime,proto3" json:"expire_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareDeliveryEvent) Reset() {
+	*x = ShareDeliveryEvent{}
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareDeliveryEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareDeliveryEvent) ProtoMessage() {}
+
+func (x *ShareDeliveryEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareDeliveryEvent.ProtoReflect.Descriptor instead.
+func (*ShareDeliveryEvent) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ShareDeliveryEvent) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ShareDeliveryEvent) GetRecipient() string {
+	if x != nil {
+		return x.Recipient
+	}
+	return ""
+}
+
+func (x *ShareDeliveryEvent) GetChannel() ShareDeliveryChannel {
+	if x != nil {
+		return x.Channel
+	}
+	return ShareDeliveryChannel_SHARE_DELIVERY_CHANNEL_UNSPECIFIED
+}
+
+func (x *ShareDeliveryEvent) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *ShareDeliveryEvent) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *ShareDeliveryEvent) GetViewerUrl() string {
+	if x != nil {
+		return x.ViewerUrl
+	}
+	return ""
+}
+
+func (x *ShareDeliveryEvent) GetExpireTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpireTime
+	}
+	return nil
+}
+
+type ListSharesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Creator may be set to only return shares created by the given user
+	// ID.
+	Creator string `protobuf:"bytes,1,opt,name=creator,proto3" json:"creator,omitempty"`
+	// StudyUid may be set to only return shares of the given study.
+	StudyUid string `protobuf:"bytes,2,opt,name=study_uid,json=studyUid,proto3" json:"study_uid,omitempty"`
+	// IncludeInvalid includes expired and revoked shares.
+	IncludeInvalid bool `protobuf:"varint,3,opt,name=include_invalid,json=includeInvalid,proto3" json:"include_invalid,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListSharesRequest) Reset() {
+	*x = ListSharesRequest{}
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSharesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSharesRequest) ProtoMessage() {}
+
+func (x *ListSharesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSharesRequest.ProtoReflect.Descriptor instead.
+func (*ListSharesRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListSharesRequest) GetCreator() string {
+	if x != nil {
+		return x.Creator
+	}
+	return ""
+}
+
+func (x *ListSharesRequest) GetStudyUid() string {
+	if x != nil {
+		return x.StudyUid
+	}
+	return ""
+}
+
+func (x *ListSharesRequest) GetIncludeInvalid() bool {
+	if x != nil {
+		return x.IncludeInvalid
+	}
+	return false
+}
+
+type ListSharesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Shares        []*Share               `protobuf:"bytes,1,rep,name=shares,proto3" json:"shares,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSharesResponse) Reset() {
+	*x = ListSharesResponse{}
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSharesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSharesResponse) ProtoMessage() {}
+
+func (x *ListSharesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSharesResponse.ProtoReflect.Descriptor instead.
+func (*ListSharesResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListSharesResponse) GetShares() []*Share {
+	if x != nil {
+		return x.Shares
+	}
+	return nil
+}
+
+type RevokeShareRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeShareRequest) Reset() {
+	*x = RevokeShareRequest{}
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeShareRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeShareRequest) ProtoMessage() {}
+
+func (x *RevokeShareRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeShareRequest.ProtoReflect.Descriptor instead.
+func (*RevokeShareRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RevokeShareRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type RevokeShareResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Share         *Share                 `protobuf:"bytes,1,opt,name=share,proto3" json:"share,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeShareResponse) Reset() {
+	*x = RevokeShareResponse{}
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeShareResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeShareResponse) ProtoMessage() {}
+
+func (x *RevokeShareResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeShareResponse.ProtoReflect.Descriptor instead.
+func (*RevokeShareResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RevokeShareResponse) GetShare() *Share {
+	if x != nil {
+		return x.Share
+	}
+	return nil
+}
+
+type ShareAccess struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	ClientIp      string                 `protobuf:"bytes,2,opt,name=client_ip,json=clientIp,proto3" json:"client_ip,omitempty"`
+	UserAgent     string                 `protobuf:"bytes,3,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	Resource      ShareResource          `protobuf:"varint,4,opt,name=resource,proto3,enum=tkd.orthanc_bridge.v1.ShareResource" json:"resource,omitempty"`
+	Path          string                 `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareAccess) Reset() {
+	*x = ShareAccess{}
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareAccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareAccess) ProtoMessage() {}
+
+func (x *ShareAccess) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareAccess.ProtoReflect.Descriptor instead.
+func (*ShareAccess) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ShareAccess) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *ShareAccess) GetClientIp() string {
+	if x != nil {
+		return x.ClientIp
+	}
+	return ""
+}
+
+func (x *ShareAccess) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *ShareAccess) GetResource() ShareResource {
+	if x != nil {
+		return x.Resource
+	}
+	return ShareResource_SHARE_RESOURCE_UNSPECIFIED
+}
+
+func (x *ShareAccess) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type ListShareAccessRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Token string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// Limit may be set to return only the most recent records.
+	Limit         int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListShareAccessRequest) Reset() {
+	*x = ListShareAccessRequest{}
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListShareAccessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListShareAccessRequest) ProtoMessage() {}
+
+func (x *ListShareAccessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListShareAccessRequest.ProtoReflect.Descriptor instead.
+func (*ListShareAccessRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListShareAccessRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ListShareAccessRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListShareAccessResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accesses      []*ShareAccess         `protobuf:"bytes,1,rep,name=accesses,proto3" json:"accesses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListShareAccessResponse) Reset() {
+	*x = ListShareAccessResponse{}
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListShareAccessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListShareAccessResponse) ProtoMessage() {}
+
+func (x *ListShareAccessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListShareAccessResponse.ProtoReflect.Descriptor instead.
+func (*ListShareAccessResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListShareAccessResponse) GetAccesses() []*ShareAccess {
+	if x != nil {
+		return x.Accesses
+	}
+	return nil
+}
+
+type UpdateShareExpirationRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Token string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// Types that are valid to be assigned to Expiration:
+	//
+	//	*UpdateShareExpirationRequest_ExpireTime
+	//	*UpdateShareExpirationRequest_ValidDuration
+	Expiration    isUpdateShareExpirationRequest_Expiration `protobuf_oneof:"expiration"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateShareExpirationRequest) Reset() {
+	*x = UpdateShareExpirationRequest{}
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateShareExpirationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateShareExpirationRequest) ProtoMessage() {}
+
+func (x *UpdateShareExpirationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateShareExpirationRequest.ProtoReflect.Descriptor instead.
+func (*UpdateShareExpirationRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateShareExpirationRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *UpdateShareExpirationRequest) GetExpiration() isUpdateShareExpirationRequest_Expiration {
+	if x != nil {
+		return x.Expiration
+	}
+	return nil
+}
+
+func (x *UpdateShareExpirationRequest) GetExpireTime() *timestamppb.Timestamp {
+	if x != nil {
+		if x, ok := x.Expiration.(*UpdateShareExpirationRequest_ExpireTime); ok {
+			return x.ExpireTime
+		}
+	}
+	return nil
+}
+
+func (x *UpdateShareExpirationRequest) GetValidDuration() *durationpb.Duration {
+	if x != nil {
+		if x, ok := x.Expiration.(*UpdateShareExpirationRequest_ValidDuration); ok {
+			return x.ValidDuration
+		}
+	}
+	return nil
+}
+
+type isUpdateShareExpirationRequest_Expiration interface {
+	isUpdateShareExpirationRequest_Expiration()
+}
+
+type UpdateShareExpirationRequest_ExpireTime struct {
+	// ExpireTime sets the new expiration time of the share.
+	ExpireTime *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expire_time,json=expireTime,proto3,oneof"`
+}
+
+type UpdateShareExpirationRequest_ValidDuration struct {
+	// ValidDuration sets the expiration time relative to now.
+	ValidDuration *durationpb.Duration `protobuf:"bytes,3,opt,name=valid_duration,json=validDuration,proto3,oneof"`
+}
+
+func (*UpdateShareExpirationRequest_ExpireTime) isUpdateShareExpirationRequest_Expiration() {}
+
+func (*UpdateShareExpirationRequest_ValidDuration) isUpdateShareExpirationRequest_Expiration() {}
+
+type UpdateShareExpirationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Share         *Share                 `protobuf:"bytes,1,opt,name=share,proto3" json:"share,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateShareExpirationResponse) Reset() {
+	*x = UpdateShareExpirationResponse{}
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateShareExpirationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateShareExpirationResponse) ProtoMessage() {}
+
+func (x *UpdateShareExpirationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_share_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateShareExpirationResponse.ProtoReflect.Descriptor instead.
+func (*UpdateShareExpirationResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UpdateShareExpirationResponse) GetShare() *Share {
+	if x != nil {
+		return x.Share
+	}
+	return nil
+}
+
+var File_tkd_orthanc_bridge_v1_share_proto protoreflect.FileDescriptor
+
+const file_tkd_orthanc_bridge_v1_share_proto_rawDesc = "" +
+	"\n" +
+	"!tkd/orthanc_bridge/v1/share.proto\x12\x15tkd.orthanc_bridge.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1bbuf/validate/validate.proto\x1a\x1etkd/common/v1/descriptor.proto\"\xe0\x04\n" +
+	"\x05Share\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12;\n" +
+	"\vcreate_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createTime\x12;\n" +
+	"\vexpire_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"expireTime\x12;\n" +
+	"\vrevoke_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"revokeTime\x12\x18\n" +
+	"\acreator\x18\x05 \x01(\tR\acreator\x12\x1a\n" +
+	"\binstance\x18\x06 \x01(\tR\binstance\x12\x1b\n" +
+	"\tstudy_uid\x18\a \x01(\tR\bstudyUid\x12#\n" +
+	"\rinstance_uids\x18\b \x03(\tR\finstanceUids\x12\x1e\n" +
+	"\n" +
+	"recipients\x18\t \x03(\tR\n" +
+	"recipients\x12\x14\n" +
+	"\x05valid\x18\n" +
+	" \x01(\bR\x05valid\x12D\n" +
+	"\n" +
+	"deliveries\x18\v \x03(\v2$.tkd.orthanc_bridge.v1.ShareDeliveryR\n" +
+	"deliveries\x12F\n" +
+	"\n" +
+	"protection\x18\f \x01(\x0e2&.tkd.orthanc_bridge.v1.ShareProtectionR\n" +
+	"protection\x12\x19\n" +
+	"\bmax_uses\x18\r \x01(\x05R\amaxUses\x12\x12\n" +
+	"\x04uses\x18\x0e \x01(\x05R\x04uses\x12\x1f\n" +
+	"\vseries_uids\x18\x0f \x03(\tR\n" +
+	"seriesUids\"\xfe\x01\n" +
+	"\rShareDelivery\x12\x1c\n" +
+	"\trecipient\x18\x01 \x01(\tR\trecipient\x12E\n" +
+	"\achannel\x18\x02 \x01(\x0e2+.tkd.orthanc_bridge.v1.ShareDeliveryChannelR\achannel\x12B\n" +
+	"\x06status\x18\x03 \x01(\x0e2*.tkd.orthanc_bridge.v1.ShareDeliveryStatusR\x06status\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12.\n" +
+	"\x04time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"\xc2\x03\n" +
+	"\x12CreateShareRequest\x12$\n" +
+	"\tstudy_uid\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\bstudyUid\x12#\n" +
+	"\rinstance_uids\x18\x02 \x03(\tR\finstanceUids\x12\x1f\n" +
+	"\vseries_uids\x18\n" +
+	" \x03(\tR\n" +
+	"seriesUids\x12@\n" +
+	"\x0evalid_duration\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\rvalidDuration\x12\x1e\n" +
+	"\n" +
+	"recipients\x18\x04 \x03(\tR\n" +
+	"recipients\x12\x18\n" +
+	"\asubject\x18\x05 \x01(\tR\asubject\x12)\n" +
+	"\x10message_template\x18\x06 \x01(\tR\x0fmessageTemplate\x12\x1b\n" +
+	"\x03pin\x18\a \x01(\tB\a\xbaH\x04r\x02\x10\x04H\x00R\x03pin\x12.\n" +
+	"\x12require_birth_date\x18\b \x01(\bH\x00R\x10requireBirthDate\x12\"\n" +
+	"\bmax_uses\x18\t \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\amaxUses\x12\x1a\n" +
+	"\binstance\x18\v \x01(\tR\binstanceB\f\n" +
+	"\n" +
+	"protection\"~\n" +
+	"\x13CreateShareResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1d\n" +
+	"\n" +
+	"viewer_url\x18\x02 \x01(\tR\tviewerUrl\x122\n" +
+	"\x05share\x18\x03 \x01(\v2\x1c.tkd.orthanc_bridge.v1.ShareR\x05share\"\x99\x02\n" +
+	"\x12ShareDeliveryEvent\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1c\n" +
+	"\trecipient\x18\x02 \x01(\tR\trecipient\x12E\n" +
+	"\achannel\x18\x03 \x01(\x0e2+.tkd.orthanc_bridge.v1.ShareDeliveryChannelR\achannel\x12\x18\n" +
+	"\asubject\x18\x04 \x01(\tR\asubject\x12\x12\n" +
+	"\x04body\x18\x05 \x01(\tR\x04body\x12\x1d\n" +
+	"\n" +
+	"viewer_url\x18\x06 \x01(\tR\tviewerUrl\x12;\n" +
+	"\vexpire_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"expireTime\"s\n" +
+	"\x11ListSharesRequest\x12\x18\n" +
+	"\acreator\x18\x01 \x01(\tR\acreator\x12\x1b\n" +
+	"\tstudy_uid\x18\x02 \x01(\tR\bstudyUid\x12'\n" +
+	"\x0finclude_invalid\x18\x03 \x01(\bR\x0eincludeInvalid\"J\n" +
+	"\x12ListSharesResponse\x124\n" +
+	"\x06shares\x18\x01 \x03(\v2\x1c.tkd.orthanc_bridge.v1.ShareR\x06shares\"3\n" +
+	"\x12RevokeShareRequest\x12\x1d\n" +
+	"\x05token\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x05token\"I\n" +
+	"\x13RevokeShareResponse\x122\n" +
+	"\x05share\x18\x01 \x01(\v2\x1c.tkd.orthanc_bridge.v1.ShareR\x05share\"\xcf\x01\n" +
+	"\vShareAccess\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x1b\n" +
+	"\tclient_ip\x18\x02 \x01(\tR\bclientIp\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x03 \x01(\tR\tuserAgent\x12@\n" +
+	"\bresource\x18\x04 \x01(\x0e2$.tkd.orthanc_bridge.v1.ShareResourceR\bresource\x12\x12\n" +
+	"\x04path\x18\x05 \x01(\tR\x04path\"V\n" +
+	"\x16ListShareAccessRequest\x12\x1d\n" +
+	"\x05token\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x05token\x12\x1d\n" +
+	"\x05limit\x18\x02 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\x05limit\"Y\n" +
+	"\x17ListShareAccessResponse\x12>\n" +
+	"\baccesses\x18\x01 \x03(\v2\".tkd.orthanc_bridge.v1.ShareAccessR\baccesses\"\xd5\x01\n" +
+	"\x1cUpdateShareExpirationRequest\x12\x1d\n" +
+	"\x05token\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x05token\x12=\n" +
+	"\vexpire_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampH\x00R\n" +
+	"expireTime\x12B\n" +
+	"\x0evalid_duration\x18\x03 \x01(\v2\x19.google.protobuf.DurationH\x00R\rvalidDurationB\x13\n" +
+	"\n" +
+	"expiration\x12\x05\xbaH\x02\b\x01\"S\n" +
+	"\x1dUpdateShareExpirationResponse\x122\n" +
+	"\x05share\x18\x01 \x01(\v2\x1c.tkd.orthanc_bridge.v1.ShareR\x05share*g\n" +
+	"\x0fShareProtection\x12\x19\n" +
+	"\x15SHARE_PROTECTION_NONE\x10\x00\x12\x18\n" +
+	"\x14SHARE_PROTECTION_PIN\x10\x01\x12\x1f\n" +
+	"\x1bSHARE_PROTECTION_BIRTH_DATE\x10\x02*\x80\x01\n" +
+	"\x14ShareDeliveryChannel\x12&\n" +
+	"\"SHARE_DELIVERY_CHANNEL_UNSPECIFIED\x10\x00\x12 \n" +
+	"\x1cSHARE_DELIVERY_CHANNEL_EMAIL\x10\x01\x12\x1e\n" +
+	"\x1aSHARE_DELIVERY_CHANNEL_SMS\x10\x02*\xa0\x01\n" +
+	"\x13ShareDeliveryStatus\x12%\n" +
+	"!SHARE_DELIVERY_STATUS_UNSPECIFIED\x10\x00\x12\x1e\n" +
+	"\x1aSHARE_DELIVERY_STATUS_SENT\x10\x01\x12 \n" +
+	"\x1cSHARE_DELIVERY_STATUS_QUEUED\x10\x02\x12 \n" +
+	"\x1cSHARE_DELIVERY_STATUS_FAILED\x10\x03*~\n" +
+	"\rShareResource\x12\x1e\n" +
+	"\x1aSHARE_RESOURCE_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13SHARE_RESOURCE_QIDO\x10\x01\x12\x17\n" +
+	"\x13SHARE_RESOURCE_WADO\x10\x02\x12\x1b\n" +
+	"\x17SHARE_RESOURCE_RENDERED\x10\x032\xd7\x04\n" +
+	"\fShareService\x12k\n" +
+	"\vCreateShare\x12).tkd.orthanc_bridge.v1.CreateShareRequest\x1a*.tkd.orthanc_bridge.v1.CreateShareResponse\"\x05\xb2~\x02\b\x01\x12h\n" +
+	"\n" +
+	"ListShares\x12(.tkd.orthanc_bridge.v1.ListSharesRequest\x1a).tkd.orthanc_bridge.v1.ListSharesResponse\"\x05\xb2~\x02\b\x01\x12k\n" +
+	"\vRevokeShare\x12).tkd.orthanc_bridge.v1.RevokeShareRequest\x1a*.tkd.orthanc_bridge.v1.RevokeShareResponse\"\x05\xb2~\x02\b\x01\x12w\n" +
+	"\x0fListShareAccess\x12-.tkd.orthanc_bridge.v1.ListShareAccessRequest\x1a..tkd.orthanc_bridge.v1.ListShareAccessResponse\"\x05\xb2~\x02\b\x01\x12\x89\x01\n" +
+	"\x15UpdateShareExpiration\x123.tkd.orthanc_bridge.v1.UpdateShareExpirationRequest\x1a4.tkd.orthanc_bridge.v1.UpdateShareExpirationResponse\"\x05\xb2~\x02\b\x01BWZUgithub.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1;bridgev1b\x06proto3"
+
+var (
+	file_tkd_orthanc_bridge_v1_share_proto_rawDescOnce sync.Once
+	file_tkd_orthanc_bridge_v1_share_proto_rawDescData []byte
+)
+
+func file_tkd_orthanc_bridge_v1_share_proto_rawDescGZIP() []byte {
+	file_tkd_orthanc_bridge_v1_share_proto_rawDescOnce.Do(func() {
+		file_tkd_orthanc_bridge_v1_share_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_share_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_share_proto_rawDesc)))
+	})
+	return file_tkd_orthanc_bridge_v1_share_proto_rawDescData
+}
+
+var file_tkd_orthanc_bridge_v1_share_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_tkd_orthanc_bridge_v1_share_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_tkd_orthanc_bridge_v1_share_proto_goTypes = []any{
+	(ShareProtection)(0),                  // 0: tkd.orthanc_bridge.v1.ShareProtection
+	(ShareDeliveryChannel)(0),             // 1: tkd.orthanc_bridge.v1.ShareDeliveryChannel
+	(ShareDeliveryStatus)(0),              // 2: tkd.orthanc_bridge.v1.ShareDeliveryStatus
+	(ShareResource)(0),                    // 3: tkd.orthanc_bridge.v1.ShareResource
+	(*Share)(nil),                         // 4: tkd.orthanc_bridge.v1.Share
+	(*ShareDelivery)(nil),                 // 5: tkd.orthanc_bridge.v1.ShareDelivery
+	(*CreateShareRequest)(nil),            // 6: tkd.orthanc_bridge.v1.CreateShareRequest
+	(*CreateShareResponse)(nil),           // 7: tkd.orthanc_bridge.v1.CreateShareResponse
+	(*ShareDeliveryEvent)(nil),            // 8: tkd.orthanc_bridge.v1.ShareDeliveryEvent
+	(*ListSharesRequest)(nil),             // 9: tkd.orthanc_bridge.v1.ListSharesRequest
+	(*ListSharesResponse)(nil),            // 10: tkd.orthanc_bridge.v1.ListSharesResponse
+	(*RevokeShareRequest)(nil),            // 11: tkd.orthanc_bridge.v1.RevokeShareRequest
+	(*RevokeShareResponse)(nil),           // 12: tkd.orthanc_bridge.v1.RevokeShareResponse
+	(*ShareAccess)(nil),                   // 13: tkd.orthanc_bridge.v1.ShareAccess
+	(*ListShareAccessRequest)(nil),        // 14: tkd.orthanc_bridge.v1.ListShareAccessRequest
+	(*ListShareAccessResponse)(nil),       // 15: tkd.orthanc_bridge.v1.ListShareAccessResponse
+	(*UpdateShareExpirationRequest)(nil),  // 16: tkd.orthanc_bridge.v1.UpdateShareExpirationRequest
+	(*UpdateShareExpirationResponse)(nil), // 17: tkd.orthanc_bridge.v1.UpdateShareExpirationResponse
+	(*timestamppb.Timestamp)(nil),         // 18: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),           // 19: google.protobuf.Duration
+}
+var file_tkd_orthanc_bridge_v1_share_proto_depIdxs = []int32{
+	18, // 0: tkd.orthanc_bridge.v1.Share.create_time:type_name -> google.protobuf.Timestamp
+	18, // 1: tkd.orthanc_bridge.v1.Share.expire_time:type_name -> google.protobuf.Timestamp
+	18, // 2: tkd.orthanc_bridge.v1.Share.revoke_time:type_name -> google.protobuf.Timestamp
+	5,  // 3: tkd.orthanc_bridge.v1.Share.deliveries:type_name -> tkd.orthanc_bridge.v1.ShareDelivery
+	0,  // 4: tkd.orthanc_bridge.v1.Share.protection:type_name -> tkd.orthanc_bridge.v1.ShareProtection
+	1,  // 5: tkd.orthanc_bridge.v1.ShareDelivery.channel:type_name -> tkd.orthanc_bridge.v1.ShareDeliveryChannel
+	2,  // 6: tkd.orthanc_bridge.v1.ShareDelivery.status:type_name -> tkd.orthanc_bridge.v1.ShareDeliveryStatus
+	18, // 7: tkd.orthanc_bridge.v1.ShareDelivery.time:type_name -> google.protobuf.Timestamp
+	19, // 8: tkd.orthanc_bridge.v1.CreateShareRequest.valid_duration:type_name -> google.protobuf.Duration
+	4,  // 9: tkd.orthanc_bridge.v1.CreateShareResponse.share:type_name -> tkd.orthanc_bridge.v1.Share
+	1,  // 10: tkd.orthanc_bridge.v1.ShareDeliveryEvent.channel:type_name -> tkd.orthanc_bridge.v1.ShareDeliveryChannel
+	18, // 11: tkd.orthanc_bridge.v1.ShareDeliveryEvent.expire_time:type_name -> google.protobuf.Timestamp
+	4,  // 12: tkd.orthanc_bridge.v1.ListSharesResponse.shares:type_name -> tkd.orthanc_bridge.v1.Share
+	4,  // 13: tkd.orthanc_bridge.v1.RevokeShareResponse.share:type_name -> tkd.orthanc_bridge.v1.Share
+	18, // 14: tkd.orthanc_bridge.v1.ShareAccess.time:type_name -> google.protobuf.Timestamp
+	3,  // 15: tkd.orthanc_bridge.v1.ShareAccess.resource:type_name -> tkd.orthanc_bridge.v1.ShareResource
+	13, // 16: tkd.orthanc_bridge.v1.ListShareAccessResponse.accesses:type_name -> tkd.orthanc_bridge.v1.ShareAccess
+	18, // 17: tkd.orthanc_bridge.v1.UpdateShareExpirationRequest.expire_time:type_name -> google.protobuf.Timestamp
+	19, // 18: tkd.orthanc_bridge.v1.UpdateShareExpirationRequest.valid_duration:type_name -> google.protobuf.Duration
+	4,  // 19: tkd.orthanc_bridge.v1.UpdateShareExpirationResponse.share:type_name -> tkd.orthanc_bridge.v1.Share
+	6,  // 20: tkd.orthanc_bridge.v1.ShareService.CreateShare:input_type -> tkd.orthanc_bridge.v1.CreateShareRequest
+	9,  // 21: tkd.orthanc_bridge.v1.ShareService.ListShares:input_type -> tkd.orthanc_bridge.v1.ListSharesRequest
+	11, // 22: tkd.orthanc_bridge.v1.ShareService.RevokeShare:input_type -> tkd.orthanc_bridge.v1.RevokeShareRequest
+	14, // 23: tkd.orthanc_bridge.v1.ShareService.ListShareAccess:input_type -> tkd.orthanc_bridge.v1.ListShareAccessRequest
+	16, // 24: tkd.orthanc_bridge.v1.ShareService.UpdateShareExpiration:input_type -> tkd.orthanc_bridge.v1.UpdateShareExpirationRequest
+	7,  // 25: tkd.orthanc_bridge.v1.ShareService.CreateShare:output_type -> tkd.orthanc_bridge.v1.CreateShareResponse
+	10, // 26: tkd.orthanc_bridge.v1.ShareService.ListShares:output_type -> tkd.orthanc_bridge.v1.ListSharesResponse
+	12, // 27: tkd.orthanc_bridge.v1.ShareService.RevokeShare:output_type -> tkd.orthanc_bridge.v1.RevokeShareResponse
+	15, // 28: tkd.orthanc_bridge.v1.ShareService.ListShareAccess:output_type -> tkd.orthanc_bridge.v1.ListShareAccessResponse
+	17, // 29: tkd.orthanc_bridge.v1.ShareService.UpdateShareExpiration:output_type -> tkd.orthanc_bridge.v1.UpdateShareExpirationResponse
+	25, // [25:30] is the sub-list for method output_type
+	20, // [20:25] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
+}
+
+func init() { file_tkd_orthanc_bridge_v1_share_proto_init() }
+func file_tkd_orthanc_bridge_v1_share_proto_init() {
+	if File_tkd_orthanc_bridge_v1_share_proto != nil {
+		return
+	}
+	file_tkd_orthanc_bridge_v1_share_proto_msgTypes[2].OneofWrappers = []any{
+		(*CreateShareRequest_Pin)(nil),
+		(*CreateShareRequest_RequireBirthDate)(nil),
+	}
+	file_tkd_orthanc_bridge_v1_share_proto_msgTypes[12].OneofWrappers = []any{
+		(*UpdateShareExpirationRequest_ExpireTime)(nil),
+		(*UpdateShareExpirationRequest_ValidDuration)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_share_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_share_proto_rawDesc)),
+			NumEnums:      4,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tkd_orthanc_bridge_v1_share_proto_goTypes,
+		DependencyIndexes: file_tkd_orthanc_bridge_v1_share_proto_depIdxs,
+		EnumInfos:         file_tkd_orthanc_bridge_v1_share_proto_enumTypes,
+		MessageInfos:      file_tkd_orthanc_bridge_v1_share_proto_msgTypes,
+	}.Build()
+	File_tkd_orthanc_bridge_v1_share_proto = out.File
+	file_tkd_orthanc_bridge_v1_share_proto_goTypes = nil
+	file_tkd_orthanc_bridge_v1_share_proto_depIdxs = nil
+}
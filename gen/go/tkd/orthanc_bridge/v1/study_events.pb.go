@@ -0,0 +1,174 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: tkd/orthanc_bridge/v1/study_events.proto
+
+package bridgev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// StudyStableEvent is published when orthanc considers a study as stable,
+// i.e. no new instances have been received for a while.
+type StudyStableEvent struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	StudyUid    string                 `protobuf:"bytes,1,opt,name=study_uid,json=studyUid,proto3" json:"study_uid,omitempty"`
+	PatientName string                 `protobuf:"bytes,2,opt,name=patient_name,json=patientName,proto3" json:"patient_name,omitempty"`
+	OwnerName   string                 `protobuf:"bytes,3,opt,name=owner_name,json=ownerName,proto3" json:"owner_name,omitempty"`
+	PatientId   string                 `protobuf:"bytes,4,opt,name=patient_id,json=patientId,proto3" json:"patient_id,omitempty"`
+	Modalities  []string               `protobuf:"bytes,5,rep,name=modalities,proto3" json:"modalities,omitempty"`
+	// Instance is the name of the orthanc instance that holds the study.
+	Instance      string `protobuf:"bytes,6,opt,name=instance,proto3" json:"instance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StudyStableEvent) Reset() {
+	*x = StudyStableEvent{}
+	mi := &file_tkd_orthanc_bridge_v1_study_events_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StudyStableEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StudyStableEvent) ProtoMessage() {}
+
+func (x *StudyStableEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_study_events_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StudyStableEvent.ProtoReflect.Descriptor instead.
+func (*StudyStableEvent) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_study_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StudyStableEvent) GetStudyUid() string {
+	if x != nil {
+		return x.StudyUid
+	}
+	return ""
+}
+
+func (x *StudyStableEvent) GetPatientName() string {
+	if x != nil {
+		return x.PatientName
+	}
+	return ""
+}
+
+func (x *StudyStableEvent) GetOwnerName() string {
+	if x != nil {
+		return x.OwnerName
+	}
+	return ""
+}
+
+func (x *StudyStableEvent) GetPatientId() string {
+	if x != nil {
+		return x.PatientId
+	}
+	return ""
+}
+
+func (x *StudyStableEvent) GetModalities() []string {
+	if x != nil {
+		return x.Modalities
+	}
+	return nil
+}
+
+func (x *StudyStableEvent) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+var File_tkd_orthanc_bridge_v1_study_events_proto protoreflect.FileDescriptor
+
+const file_tkd_orthanc_bridge_v1_study_events_proto_rawDesc = "" +
+	"\n" +
+	"(tkd/orthanc_bridge/v1/study_events.proto\x12\x15tkd.orthanc_bridge.v1\"\xcc\x01\n" +
+	"\x10StudyStableEvent\x12\x1b\n" +
+	"\tstudy_uid\x18\x01 \x01(\tR\bstudyUid\x12!\n" +
+	"\fpatient_name\x18\x02 \x01(\tR\vpatientName\x12\x1d\n" +
+	"\n" +
+	"owner_name\x18\x03 \x01(\tR\townerName\x12\x1d\n" +
+	"\n" +
+	"patient_id\x18\x04 \x01(\tR\tpatientId\x12\x1e\n" +
+	"\n" +
+	"modalities\x18\x05 \x03(\tR\n" +
+	"modalities\x12\x1a\n" +
+	"\binstance\x18\x06 \x01(\tR\binstanceBWZUgithub.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1;bridgev1b\x06proto3"
+
+var (
+	file_tkd_orthanc_bridge_v1_study_events_proto_rawDescOnce sync.Once
+	file_tkd_orthanc_bridge_v1_study_events_proto_rawDescData []byte
+)
+
+func file_tkd_orthanc_bridge_v1_study_events_proto_rawDescGZIP() []byte {
+	file_tkd_orthanc_bridge_v1_study_events_proto_rawDescOnce.Do(func() {
+		file_tkd_orthanc_bridge_v1_study_events_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_study_events_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_study_events_proto_rawDesc)))
+	})
+	return file_tkd_orthanc_bridge_v1_study_events_proto_rawDescData
+}
+
+var file_tkd_orthanc_bridge_v1_study_events_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_tkd_orthanc_bridge_v1_study_events_proto_goTypes = []any{
+	(*StudyStableEvent)(nil), // 0: tkd.orthanc_bridge.v1.StudyStableEvent
+}
+var file_tkd_orthanc_bridge_v1_study_events_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_tkd_orthanc_bridge_v1_study_events_proto_init() }
+func file_tkd_orthanc_bridge_v1_study_events_proto_init() {
+	if File_tkd_orthanc_bridge_v1_study_events_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_study_events_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_study_events_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_tkd_orthanc_bridge_v1_study_events_proto_goTypes,
+		DependencyIndexes: file_tkd_orthanc_bridge_v1_study_events_proto_depIdxs,
+		MessageInfos:      file_tkd_orthanc_bridge_v1_study_events_proto_msgTypes,
+	}.Build()
+	File_tkd_orthanc_bridge_v1_study_events_proto = out.File
+	file_tkd_orthanc_bridge_v1_study_events_proto_goTypes = nil
+	file_tkd_orthanc_bridge_v1_study_events_proto_depIdxs = nil
+}
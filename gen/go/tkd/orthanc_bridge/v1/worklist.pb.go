@@ -0,0 +1,615 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: tkd/orthanc_bridge/v1/worklist.proto
+
+package bridgev1
+
+import (
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	_ "github.com/tierklinik-dobersberg/apis/gen/go/tkd/common/v1"
+	v12 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/customer/v1"
+	v11 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/dicom/v1"
+	v1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateWorklistEntryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// CustomerId is the ID of the customer that owns the patient.
+	CustomerId string `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	// PatientId is the ID of the patient as used by the customer service.
+	PatientId string `protobuf:"bytes,2,opt,name=patient_id,json=patientId,proto3" json:"patient_id,omitempty"`
+	// Modality is the requested modality (e.g. CR, US, DX).
+	Modality string `protobuf:"bytes,3,opt,name=modality,proto3" json:"modality,omitempty"`
+	// ScheduledTime is the time at which the procedure is scheduled.
+	ScheduledTime *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=scheduled_time,json=scheduledTime,proto3" json:"scheduled_time,omitempty"`
+	// ScheduledStationAeTitle may be set to schedule the procedure on a
+	// specific modality.
+	ScheduledStationAeTitle string `protobuf:"bytes,5,opt,name=scheduled_station_ae_title,json=scheduledStationAeTitle,proto3" json:"scheduled_station_ae_title,omitempty"`
+	// Description is an optional description of the requested procedure.
+	Description   string `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateWorklistEntryRequest) Reset() {
+	*x = CreateWorklistEntryRequest{}
+	mi := &file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWorklistEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWorklistEntryRequest) ProtoMessage() {}
+
+func (x *CreateWorklistEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateWorklistEntryRequest.ProtoReflect.Descriptor instead.
+func (*CreateWorklistEntryRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_worklist_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateWorklistEntryRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *CreateWorklistEntryRequest) GetPatientId() string {
+	if x != nil {
+		return x.PatientId
+	}
+	return ""
+}
+
+func (x *CreateWorklistEntryRequest) GetModality() string {
+	if x != nil {
+		return x.Modality
+	}
+	return ""
+}
+
+func (x *CreateWorklistEntryRequest) GetScheduledTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScheduledTime
+	}
+	return nil
+}
+
+func (x *CreateWorklistEntryRequest) GetScheduledStationAeTitle() string {
+	if x != nil {
+		return x.ScheduledStationAeTitle
+	}
+	return ""
+}
+
+func (x *CreateWorklistEntryRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type CreateWorklistEntryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entry         *v1.WorklistEntry      `protobuf:"bytes,1,opt,name=entry,proto3" json:"entry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateWorklistEntryResponse) Reset() {
+	*x = CreateWorklistEntryResponse{}
+	mi := &file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWorklistEntryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWorklistEntryResponse) ProtoMessage() {}
+
+func (x *CreateWorklistEntryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateWorklistEntryResponse.ProtoReflect.Descriptor instead.
+func (*CreateWorklistEntryResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_worklist_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateWorklistEntryResponse) GetEntry() *v1.WorklistEntry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+type DeleteWorklistEntryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name is the name of the worklist entry as returned by
+	// GetWorklistEntries.
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteWorklistEntryRequest) Reset() {
+	*x = DeleteWorklistEntryRequest{}
+	mi := &file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteWorklistEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteWorklistEntryRequest) ProtoMessage() {}
+
+func (x *DeleteWorklistEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteWorklistEntryRequest.ProtoReflect.Descriptor instead.
+func (*DeleteWorklistEntryRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_worklist_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DeleteWorklistEntryRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type UpdateWorklistEntryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name is the name of the worklist entry as returned by
+	// GetWorklistEntries.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Elements holds the DICOM elements that should be replaced or added.
+	// Attributes of the scheduled procedure step (like the scheduled date
+	// or the performing physician) are updated in the first item of the
+	// ScheduledProcedureStepSequence.
+	Elements      []*v11.Element `protobuf:"bytes,2,rep,name=elements,proto3" json:"elements,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateWorklistEntryRequest) Reset() {
+	*x = UpdateWorklistEntryRequest{}
+	mi := &file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateWorklistEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateWorklistEntryRequest) ProtoMessage() {}
+
+func (x *UpdateWorklistEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateWorklistEntryRequest.ProtoReflect.Descriptor instead.
+func (*UpdateWorklistEntryRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_worklist_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UpdateWorklistEntryRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateWorklistEntryRequest) GetElements() []*v11.Element {
+	if x != nil {
+		return x.Elements
+	}
+	return nil
+}
+
+type UpdateWorklistEntryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entry         *v1.WorklistEntry      `protobuf:"bytes,1,opt,name=entry,proto3" json:"entry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateWorklistEntryResponse) Reset() {
+	*x = UpdateWorklistEntryResponse{}
+	mi := &file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateWorklistEntryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateWorklistEntryResponse) ProtoMessage() {}
+
+func (x *UpdateWorklistEntryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateWorklistEntryResponse.ProtoReflect.Descriptor instead.
+func (*UpdateWorklistEntryResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_worklist_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpdateWorklistEntryResponse) GetEntry() *v1.WorklistEntry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+type TestWorklistRulesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// CustomerId and PatientId may be set to load the customer and patient
+	// from the customer service. If empty, customer and patient are used
+	// as sample data instead.
+	CustomerId string `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	PatientId  string `protobuf:"bytes,2,opt,name=patient_id,json=patientId,proto3" json:"patient_id,omitempty"`
+	// Customer and Patient hold sample data for the rules.
+	Customer *v12.Customer `protobuf:"bytes,3,opt,name=customer,proto3" json:"customer,omitempty"`
+	Patient  *v12.Patient  `protobuf:"bytes,4,opt,name=patient,proto3" json:"patient,omitempty"`
+	// Modality is the requested modality (e.g. CR, US, DX).
+	Modality string `protobuf:"bytes,5,opt,name=modality,proto3" json:"modality,omitempty"`
+	// ScheduledTime defaults to the current time.
+	ScheduledTime           *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=scheduled_time,json=scheduledTime,proto3" json:"scheduled_time,omitempty"`
+	ScheduledStationAeTitle string                 `protobuf:"bytes,7,opt,name=scheduled_station_ae_title,json=scheduledStationAeTitle,proto3" json:"scheduled_station_ae_title,omitempty"`
+	Description             string                 `protobuf:"bytes,8,opt,name=description,proto3" json:"description,omitempty"`
+	// Script is the JavaScript source of the rule file that should be
+	// tested. If empty, the installed rule set is used.
+	Script        string `protobuf:"bytes,9,opt,name=script,proto3" json:"script,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TestWorklistRulesRequest) Reset() {
+	*x = TestWorklistRulesRequest{}
+	mi := &file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TestWorklistRulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TestWorklistRulesRequest) ProtoMessage() {}
+
+func (x *TestWorklistRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TestWorklistRulesRequest.ProtoReflect.Descriptor instead.
+func (*TestWorklistRulesRequest) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_worklist_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TestWorklistRulesRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *TestWorklistRulesRequest) GetPatientId() string {
+	if x != nil {
+		return x.PatientId
+	}
+	return ""
+}
+
+func (x *TestWorklistRulesRequest) GetCustomer() *v12.Customer {
+	if x != nil {
+		return x.Customer
+	}
+	return nil
+}
+
+func (x *TestWorklistRulesRequest) GetPatient() *v12.Patient {
+	if x != nil {
+		return x.Patient
+	}
+	return nil
+}
+
+func (x *TestWorklistRulesRequest) GetModality() string {
+	if x != nil {
+		return x.Modality
+	}
+	return ""
+}
+
+func (x *TestWorklistRulesRequest) GetScheduledTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScheduledTime
+	}
+	return nil
+}
+
+func (x *TestWorklistRulesRequest) GetScheduledStationAeTitle() string {
+	if x != nil {
+		return x.ScheduledStationAeTitle
+	}
+	return ""
+}
+
+func (x *TestWorklistRulesRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *TestWorklistRulesRequest) GetScript() string {
+	if x != nil {
+		return x.Script
+	}
+	return ""
+}
+
+type TestWorklistRulesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Elements holds the generated DICOM dataset.
+	Elements []*v11.Element `protobuf:"bytes,1,rep,name=elements,proto3" json:"elements,omitempty"`
+	// Errors holds all errors reported while compiling or executing the
+	// rules.
+	Errors []string `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	// ReloadError is set if the installed rule set has been changed but
+	// could not be reloaded. An older version of the rules is still active
+	// in this case.
+	ReloadError   string `protobuf:"bytes,3,opt,name=reload_error,json=reloadError,proto3" json:"reload_error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TestWorklistRulesResponse) Reset() {
+	*x = TestWorklistRulesResponse{}
+	mi := &file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TestWorklistRulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TestWorklistRulesResponse) ProtoMessage() {}
+
+func (x *TestWorklistRulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TestWorklistRulesResponse.ProtoReflect.Descriptor instead.
+func (*TestWorklistRulesResponse) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_worklist_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TestWorklistRulesResponse) GetElements() []*v11.Element {
+	if x != nil {
+		return x.Elements
+	}
+	return nil
+}
+
+func (x *TestWorklistRulesResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *TestWorklistRulesResponse) GetReloadError() string {
+	if x != nil {
+		return x.ReloadError
+	}
+	return ""
+}
+
+var File_tkd_orthanc_bridge_v1_worklist_proto protoreflect.FileDescriptor
+
+const file_tkd_orthanc_bridge_v1_worklist_proto_rawDesc = "" +
+	"\n" +
+	"$tkd/orthanc_bridge/v1/worklist.proto\x12\x15tkd.orthanc_bridge.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1bbuf/validate/validate.proto\x1a\x1etkd/common/v1/descriptor.proto\x1a\x1etkd/customer/v1/customer.proto\x1a\x1dtkd/customer/v1/patient.proto\x1a\x18tkd/dicom/v1/dicom.proto\x1a*tkd/orthanc_bridge/v1/orthanc-bridge.proto\"\xbd\x02\n" +
+	"\x1aCreateWorklistEntryRequest\x12(\n" +
+	"\vcustomer_id\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\n" +
+	"customerId\x12&\n" +
+	"\n" +
+	"patient_id\x18\x02 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\tpatientId\x12#\n" +
+	"\bmodality\x18\x03 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\bmodality\x12I\n" +
+	"\x0escheduled_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampB\x06\xbaH\x03\xc8\x01\x01R\rscheduledTime\x12;\n" +
+	"\x1ascheduled_station_ae_title\x18\x05 \x01(\tR\x17scheduledStationAeTitle\x12 \n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\"Y\n" +
+	"\x1bCreateWorklistEntryResponse\x12:\n" +
+	"\x05entry\x18\x01 \x01(\v2$.tkd.orthanc_bridge.v1.WorklistEntryR\x05entry\"9\n" +
+	"\x1aDeleteWorklistEntryRequest\x12\x1b\n" +
+	"\x04name\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x04name\"v\n" +
+	"\x1aUpdateWorklistEntryRequest\x12\x1b\n" +
+	"\x04name\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x04name\x12;\n" +
+	"\belements\x18\x02 \x03(\v2\x15.tkd.dicom.v1.ElementB\b\xbaH\x05\x92\x01\x02\b\x01R\belements\"Y\n" +
+	"\x1bUpdateWorklistEntryResponse\x12:\n" +
+	"\x05entry\x18\x01 \x01(\v2$.tkd.orthanc_bridge.v1.WorklistEntryR\x05entry\"\xa4\x03\n" +
+	"\x18TestWorklistRulesRequest\x12\x1f\n" +
+	"\vcustomer_id\x18\x01 \x01(\tR\n" +
+	"customerId\x12\x1d\n" +
+	"\n" +
+	"patient_id\x18\x02 \x01(\tR\tpatientId\x125\n" +
+	"\bcustomer\x18\x03 \x01(\v2\x19.tkd.customer.v1.CustomerR\bcustomer\x122\n" +
+	"\apatient\x18\x04 \x01(\v2\x18.tkd.customer.v1.PatientR\apatient\x12#\n" +
+	"\bmodality\x18\x05 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\bmodality\x12A\n" +
+	"\x0escheduled_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\rscheduledTime\x12;\n" +
+	"\x1ascheduled_station_ae_title\x18\a \x01(\tR\x17scheduledStationAeTitle\x12 \n" +
+	"\vdescription\x18\b \x01(\tR\vdescription\x12\x16\n" +
+	"\x06script\x18\t \x01(\tR\x06script\"\x89\x01\n" +
+	"\x19TestWorklistRulesResponse\x121\n" +
+	"\belements\x18\x01 \x03(\v2\x15.tkd.dicom.v1.ElementR\belements\x12\x16\n" +
+	"\x06errors\x18\x02 \x03(\tR\x06errors\x12!\n" +
+	"\freload_error\x18\x03 \x01(\tR\vreloadError2\x85\x04\n" +
+	"\x0fWorklistService\x12\x83\x01\n" +
+	"\x13CreateWorklistEntry\x121.tkd.orthanc_bridge.v1.CreateWorklistEntryRequest\x1a2.tkd.orthanc_bridge.v1.CreateWorklistEntryResponse\"\x05\xb2~\x02\b\x01\x12g\n" +
+	"\x13DeleteWorklistEntry\x121.tkd.orthanc_bridge.v1.DeleteWorklistEntryRequest\x1a\x16.google.protobuf.Empty\"\x05\xb2~\x02\b\x01\x12\x83\x01\n" +
+	"\x13UpdateWorklistEntry\x121.tkd.orthanc_bridge.v1.UpdateWorklistEntryRequest\x1a2.tkd.orthanc_bridge.v1.UpdateWorklistEntryResponse\"\x05\xb2~\x02\b\x01\x12}\n" +
+	"\x11TestWorklistRules\x12/.tkd.orthanc_bridge.v1.TestWorklistRulesRequest\x1a0.tkd.orthanc_bridge.v1.TestWorklistRulesResponse\"\x05\xb2~\x02\b\x01BWZUgithub.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1;bridgev1b\x06proto3"
+
+var (
+	file_tkd_orthanc_bridge_v1_worklist_proto_rawDescOnce sync.Once
+	file_tkd_orthanc_bridge_v1_worklist_proto_rawDescData []byte
+)
+
+func file_tkd_orthanc_bridge_v1_worklist_proto_rawDescGZIP() []byte {
+	file_tkd_orthanc_bridge_v1_worklist_proto_rawDescOnce.Do(func() {
+		file_tkd_orthanc_bridge_v1_worklist_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_worklist_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_worklist_proto_rawDesc)))
+	})
+	return file_tkd_orthanc_bridge_v1_worklist_proto_rawDescData
+}
+
+var file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_tkd_orthanc_bridge_v1_worklist_proto_goTypes = []any{
+	(*CreateWorklistEntryRequest)(nil),  // 0: tkd.orthanc_bridge.v1.CreateWorklistEntryRequest
+	(*CreateWorklistEntryResponse)(nil), // 1: tkd.orthanc_bridge.v1.CreateWorklistEntryResponse
+	(*DeleteWorklistEntryRequest)(nil),  // 2: tkd.orthanc_bridge.v1.DeleteWorklistEntryRequest
+	(*UpdateWorklistEntryRequest)(nil),  // 3: tkd.orthanc_bridge.v1.UpdateWorklistEntryRequest
+	(*UpdateWorklistEntryResponse)(nil), // 4: tkd.orthanc_bridge.v1.UpdateWorklistEntryResponse
+	(*TestWorklistRulesRequest)(nil),    // 5: tkd.orthanc_bridge.v1.TestWorklistRulesRequest
+	(*TestWorklistRulesResponse)(nil),   // 6: tkd.orthanc_bridge.v1.TestWorklistRulesResponse
+	(*timestamppb.Timestamp)(nil),       // 7: google.protobuf.Timestamp
+	(*v1.WorklistEntry)(nil),            // 8: tkd.orthanc_bridge.v1.WorklistEntry
+	(*v11.Element)(nil),                 // 9: tkd.dicom.v1.Element
+	(*v12.Customer)(nil),                // 10: tkd.customer.v1.Customer
+	(*v12.Patient)(nil),                 // 11: tkd.customer.v1.Patient
+	(*emptypb.Empty)(nil),               // 12: google.protobuf.Empty
+}
+var file_tkd_orthanc_bridge_v1_worklist_proto_depIdxs = []int32{
+	7,  // 0: tkd.orthanc_bridge.v1.CreateWorklistEntryRequest.scheduled_time:type_name -> google.protobuf.Timestamp
+	8,  // 1: tkd.orthanc_bridge.v1.CreateWorklistEntryResponse.entry:type_name -> tkd.orthanc_bridge.v1.WorklistEntry
+	9,  // 2: tkd.orthanc_bridge.v1.UpdateWorklistEntryRequest.elements:type_name -> tkd.dicom.v1.Element
+	8,  // 3: tkd.orthanc_bridge.v1.UpdateWorklistEntryResponse.entry:type_name -> tkd.orthanc_bridge.v1.WorklistEntry
+	10, // 4: tkd.orthanc_bridge.v1.TestWorklistRulesRequest.customer:type_name -> tkd.customer.v1.Customer
+	11, // 5: tkd.orthanc_bridge.v1.TestWorklistRulesRequest.patient:type_name -> tkd.customer.v1.Patient
+	7,  // 6: tkd.orthanc_bridge.v1.TestWorklistRulesRequest.scheduled_time:type_name -> google.protobuf.Timestamp
+	9,  // 7: tkd.orthanc_bridge.v1.TestWorklistRulesResponse.elements:type_name -> tkd.dicom.v1.Element
+	0,  // 8: tkd.orthanc_bridge.v1.WorklistService.CreateWorklistEntry:input_type -> tkd.orthanc_bridge.v1.CreateWorklistEntryRequest
+	2,  // 9: tkd.orthanc_bridge.v1.WorklistService.DeleteWorklistEntry:input_type -> tkd.orthanc_bridge.v1.DeleteWorklistEntryRequest
+	3,  // 10: tkd.orthanc_bridge.v1.WorklistService.UpdateWorklistEntry:input_type -> tkd.orthanc_bridge.v1.UpdateWorklistEntryRequest
+	5,  // 11: tkd.orthanc_bridge.v1.WorklistService.TestWorklistRules:input_type -> tkd.orthanc_bridge.v1.TestWorklistRulesRequest
+	1,  // 12: tkd.orthanc_bridge.v1.WorklistService.CreateWorklistEntry:output_type -> tkd.orthanc_bridge.v1.CreateWorklistEntryResponse
+	12, // 13: tkd.orthanc_bridge.v1.WorklistService.DeleteWorklistEntry:output_type -> google.protobuf.Empty
+	4,  // 14: tkd.orthanc_bridge.v1.WorklistService.UpdateWorklistEntry:output_type -> tkd.orthanc_bridge.v1.UpdateWorklistEntryResponse
+	6,  // 15: tkd.orthanc_bridge.v1.WorklistService.TestWorklistRules:output_type -> tkd.orthanc_bridge.v1.TestWorklistRulesResponse
+	12, // [12:16] is the sub-list for method output_type
+	8,  // [8:12] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_tkd_orthanc_bridge_v1_worklist_proto_init() }
+func file_tkd_orthanc_bridge_v1_worklist_proto_init() {
+	if File_tkd_orthanc_bridge_v1_worklist_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_worklist_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_worklist_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tkd_orthanc_bridge_v1_worklist_proto_goTypes,
+		DependencyIndexes: file_tkd_orthanc_bridge_v1_worklist_proto_depIdxs,
+		MessageInfos:      file_tkd_orthanc_bridge_v1_worklist_proto_msgTypes,
+	}.Build()
+	File_tkd_orthanc_bridge_v1_worklist_proto = out.File
+	file_tkd_orthanc_bridge_v1_worklist_proto_goTypes = nil
+	file_tkd_orthanc_bridge_v1_worklist_proto_depIdxs = nil
+}
@@ -0,0 +1,127 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: tkd/orthanc_bridge/v1/worklist_events.proto
+
+package bridgev1
+
+import (
+	v1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// WorklistEntryUpdatedEvent is published when an existing worklist entry
+// has been changed. New entries are published as WorklistEntryCreatedEvent.
+type WorklistEntryUpdatedEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entry         *v1.WorklistEntry      `protobuf:"bytes,1,opt,name=entry,proto3" json:"entry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorklistEntryUpdatedEvent) Reset() {
+	*x = WorklistEntryUpdatedEvent{}
+	mi := &file_tkd_orthanc_bridge_v1_worklist_events_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorklistEntryUpdatedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorklistEntryUpdatedEvent) ProtoMessage() {}
+
+func (x *WorklistEntryUpdatedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_tkd_orthanc_bridge_v1_worklist_events_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorklistEntryUpdatedEvent.ProtoReflect.Descriptor instead.
+func (*WorklistEntryUpdatedEvent) Descriptor() ([]byte, []int) {
+	return file_tkd_orthanc_bridge_v1_worklist_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *WorklistEntryUpdatedEvent) GetEntry() *v1.WorklistEntry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+var File_tkd_orthanc_bridge_v1_worklist_events_proto protoreflect.FileDescriptor
+
+const file_tkd_orthanc_bridge_v1_worklist_events_proto_rawDesc = "" +
+	"\n" +
+	"+tkd/orthanc_bridge/v1/worklist_events.proto\x12\x15tkd.orthanc_bridge.v1\x1a*tkd/orthanc_bridge/v1/orthanc-bridge.proto\"W\n" +
+	"\x19WorklistEntryUpdatedEvent\x12:\n" +
+	"\x05entry\x18\x01 \x01(\v2$.tkd.orthanc_bridge.v1.WorklistEntryR\x05entryBWZUgithub.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1;bridgev1b\x06proto3"
+
+var (
+	file_tkd_orthanc_bridge_v1_worklist_events_proto_rawDescOnce sync.Once
+	file_tkd_orthanc_bridge_v1_worklist_events_proto_rawDescData []byte
+)
+
+func file_tkd_orthanc_bridge_v1_worklist_events_proto_rawDescGZIP() []byte {
+	file_tkd_orthanc_bridge_v1_worklist_events_proto_rawDescOnce.Do(func() {
+		file_tkd_orthanc_bridge_v1_worklist_events_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_worklist_events_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_worklist_events_proto_rawDesc)))
+	})
+	return file_tkd_orthanc_bridge_v1_worklist_events_proto_rawDescData
+}
+
+var file_tkd_orthanc_bridge_v1_worklist_events_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_tkd_orthanc_bridge_v1_worklist_events_proto_goTypes = []any{
+	(*WorklistEntryUpdatedEvent)(nil), // 0: tkd.orthanc_bridge.v1.WorklistEntryUpdatedEvent
+	(*v1.WorklistEntry)(nil),          // 1: tkd.orthanc_bridge.v1.WorklistEntry
+}
+var file_tkd_orthanc_bridge_v1_worklist_events_proto_depIdxs = []int32{
+	1, // 0: tkd.orthanc_bridge.v1.WorklistEntryUpdatedEvent.entry:type_name -> tkd.orthanc_bridge.v1.WorklistEntry
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_tkd_orthanc_bridge_v1_worklist_events_proto_init() }
+func file_tkd_orthanc_bridge_v1_worklist_events_proto_init() {
+	if File_tkd_orthanc_bridge_v1_worklist_events_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tkd_orthanc_bridge_v1_worklist_events_proto_rawDesc), len(file_tkd_orthanc_bridge_v1_worklist_events_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_tkd_orthanc_bridge_v1_worklist_events_proto_goTypes,
+		DependencyIndexes: file_tkd_orthanc_bridge_v1_worklist_events_proto_depIdxs,
+		MessageInfos:      file_tkd_orthanc_bridge_v1_worklist_events_proto_msgTypes,
+	}.Build()
+	File_tkd_orthanc_bridge_v1_worklist_events_proto = out.File
+	file_tkd_orthanc_bridge_v1_worklist_events_proto_goTypes = nil
+	file_tkd_orthanc_bridge_v1_worklist_events_proto_depIdxs = nil
+}
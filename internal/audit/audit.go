@@ -0,0 +1,137 @@
+// Package audit records accesses to patient imaging data in an append-only
+// audit trail.
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+)
+
+const (
+	// bufferSize is the number of entries that may be queued before
+	// Record starts dropping entries.
+	bufferSize = 4096
+
+	// maxBatchSize limits the number of entries written at once.
+	maxBatchSize = 256
+
+	flushInterval = time.Second
+	writeTimeout  = 10 * time.Second
+)
+
+// Store persists audit entries. It is implemented by *repo.Repo.
+type Store interface {
+	AddAuditEntries(ctx context.Context, entries []repo.AuditEntry) error
+}
+
+// Logger writes audit entries to a Store in the background.
+type Logger struct {
+	store   Store
+	entries chan repo.AuditEntry
+
+	// done is closed once the logger has stopped writing entries.
+	done chan struct{}
+}
+
+// NewLogger returns a new logger that writes entries to store until ctx is
+// cancelled.
+func NewLogger(ctx context.Context, store Store) *Logger {
+	l := &Logger{
+		store:   store,
+		entries: make(chan repo.AuditEntry, bufferSize),
+		done:    make(chan struct{}),
+	}
+
+	go l.run(ctx)
+
+	return l
+}
+
+// Record queues entry for writing. If entry.Time is unset, the current time
+// is used. Record never blocks; if the queue is full or the logger has been
+// stopped, the entry is written to the service logs instead. Record may be
+// called on a nil logger.
+func (l *Logger) Record(entry repo.AuditEntry) {
+	if l == nil {
+		return
+	}
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	select {
+	case <-l.done:
+		logEntry("audit logger stopped, dropping audit entry", nil, entry)
+		return
+	default:
+	}
+
+	select {
+	case l.entries <- entry:
+	default:
+		logEntry("audit queue full, dropping audit entry", nil, entry)
+	}
+}
+
+// logEntry writes e to the service logs so it is not lost completely.
+func logEntry(msg string, err error, e repo.AuditEntry) {
+	slog.Error(msg, "error", err, "time", e.Time, "user", e.User, "share", e.ShareToken, "action", e.Action, "studies", e.StudyUIDs, "outcome", e.Outcome, "detail", e.Detail)
+}
+
+func (l *Logger) run(ctx context.Context) {
+	defer close(l.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]repo.AuditEntry, 0, maxBatchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		writeCtx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+		defer cancel()
+
+		if err := l.store.AddAuditEntries(writeCtx, batch); err != nil {
+			// keep the entries in the service logs so they are not lost
+			// completely.
+			for _, e := range batch {
+				logEntry("failed to write audit entry", err, e)
+			}
+		}
+
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case e := <-l.entries:
+			batch = append(batch, e)
+
+			if len(batch) >= maxBatchSize {
+				flush()
+			}
+
+		case <-ticker.C:
+			flush()
+
+		case <-ctx.Done():
+			// write all entries that are still queued
+			for {
+				select {
+				case e := <-l.entries:
+					batch = append(batch, e)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// trustedProxies holds the networks of reverse proxies whose
+// X-Forwarded-For header is honoured. It is configured once on startup
+// using SetTrustedProxies.
+var trustedProxies []netip.Prefix
+
+// SetTrustedProxies configures the reverse proxies that are allowed to set
+// the X-Forwarded-For header. Each entry is either an IP address or a
+// network in CIDR notation. If no proxies are configured, the header is
+// ignored.
+func SetTrustedProxies(proxies []string) error {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		if !strings.Contains(p, "/") {
+			addr, err := netip.ParseAddr(p)
+			if err != nil {
+				return fmt.Errorf("invalid trusted proxy %q: %w", p, err)
+			}
+
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: %w", p, err)
+		}
+
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	trustedProxies = prefixes
+
+	return nil
+}
+
+// ClientIP returns the IP address of the client that sent r.
+func ClientIP(r *http.Request) string {
+	return RemoteIP(r.Header, r.RemoteAddr)
+}
+
+// RemoteIP returns the IP address of a client given the request header and
+// the remote address of the connection. If the connection has been opened
+// by a trusted proxy, the X-Forwarded-For header is searched from right to
+// left for the first address that does not belong to a trusted proxy.
+func RemoteIP(header http.Header, remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	if !isTrustedProxy(host) {
+		return host
+	}
+
+	var forwarded []string
+	for _, value := range header.Values("X-Forwarded-For") {
+		for _, addr := range strings.Split(value, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				forwarded = append(forwarded, addr)
+			}
+		}
+	}
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		if !isTrustedProxy(forwarded[i]) {
+			return forwarded[i]
+		}
+
+		host = forwarded[i]
+	}
+
+	return host
+}
+
+func isTrustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+	for _, p := range trustedProxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
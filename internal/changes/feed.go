@@ -0,0 +1,167 @@
+package changes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/orthanc"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+)
+
+// pageSize is the number of changes requested from orthanc at once.
+const pageSize = 100
+
+type Storage interface {
+	GetChangeSeq(ctx context.Context, consumer string) (int, error)
+	SaveChangeSeq(ctx context.Context, consumer string, seq int) error
+}
+
+// Handler processes a page of changes. If an error is returned the page
+// is retried during the next poll.
+type Handler func(ctx context.Context, changes []orthanc.ChangeResult) error
+
+// InitFunc is called if there is no stored sequence number for a consumer
+// and must return the sequence number to start from.
+type InitFunc func(ctx context.Context) (int, error)
+
+// Feed tails the /changes endpoint of an orthanc instance and passes all
+// changes to a handler. The sequence number of the last processed change is
+// persisted so processing continues where it stopped after a restart.
+type Feed struct {
+	consumer string
+	cli      *orthanc.Client
+	repo     Storage
+	interval time.Duration
+	init     InitFunc
+	handler  Handler
+
+	wg sync.WaitGroup
+}
+
+// Tail starts a new change feed. The consumer is used to store the sequence
+// number of the last processed change and must be unique.
+func Tail(ctx context.Context, consumer string, cli *orthanc.Client, repo Storage, interval time.Duration, init InitFunc, handler Handler) *Feed {
+	feed := &Feed{
+		consumer: consumer,
+		cli:      cli,
+		repo:     repo,
+		interval: interval,
+		init:     init,
+		handler:  handler,
+	}
+
+	feed.start(ctx)
+
+	return feed
+}
+
+// StartAtLast is an InitFunc that skips all changes that happened before
+// the consumer has been started for the first time.
+func StartAtLast(cli *orthanc.Client) InitFunc {
+	return func(ctx context.Context) (int, error) {
+		last, err := cli.GetLastChange(ctx)
+		if err != nil {
+			return -1, fmt.Errorf("failed to fetch last change: %w", err)
+		}
+
+		return last.Last, nil
+	}
+}
+
+func (feed *Feed) start(ctx context.Context) {
+	feed.wg.Add(1)
+	go func() {
+		defer feed.wg.Done()
+
+		ticker := time.NewTicker(feed.interval)
+		defer ticker.Stop()
+
+		// -1 means that we still need to load the last processed sequence number
+		seq := -1
+
+		for {
+			if seq < 0 {
+				var err error
+
+				seq, err = feed.loadSeq(ctx)
+				if err != nil {
+					slog.Error("failed to prepare change feed", "consumer", feed.consumer, "error", err)
+					seq = -1
+				}
+			}
+
+			if seq >= 0 {
+				seq = feed.processChanges(ctx, seq)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (feed *Feed) Wait() {
+	feed.wg.Wait()
+}
+
+func (feed *Feed) loadSeq(ctx context.Context) (int, error) {
+	seq, err := feed.repo.GetChangeSeq(ctx, feed.consumer)
+	if err == nil {
+		return seq, nil
+	}
+
+	if !errors.Is(err, repo.ErrNotFound) {
+		return -1, fmt.Errorf("failed to load last change sequence: %w", err)
+	}
+
+	seq, err = feed.init(ctx)
+	if err != nil {
+		return -1, err
+	}
+
+	if err := feed.repo.SaveChangeSeq(ctx, feed.consumer, seq); err != nil {
+		return -1, err
+	}
+
+	return seq, nil
+}
+
+// processChanges processes all changes since seq and returns the sequence
+// number of the last change that has been processed successfully.
+func (feed *Feed) processChanges(ctx context.Context, seq int) int {
+	for {
+		changes, err := feed.cli.GetChanges(ctx, orthanc.WithSince(seq), orthanc.WithLimit(pageSize))
+		if err != nil {
+			slog.Error("failed to fetch orthanc changes", "consumer", feed.consumer, "error", err)
+			return seq
+		}
+
+		if len(changes.Changes) > 0 {
+			if err := feed.handler(ctx, changes.Changes); err != nil {
+				// the page will be retried during the next run
+				slog.Error("failed to process orthanc changes", "consumer", feed.consumer, "error", err)
+				return seq
+			}
+		}
+
+		if changes.Last > seq {
+			if err := feed.repo.SaveChangeSeq(ctx, feed.consumer, changes.Last); err != nil {
+				slog.Error("failed to save last change sequence", "consumer", feed.consumer, "error", err)
+				return seq
+			}
+
+			seq = changes.Last
+		}
+
+		if changes.Done || len(changes.Changes) == 0 {
+			return seq
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package changes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	orthanc_bridgev1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1"
+	bridgev1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/orthanc"
+	"google.golang.org/protobuf/proto"
+)
+
+// responsiblePersonTag is not part of the main DICOM tags of orthanc and is
+// thus read from the DICOM file directly.
+const responsiblePersonTag = "0010-2297"
+
+// EventPublisher publishes event messages. It is implemented by
+// *events.Client.
+type EventPublisher interface {
+	Publish(ctx context.Context, msg proto.Message) error
+}
+
+// Publisher converts orthanc changes to events and publishes them using
+// the event service.
+type Publisher struct {
+	instance  string
+	cli       *orthanc.Client
+	publisher EventPublisher
+}
+
+// NewPublisher returns a new publisher for the orthanc instance with the
+// given name. Use Publisher.HandleChanges as the Handler of a change Feed.
+func NewPublisher(instance string, cli *orthanc.Client, publisher EventPublisher) *Publisher {
+	return &Publisher{
+		instance:  instance,
+		cli:       cli,
+		publisher: publisher,
+	}
+}
+
+// HandleChanges publishes an InstanceReceivedEvent for each new instance and a
+// StudyStableEvent for each study that became stable.
+func (p *Publisher) HandleChanges(ctx context.Context, changes []orthanc.ChangeResult) error {
+	// series and studies are cached per page since orthanc usually reports
+	// many new instances for the same series in a row.
+	series := make(map[string]orthanc.GetSeriesResponse)
+	studies := make(map[string]orthanc.GetStudyResponse)
+
+	for _, change := range changes {
+		var (
+			msg proto.Message
+			err error
+		)
+
+		switch change.ChangeType {
+		case orthanc.ChangeNewInstance:
+			msg, err = p.instanceReceived(ctx, change.ID, series, studies)
+
+		case orthanc.ChangeStableStudy:
+			msg, err = p.studyStable(ctx, change.ID)
+
+		default:
+			continue
+		}
+
+		if err != nil {
+			if errors.Is(err, orthanc.ErrNotFound) {
+				// the resource has been deleted in the meantime
+				continue
+			}
+
+			return fmt.Errorf("failed to prepare event for change %d: %w", change.Seq, err)
+		}
+
+		// Errors are only logged here since retrying the page would publish
+		// all events of the page again.
+		if err := p.publisher.Publish(ctx, msg); err != nil {
+			slog.Error("failed to publish event", "instance", p.instance, "seq", change.Seq, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (p *Publisher) instanceReceived(ctx context.Context, id string, seriesCache map[string]orthanc.GetSeriesResponse, studyCache map[string]orthanc.GetStudyResponse) (proto.Message, error) {
+	instance, err := p.cli.GetInstance(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	series, ok := seriesCache[instance.ParentSeries]
+	if !ok {
+		series, err = p.cli.GetSeries(ctx, instance.ParentSeries)
+		if err != nil {
+			return nil, err
+		}
+
+		seriesCache[series.ID] = series
+	}
+
+	study, ok := studyCache[series.ParentStudy]
+	if !ok {
+		study, err = p.cli.GetStudy(ctx, series.ParentStudy)
+		if err != nil {
+			return nil, err
+		}
+
+		studyCache[study.ID] = study
+	}
+
+	owner, err := p.ownerName(ctx, study, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &orthanc_bridgev1.InstanceReceivedEvent{
+		StudyUid:    study.MainDicomTags["StudyInstanceUID"],
+		SeriesUid:   series.MainDicomTags["SeriesInstanceUID"],
+		InstanceUid: instance.MainDicomTags["SOPInstanceUID"],
+		PatientName: strings.TrimSpace(study.PatientMainDicomTags["PatientName"]),
+		OwnerName:   owner,
+		Modality:    series.MainDicomTags["Modality"],
+		PatientId:   strings.TrimSpace(study.PatientMainDicomTags["PatientID"]),
+	}, nil
+}
+
+func (p *Publisher) studyStable(ctx context.Context, id string) (proto.Message, error) {
+	study, err := p.cli.GetStudy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := p.cli.GetStudySeries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &bridgev1.StudyStableEvent{
+		Instance:    p.instance,
+		StudyUid:    study.MainDicomTags["StudyInstanceUID"],
+		PatientName: strings.TrimSpace(study.PatientMainDicomTags["PatientName"]),
+		PatientId:   strings.TrimSpace(study.PatientMainDicomTags["PatientID"]),
+	}
+
+	var instanceId string
+	for _, s := range series {
+		if m := s.MainDicomTags["Modality"]; m != "" && !slices.Contains(event.Modalities, m) {
+			event.Modalities = append(event.Modalities, m)
+		}
+
+		if instanceId == "" && len(s.Instances) > 0 {
+			instanceId = s.Instances[0]
+		}
+	}
+
+	event.OwnerName, err = p.ownerName(ctx, study, instanceId)
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// ownerName returns the ResponsiblePerson of the study. If orthanc is not
+// configured to store it as a main DICOM tag it is read from the instance
+// with the given ID.
+func (p *Publisher) ownerName(ctx context.Context, study orthanc.GetStudyResponse, instanceId string) (string, error) {
+	if owner, ok := study.MainDicomTags["ResponsiblePerson"]; ok {
+		return strings.TrimSpace(owner), nil
+	}
+
+	if instanceId == "" {
+		return "", nil
+	}
+
+	owner, err := p.cli.GetInstanceTagValue(ctx, instanceId, responsiblePersonTag)
+	if err != nil {
+		// the tag is simply not set for this instance
+		if errors.Is(err, orthanc.ErrNotFound) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	return strings.TrimSpace(owner), nil
+}
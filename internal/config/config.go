@@ -19,12 +19,82 @@ type OrthancInstance struct {
 	Password           string `json:"password"`
 	InsecureSkipVerify bool   `json:"insecureSkipVerify"`
 	RewriteHost        string `json:"rewriteHost"`
-	DicomWeb           string `json:"dicomWebPath"` // defaults to /dicom-web/
+	DicomWeb           string `json:"dicomWebPath"`  // defaults to /dicom-web/
+	Index              bool   `json:"index"`         // keep a study index in MongoDB
+	PublishEvents      bool   `json:"publishEvents"` // publish events from the orthanc change log
+
+	// Policies restricts what IDM users may do on the DICOMweb proxy of
+	// the instance. A request is allowed if at least one policy matches.
+	// If no policies are configured, all users have full access to the
+	// DICOMweb API but not to the Orthanc REST API.
+	Policies []ProxyPolicy `json:"policies"`
+}
+
+// ProxyPolicy grants users with one of Roles access to the DICOMweb proxy.
+type ProxyPolicy struct {
+	// Roles holds role IDs or names. If empty, all users match.
+	Roles []string `json:"roles"`
+
+	// Methods holds the allowed HTTP methods (e.g. GET, POST or DELETE).
+	// If empty, only GET and HEAD are allowed.
+	Methods []string `json:"methods"`
+
+	// Paths holds the allowed path classes: qido, wado, stow, rendered or
+	// rest. The latter covers everything outside of the DICOMweb API, like
+	// the Orthanc REST API, and must be listed explicitly. If empty, all
+	// DICOMweb path classes match.
+	Paths []string `json:"paths"`
 }
 
 type WorklistConfig struct {
 	TargetDirectory string `json:"targetDirectory"`
 	RulesDirectory  string `json:"rulesDirectory"`
+
+	// MaxAge defines how long worklist entries are kept after their
+	// scheduled date. Defaults to 72h.
+	MaxAge string `json:"maxAge"`
+
+	// RuleTimeout defines how long a single worklist rule may run before it
+	// is interrupted. Defaults to 5s.
+	RuleTimeout string `json:"ruleTimeout"`
+
+	// Instance is the name of the orthanc instance that receives studies
+	// for worklist entries. Defaults to the DefaultInstance.
+	Instance string `json:"instance"`
+
+	// DIMSEListenAddress enables the built-in Modality Worklist SCP (C-ECHO
+	// and C-FIND) on the given address (e.g. ":4243"). This is not required
+	// if orthanc serves the worklist using its worklist plugin.
+	DIMSEListenAddress string `json:"dimseListen"`
+
+	// AETitle is the AE title of the built-in worklist SCP. If set,
+	// associations for other called AE titles are rejected.
+	AETitle string `json:"aeTitle"`
+}
+
+// ShareDeliveryConfig configures how study shares are delivered to
+// recipients.
+type ShareDeliveryConfig struct {
+	// SMTP is used to deliver shares to e-mail addresses.
+	SMTP *SMTPConfig `json:"smtp"`
+
+	// PublishEvents publishes a ShareDeliveryEvent for recipients that
+	// cannot be reached using SMTP (e.g. phone numbers) so the message is
+	// delivered by the notification service.
+	PublishEvents bool `json:"publishEvents"`
+
+	// Subject and Template are used if a share request does not specify a
+	// subject or message template.
+	Subject  string `json:"subject"`
+	Template string `json:"template"`
+}
+
+type SMTPConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"` // defaults to 587
+	Username string `json:"user"`
+	Password string `json:"password"`
+	From     string `json:"from"`
 }
 
 type Config struct {
@@ -35,7 +105,43 @@ type Config struct {
 	Instances           map[string]OrthancInstance `json:"instances"`
 	DefaultInstance     string                     `json:"defaultInstance"`
 	Worklist            *WorklistConfig            `json:"worklist"`
-	Mongo               struct {
+	ShareDelivery       *ShareDeliveryConfig       `json:"shareDelivery"`
+	StudyLoaderWorkers  int                        `env:"STUDY_LOADER_WORKERS" json:"studyLoaderWorkers"`
+
+	// ExportWorkers limits the number of export jobs that run
+	// concurrently. Defaults to 2.
+	ExportWorkers int `env:"EXPORT_WORKERS" json:"exportWorkers"`
+
+	// DownloadSigningKey is used to sign download links. If unset, a
+	// random key is generated on startup.
+	DownloadSigningKey string `env:"DOWNLOAD_SIGNING_KEY" json:"downloadSigningKey"`
+
+	// MediaViewerDirectory may be set to a directory containing a portable
+	// DICOM viewer. Its content can be added to the root of DICOMDIR media
+	// exports.
+	MediaViewerDirectory string `env:"MEDIA_VIEWER_DIRECTORY" json:"mediaViewerDirectory"`
+
+	// ArchiveNameTemplate names the entries of exported archives using
+	// DICOM tags, e.g. "{SeriesNumber:3}-{SeriesDescription}/{InstanceNumber:4}"
+	// which is also the default. The extension of the exported format is
+	// appended.
+	ArchiveNameTemplate string `env:"ARCHIVE_NAME_TEMPLATE" json:"archiveNameTemplate"`
+
+	// ReportColumns and ReportRows define the grid of image previews on
+	// each page of PDF study reports. Default to 2 columns and 3 rows.
+	ReportColumns int `env:"REPORT_COLUMNS" json:"reportColumns"`
+	ReportRows    int `env:"REPORT_ROWS" json:"reportRows"`
+
+	// MaxExportTTL caps the time to live of exported artifacts and the
+	// validity of their download links. Defaults to 24h.
+	MaxExportTTL string `env:"MAX_EXPORT_TTL" json:"maxExportTtl"`
+
+	// TrustedProxies lists the IP addresses or CIDR networks of reverse
+	// proxies that are allowed to set the X-Forwarded-For header. The
+	// header is ignored for connections from any other address.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" json:"trustedProxies"`
+
+	Mongo struct {
 		URL      string `json:"url"`
 		Database string `json:"database"`
 	} `json:"mongodb"`
@@ -84,6 +190,13 @@ func LoadConfig(ctx context.Context, path string) (*Config, error) {
 		cfg.AllowedOrigins = []string{"*"}
 	}
 
+	// if there's only one orthanc instance configured, use it as the default
+	if cfg.DefaultInstance == "" && len(cfg.Instances) == 1 {
+		for name := range cfg.Instances {
+			cfg.DefaultInstance = name
+		}
+	}
+
 	if cfg.Mongo.URL == "" || cfg.Mongo.Database == "" {
 		return nil, fmt.Errorf("invalid mongodb configuration")
 	}
@@ -4,56 +4,97 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/url"
 	"path"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/uid"
 	dicomv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/dicom/v1"
 	orthanc_bridgev1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1"
 	"github.com/tierklinik-dobersberg/apis/pkg/discovery/consuldiscover"
 	"github.com/tierklinik-dobersberg/apis/pkg/discovery/wellknown"
 	"github.com/tierklinik-dobersberg/apis/pkg/events"
+	bridgev1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/audit"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/changes"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dicomweb"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dimse"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/export"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/indexer"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/notify"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/orthanc"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/worklist"
 )
 
+// InstanceClients holds the API clients for a single Orthanc instance.
+type InstanceClients struct {
+	Name string
+
+	DICOMWebClient *dicomweb.Client
+	OrthancClient  *orthanc.Client
+}
+
+// changesPollInterval defines how often the orthanc change log is checked
+// for new changes.
+const changesPollInterval = 10 * time.Second
+
 type Providers struct {
 	Clients     wellknown.Clients
 	EventClient *events.Client
 
-	DICOMWebClient *dicomweb.Client
-	OrthancClient  *orthanc.Client
+	// Instances holds the API clients for each configured Orthanc instance
+	// keyed by the instance name.
+	Instances map[string]*InstanceClients
 
 	Repo *repo.Repo
 
+	// Audit records accesses to patient imaging data.
+	Audit *audit.Logger
+
 	Artifacts *export.Registry
 
+	// ExportJobs runs asynchronous export jobs.
+	ExportJobs *export.JobQueue
+
+	// Indexers holds the study indexers for all orthanc instances that
+	// have the study index enabled.
+	Indexers map[string]*indexer.Indexer
+
+	// EventFeeds holds the change feeds of all orthanc instances for which
+	// events are published.
+	EventFeeds map[string]*changes.Feed
+
 	Worklist *worklist.Worklist
 
+	// ShareNotifier delivers study shares to recipients. It is nil if
+	// share delivery is not configured.
+	ShareNotifier *notify.Dispatcher
+
 	Config Config
 }
 
 func NewProviders(ctx context.Context, cfg Config) (*Providers, error) {
-	var instance OrthancInstance
 	if cfg.DefaultInstance != "" {
-		var ok bool
-		instance, ok = cfg.Instances[cfg.DefaultInstance]
-		if !ok {
+		if _, ok := cfg.Instances[cfg.DefaultInstance]; !ok {
 			return nil, fmt.Errorf("not configuration for default client %q found", cfg.DefaultInstance)
 		}
 	}
 
-	u, err := url.Parse(instance.Address)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse instance address: %w", err)
-	}
+	instances := make(map[string]*InstanceClients, len(cfg.Instances))
+	orthancClients := make(map[string]*orthanc.Client, len(cfg.Instances))
 
-	if instance.Username != "" {
-		u.User = url.UserPassword(instance.Username, instance.Password)
+	for name, instance := range cfg.Instances {
+		clients, err := newInstanceClients(name, instance)
+		if err != nil {
+			return nil, fmt.Errorf("instance %q: %w", name, err)
+		}
+
+		instances[name] = clients
+		orthancClients[name] = clients.OrthancClient
 	}
 
 	var eventClient *events.Client
@@ -69,18 +110,6 @@ func NewProviders(ctx context.Context, cfg Config) (*Providers, error) {
 		}
 	}
 
-	webClient := dicomweb.NewClient((&url.URL{
-		Scheme: u.Scheme,
-		Host:   u.Host,
-		Path:   path.Join(u.Path, instance.DicomWeb),
-		User:   u.User,
-	}).String())
-
-	orthancClient, err := orthanc.NewClient(u.String())
-	if err != nil {
-		return nil, fmt.Errorf("failed to create orthanc client: %w", err)
-	}
-
 	storage, err := repo.New(ctx, cfg.Mongo.URL, cfg.Mongo.Database)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create repository: %w", err)
@@ -88,34 +117,259 @@ func NewProviders(ctx context.Context, cfg Config) (*Providers, error) {
 
 	clients := wellknown.ConfigureClients(wellknown.ConfigureClientOptions{})
 
+	if err := audit.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, err
+	}
+
+	auditLog := audit.NewLogger(ctx, storage)
+
+	var maxExportTTL time.Duration
+	if cfg.MaxExportTTL != "" {
+		maxExportTTL, err = time.ParseDuration(cfg.MaxExportTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for maxExportTtl: %w", err)
+		}
+	}
+
+	artifacts, err := export.NewRegistry(ctx, orthancClients, storage, auditLog, export.RegistryOptions{
+		SigningKey:      []byte(cfg.DownloadSigningKey),
+		ViewerDirectory: cfg.MediaViewerDirectory,
+		NameTemplate:    cfg.ArchiveNameTemplate,
+		ReportColumns:   cfg.ReportColumns,
+		ReportRows:      cfg.ReportRows,
+		MaxTTL:          maxExportTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create artifact registry: %w", err)
+	}
+
+	var jobEvents export.EventPublisher
+	if eventClient != nil {
+		jobEvents = eventClient
+	}
+
 	p := &Providers{
-		Clients:        clients,
-		DICOMWebClient: webClient,
-		OrthancClient:  orthancClient,
-		Config:         cfg,
-		Artifacts:      export.NewRegistry(ctx, orthancClient, storage),
-		Repo:           storage,
-		EventClient:    eventClient,
+		Clients:     clients,
+		Instances:   instances,
+		Config:      cfg,
+		Artifacts:   artifacts,
+		ExportJobs:  export.NewJobQueue(ctx, artifacts, cfg.PublicURL, jobEvents, cfg.ExportWorkers),
+		Repo:        storage,
+		Audit:       auditLog,
+		EventClient: eventClient,
+	}
+
+	p.Indexers = make(map[string]*indexer.Indexer)
+	for name, instance := range cfg.Instances {
+		if instance.Index {
+			p.Indexers[name] = indexer.New(ctx, name, instances[name].OrthancClient, storage, changesPollInterval)
+		}
+	}
+
+	p.EventFeeds = make(map[string]*changes.Feed)
+	for name, instance := range cfg.Instances {
+		if !instance.PublishEvents {
+			continue
+		}
+
+		if eventClient == nil {
+			slog.Error("event service not available, not publishing events", "instance", name)
+			continue
+		}
+
+		cli := instances[name].OrthancClient
+		publisher := changes.NewPublisher(name, cli, eventClient)
+
+		p.EventFeeds[name] = changes.Tail(ctx, "events/"+name, cli, storage, changesPollInterval, changes.StartAtLast(cli), publisher.HandleChanges)
+	}
+
+	if cfg.ShareDelivery != nil {
+		p.ShareNotifier = newShareNotifier(*cfg.ShareDelivery, eventClient)
 	}
 
 	if cfg.Worklist != nil {
-		wl, err := worklist.New(cfg.Worklist.TargetDirectory, cfg.Worklist.RulesDirectory, p.onWLEntryCreated, p.onWlEntryDeleted)
+		var ruleTimeout time.Duration
+		if cfg.Worklist.RuleTimeout != "" {
+			ruleTimeout, err = time.ParseDuration(cfg.Worklist.RuleTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("failed to configure DICOM worklist: invalid value for ruleTimeout: %w", err)
+			}
+		}
+
+		wl, err := worklist.New(cfg.Worklist.TargetDirectory, cfg.Worklist.RulesDirectory, ruleTimeout, p.onWLEntryCreated, p.onWLEntryUpdated, p.onWlEntryDeleted)
 		if err != nil {
 			return nil, fmt.Errorf("failed to configure DICOM worklist: %w", err)
 		}
 
 		p.Worklist = wl
+
+		if err := p.startWorklistHousekeeping(ctx, *cfg.Worklist); err != nil {
+			return nil, fmt.Errorf("failed to configure DICOM worklist: %w", err)
+		}
+
+		if cfg.Worklist.DIMSEListenAddress != "" {
+			if err := p.startWorklistSCP(ctx, *cfg.Worklist); err != nil {
+				return nil, fmt.Errorf("failed to start DICOM worklist SCP: %w", err)
+			}
+		}
 	}
 
 	return p, nil
 }
 
+func newShareNotifier(cfg ShareDeliveryConfig, eventClient *events.Client) *notify.Dispatcher {
+	d := notify.NewDispatcher()
+
+	if cfg.SMTP != nil {
+		d.Register(notify.ChannelEmail, notify.NewSMTP(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From))
+	}
+
+	if cfg.PublishEvents {
+		if eventClient == nil {
+			slog.Error("event service not available, shares are not delivered using the notification service")
+
+			return d
+		}
+
+		n := notify.NewEvent(eventClient)
+
+		d.Register(notify.ChannelSMS, n)
+
+		if !d.Supports(notify.ChannelEmail) {
+			d.Register(notify.ChannelEmail, n)
+		}
+	}
+
+	return d
+}
+
+// defaultWorklistMaxAge is used if WorklistConfig.MaxAge is not set.
+const defaultWorklistMaxAge = 72 * time.Hour
+
+func (p *Providers) startWorklistHousekeeping(ctx context.Context, cfg WorklistConfig) error {
+	maxAge := defaultWorklistMaxAge
+	if cfg.MaxAge != "" {
+		var err error
+
+		maxAge, err = time.ParseDuration(cfg.MaxAge)
+		if err != nil {
+			return fmt.Errorf("invalid value for maxAge: %w", err)
+		}
+	}
+
+	p.Worklist.StartHousekeeping(ctx, maxAge)
+
+	clients, ok := p.Instance(cfg.Instance)
+	if !ok {
+		if cfg.Instance != "" {
+			return fmt.Errorf("unknown orthanc instance %q", cfg.Instance)
+		}
+
+		slog.Warn("no default orthanc instance configured, worklist entries will not be completed")
+
+		return nil
+	}
+
+	cli := clients.OrthancClient
+	changes.Tail(ctx, "worklist/"+clients.Name, cli, p.Repo, changesPollInterval, changes.StartAtLast(cli), p.Worklist.CompletionHandler(cli))
+
+	return nil
+}
+
+// startWorklistSCP starts the built-in Modality Worklist SCP that answers
+// C-FIND requests from the worklist entries.
+func (p *Providers) startWorklistSCP(ctx context.Context, cfg WorklistConfig) error {
+	l, err := net.Listen("tcp", cfg.DIMSEListenAddress)
+	if err != nil {
+		return err
+	}
+
+	srv := dimse.NewServer(cfg.AETitle)
+	srv.HandleFind(uid.ModalityWorklistInformationFind, func(ctx context.Context, query dicom.Dataset) ([]dicom.Dataset, error) {
+		return p.Worklist.Find(query)
+	})
+
+	go func() {
+		slog.Info("DICOM worklist SCP listening", "address", cfg.DIMSEListenAddress, "aeTitle", cfg.AETitle)
+
+		if err := srv.Serve(ctx, l); err != nil {
+			slog.Error("DICOM worklist SCP stopped", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+func newInstanceClients(name string, instance OrthancInstance) (*InstanceClients, error) {
+	u, err := url.Parse(instance.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse instance address: %w", err)
+	}
+
+	if instance.Username != "" {
+		u.User = url.UserPassword(instance.Username, instance.Password)
+	}
+
+	webClient := dicomweb.NewClient((&url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   path.Join(u.Path, instance.DicomWeb),
+		User:   u.User,
+	}).String())
+
+	orthancClient, err := orthanc.NewClient(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create orthanc client: %w", err)
+	}
+
+	return &InstanceClients{
+		Name:           name,
+		DICOMWebClient: webClient,
+		OrthancClient:  orthancClient,
+	}, nil
+}
+
+// Instance returns the API clients for the Orthanc instance with the given
+// name. If name is empty, the clients of the DefaultInstance are returned.
+func (p *Providers) Instance(name string) (*InstanceClients, bool) {
+	if name == "" {
+		name = p.Config.DefaultInstance
+	}
+
+	clients, ok := p.Instances[name]
+
+	return clients, ok
+}
+
 func (p *Providers) onWLEntryCreated(path string, ds dicom.Dataset) {
+	// the event service may not be available if service discovery failed.
+	if p.EventClient == nil {
+		return
+	}
+
+	p.EventClient.Publish(context.Background(), &orthanc_bridgev1.WorklistEntryCreatedEvent{
+		Entry: worklistEntryProto(path, ds),
+	})
+}
+
+func (p *Providers) onWLEntryUpdated(path string, ds dicom.Dataset) {
+	if p.EventClient == nil {
+		return
+	}
+
+	p.EventClient.Publish(context.Background(), &bridgev1.WorklistEntryUpdatedEvent{
+		Entry: worklistEntryProto(path, ds),
+	})
+}
+
+// worklistEntryProto converts a worklist entry for publishing it as part
+// of an event. Elements that cannot be converted are skipped.
+func worklistEntryProto(path string, ds dicom.Dataset) *orthanc_bridgev1.WorklistEntry {
 	elements := make([]*dicomv1.Element, 0, len(ds.Elements))
 
 	merr := new(multierror.Error)
 	for _, el := range ds.Elements {
-		pb, err := dicomv1.ElementProto(el)
+		pb, err := worklist.ElementProto(el)
 		if err != nil {
 			merr.Errors = append(merr.Errors, fmt.Errorf("%s: %w", el.Tag.String(), err))
 			continue
@@ -124,19 +378,21 @@ func (p *Providers) onWLEntryCreated(path string, ds dicom.Dataset) {
 		elements = append(elements, pb)
 	}
 
-	p.EventClient.Publish(context.Background(), &orthanc_bridgev1.WorklistEntryCreatedEvent{
-		Entry: &orthanc_bridgev1.WorklistEntry{
-			Name:     path,
-			Elements: elements,
-		},
-	})
-
 	if err := merr.ErrorOrNil(); err != nil {
 		slog.Error("failed to convert one or more DICOM elements", "error", err)
 	}
+
+	return &orthanc_bridgev1.WorklistEntry{
+		Name:     path,
+		Elements: elements,
+	}
 }
 
 func (p *Providers) onWlEntryDeleted(path string) {
+	if p.EventClient == nil {
+		return
+	}
+
 	p.EventClient.Publish(context.Background(), &orthanc_bridgev1.WorklistEntryRemovedEvent{
 		Name: path,
 	})
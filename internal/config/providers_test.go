@@ -0,0 +1,72 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/suyashkumar/dicom/pkg/tag"
+	customerv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/customer/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/worklist"
+)
+
+// TestWorklistHousekeepingWithoutEventClient makes sure worklist entries
+// can be created and removed if the event service is not available.
+func TestWorklistHousekeepingWithoutEventClient(t *testing.T) {
+	p := &Providers{}
+
+	wl, err := worklist.New(t.TempDir(), t.TempDir(), 0, p.onWLEntryCreated, p.onWLEntryUpdated, p.onWlEntryDeleted)
+	if err != nil {
+		t.Fatalf("failed to create worklist: %s", err)
+	}
+	p.Worklist = wl
+
+	customer := &customerv1.Customer{FirstName: "Max", LastName: "Mustermann"}
+	patient := &customerv1.Patient{PatientId: "1", PatientName: "Rex"}
+
+	expired, err := wl.CreateEntry(customer, patient, worklist.Request{
+		Modality:      "CR",
+		ScheduledTime: time.Now().Add(-96 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("failed to create expired entry: %s", err)
+	}
+
+	completed, err := wl.CreateEntry(customer, patient, worklist.Request{
+		Modality:      "CR",
+		ScheduledTime: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create entry: %s", err)
+	}
+
+	if err := wl.RemoveExpired(time.Now().Add(-72 * time.Hour)); err != nil {
+		t.Fatalf("failed to remove expired entries: %s", err)
+	}
+
+	studyUID, err := completed.Dataset.FindElementByTag(tag.StudyInstanceUID)
+	if err != nil {
+		t.Fatalf("entry does not have a study instance UID: %s", err)
+	}
+
+	removed, err := wl.CompleteStudy("", studyUID.Value.GetValue().([]string)[0])
+	if err != nil {
+		t.Fatalf("failed to complete study: %s", err)
+	}
+
+	if len(removed) != 1 || removed[0] != completed.Path {
+		t.Errorf("expected %q to be completed, got %v", completed.Path, removed)
+	}
+
+	entries, err := wl.ListEntries()
+	if err != nil {
+		t.Fatalf("failed to list entries: %s", err)
+	}
+
+	if len(entries) != 0 {
+		t.Errorf("expected all entries to be removed, %d left (expired entry %q)", len(entries), expired.Path)
+	}
+
+	// removals are reported by the file system watcher in the background.
+	// Give it some time to process the events so a panic fails the test.
+	time.Sleep(200 * time.Millisecond)
+}
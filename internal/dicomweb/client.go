@@ -80,7 +80,12 @@ func (cli *Client) Query(ctx context.Context, req QIDORequest) ([]QIDOResponse,
 
 	case Instance:
 		endpoint += "/studies/" + req.StudyInstanceUID
-		endpoint += "/series/" + req.SeriesInstanceUID
+
+		// without a series, query all instances of the study
+		if req.SeriesInstanceUID != "" {
+			endpoint += "/series/" + req.SeriesInstanceUID
+		}
+
 		endpoint += "/instances"
 
 	case Metadata:
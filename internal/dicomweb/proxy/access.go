@@ -0,0 +1,144 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/audit"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+)
+
+// recordAccessTimeout limits the time to store a share access record.
+const recordAccessTimeout = 5 * time.Second
+
+// viewerSessions tracks the viewer sessions of study shares. A session is
+// identified by the share token, client IP and user agent and stays active
+// as long as requests arrive within ttl.
+type viewerSessions struct {
+	ttl time.Duration
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func newViewerSessions(ttl time.Duration) *viewerSessions {
+	return &viewerSessions{
+		ttl:  ttl,
+		seen: make(map[string]time.Time),
+	}
+}
+
+// active reports whether the session identified by key is still active and
+// extends it if so.
+func (vs *viewerSessions) active(key string) bool {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	last, ok := vs.seen[key]
+	if !ok || time.Since(last) >= vs.ttl {
+		return false
+	}
+
+	vs.seen[key] = time.Now()
+
+	return true
+}
+
+func (vs *viewerSessions) add(key string) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	now := time.Now()
+
+	for k, last := range vs.seen {
+		if now.Sub(last) >= vs.ttl {
+			delete(vs.seen, k)
+		}
+	}
+
+	vs.seen[key] = now
+}
+
+// trackShareAccess counts new viewer sessions of share and records the
+// request in the share access log. It returns false if the request must be
+// denied because the share has been used too often.
+func (shp *SingelHostProxy) trackShareAccess(w http.ResponseWriter, r *http.Request, share *repo.StudyShare) bool {
+	ip := audit.ClientIP(r)
+	key := share.Token + "|" + ip + "|" + r.UserAgent()
+
+	if !shp.sessions.active(key) {
+		// the viewer sends many requests in parallel so make sure a new
+		// session is only counted once.
+		_, err, _ := shp.once.Do("session:"+key, func() (any, error) {
+			if shp.sessions.active(key) {
+				return nil, nil
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), recordAccessTimeout)
+			defer cancel()
+
+			if _, err := shp.store.StartStudyShareSession(ctx, share.Token); err != nil {
+				return nil, err
+			}
+
+			shp.sessions.add(key)
+
+			return nil, nil
+		})
+
+		if err != nil {
+			if errors.Is(err, repo.ErrShareExhausted) {
+				http.Error(w, "this study share has reached its maximum number of uses", http.StatusForbidden)
+				return false
+			}
+
+			slog.Error("failed to start study share session", "token", share.Token, "error", err)
+			http.Error(w, "failed to validate study share", http.StatusInternalServerError)
+
+			return false
+		}
+	}
+
+	access := repo.ShareAccess{
+		Token:     share.Token,
+		Time:      time.Now(),
+		ClientIP:  ip,
+		UserAgent: r.UserAgent(),
+		Resource:  shareResource(r.URL.Path),
+		Path:      r.URL.Path,
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), recordAccessTimeout)
+		defer cancel()
+
+		if err := shp.store.RecordShareAccess(ctx, access); err != nil {
+			slog.Error("failed to record study share access", "token", access.Token, "error", err)
+		}
+	}()
+
+	return true
+}
+
+// shareResource returns the type of resource requested by a DICOMweb
+// request path.
+func shareResource(p string) string {
+	p = strings.TrimSuffix(p, "/")
+
+	if strings.HasSuffix(p, "/rendered") || strings.HasSuffix(p, "/thumbnail") {
+		return repo.ShareResourceRendered
+	}
+
+	switch path.Base(p) {
+	case "studies", "series", "instances":
+		return repo.ShareResourceQIDO
+	default:
+		return repo.ShareResourceWADO
+	}
+}
@@ -0,0 +1,68 @@
+package proxy
+
+import (
+	"net/http"
+
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/audit"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dicomweb"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+)
+
+// statusWriter captures the status code of a response.
+type statusWriter struct {
+	http.ResponseWriter
+
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap allows http.ResponseController to access the underlying
+// ResponseWriter.
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// auditRequest records an authenticated DICOMweb request in the audit
+// trail.
+func (shp *SingelHostProxy) auditRequest(r *http.Request, resolved resolvedAccessToken, status int) {
+	entry := repo.AuditEntry{
+		Action:   repo.AuditProxyRequest,
+		Instance: shp.Name,
+		Detail:   r.Method + " " + r.URL.Path,
+		ClientIP: audit.ClientIP(r),
+	}
+
+	if resolved.studShare != nil {
+		entry.ShareToken = resolved.studShare.Token
+	} else {
+		entry.User = resolved.userID
+	}
+
+	study := r.URL.Query().Get("StudyInstanceUID")
+	if study == "" {
+		study = r.URL.Query().Get(dicomweb.StudyInstanceUID)
+	}
+
+	if match, ok := shp.isQidoUrl(r.URL.Path); ok && match.Params["study"] != "" {
+		study = match.Params["study"]
+	}
+
+	if study != "" {
+		entry.StudyUIDs = []string{study}
+	}
+
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		entry.Outcome = repo.AuditOutcomeDenied
+	case status >= 400:
+		entry.Outcome = repo.AuditOutcomeFailed
+	default:
+		entry.Outcome = repo.AuditOutcomeSuccess
+	}
+
+	shp.audit.Record(entry)
+}
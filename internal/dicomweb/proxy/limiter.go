@@ -0,0 +1,79 @@
+package proxy
+
+import (
+	"sync"
+	"time"
+)
+
+// attemptLimiter blocks keys after too many attempts within a time window.
+// Attempts are reserved before they are verified and only successful
+// attempts are given back.
+type attemptLimiter struct {
+	max    int
+	window time.Duration
+
+	mu       sync.Mutex
+	attempts map[string]*failedAttempts
+}
+
+type failedAttempts struct {
+	count int
+	first time.Time
+}
+
+func newAttemptLimiter(max int, window time.Duration) *attemptLimiter {
+	return &attemptLimiter{
+		max:      max,
+		window:   window,
+		attempts: make(map[string]*failedAttempts),
+	}
+}
+
+// reserve counts an attempt for key before it is verified and reports
+// whether the attempt is allowed. Checking and counting is done atomically
+// so parallel attempts cannot exceed the limit. Successful attempts must be
+// given back using release or reset.
+func (l *attemptLimiter) reserve(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	for key, a := range l.attempts {
+		if now.Sub(a.first) >= l.window {
+			delete(l.attempts, key)
+		}
+	}
+
+	a, ok := l.attempts[key]
+	if !ok {
+		a = &failedAttempts{first: now}
+		l.attempts[key] = a
+	}
+
+	if a.count >= l.max {
+		return false
+	}
+
+	a.count++
+
+	return true
+}
+
+// release gives back an attempt reserved for key that succeeded.
+func (l *attemptLimiter) release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if a, ok := l.attempts[key]; ok && a.count > 0 {
+		a.count--
+	}
+}
+
+// reset removes all failed attempts of key.
+func (l *attemptLimiter) reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.attempts, key)
+}
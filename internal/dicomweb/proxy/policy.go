@@ -0,0 +1,196 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/config"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+)
+
+// Path classes used by proxy policies.
+const (
+	PathQIDO     = repo.ShareResourceQIDO
+	PathWADO     = repo.ShareResourceWADO
+	PathRendered = repo.ShareResourceRendered
+	PathSTOW     = "stow"
+
+	// PathREST matches every request outside of the DICOMweb API, like
+	// the Orthanc REST API. It is only allowed by policies that list it
+	// explicitly.
+	PathREST = "rest"
+)
+
+// defaultDicomWebPath is the path of the DICOMweb API of Orthanc if the
+// instance does not configure a dicomWebPath.
+const defaultDicomWebPath = "dicom-web"
+
+// validatePolicies ensures all policies only use known path classes.
+func validatePolicies(policies []config.ProxyPolicy) error {
+	for idx, p := range policies {
+		for _, class := range p.Paths {
+			switch strings.ToLower(class) {
+			case PathQIDO, PathWADO, PathRendered, PathSTOW, PathREST:
+			default:
+				return fmt.Errorf("policy #%d: unsupported path class %q", idx, class)
+			}
+		}
+	}
+
+	return nil
+}
+
+// dicomWebPrefix returns the path prefix of the DICOMweb API of the
+// Orthanc instance as configured by its dicomWebPath.
+func (shp *SingelHostProxy) dicomWebPrefix() string {
+	p := strings.Trim(shp.DicomWeb, "/")
+	if p == "" {
+		p = defaultDicomWebPath
+	}
+
+	return p + "/"
+}
+
+// isDicomWebPath reports whether p addresses a study, series or instance
+// resource of the DICOMweb API. Other DICOMweb endpoints, like the
+// configuration of remote DICOMweb servers, are not included.
+func (shp *SingelHostProxy) isDicomWebPath(p string) bool {
+	p, ok := strings.CutPrefix(strings.TrimPrefix(p, "/"), shp.dicomWebPrefix())
+	if !ok {
+		return false
+	}
+
+	resource, _, _ := strings.Cut(p, "/")
+
+	switch resource {
+	case "studies", "series", "instances":
+		return true
+	default:
+		return false
+	}
+}
+
+// pathClass returns the path class of a request.
+func (shp *SingelHostProxy) pathClass(r *http.Request) string {
+	p := strings.TrimSuffix(r.URL.Path, "/")
+
+	if !shp.isDicomWebPath(p) {
+		return PathREST
+	}
+
+	// STOW-RS stores instances using POST .../studies or
+	// POST .../studies/{study}
+	if r.Method == http.MethodPost {
+		if path.Base(p) == "studies" || path.Base(path.Dir(p)) == "studies" {
+			return PathSTOW
+		}
+	}
+
+	return shareResource(p)
+}
+
+// allowedByPolicy reports whether the user may perform r. Study shares are
+// restricted to read-only requests and are checked against the share scope
+// instead.
+//
+// Policies without methods only allow GET and HEAD requests and policies
+// without paths match every DICOMweb path class. Requests outside of the
+// DICOMweb API are only allowed by policies that list PathREST.
+func (shp *SingelHostProxy) allowedByPolicy(ctx context.Context, r *http.Request, resolved resolvedAccessToken) bool {
+	readOnly := r.Method == http.MethodGet || r.Method == http.MethodHead
+
+	if resolved.studShare != nil {
+		return readOnly
+	}
+
+	class := shp.pathClass(r)
+
+	if len(shp.Policies) == 0 {
+		return class != PathREST
+	}
+
+	var roles []string
+	for _, p := range shp.Policies {
+		if len(p.Methods) == 0 && !readOnly {
+			continue
+		}
+
+		if len(p.Methods) > 0 && !slices.ContainsFunc(p.Methods, func(m string) bool {
+			return strings.EqualFold(m, r.Method)
+		}) {
+			continue
+		}
+
+		if len(p.Paths) == 0 && class == PathREST {
+			continue
+		}
+
+		if len(p.Paths) > 0 && !slices.ContainsFunc(p.Paths, func(c string) bool {
+			return strings.EqualFold(c, class)
+		}) {
+			continue
+		}
+
+		if len(p.Roles) == 0 {
+			return true
+		}
+
+		// role names are only resolved if there's a policy that requires
+		// them.
+		if roles == nil {
+			roles = shp.roleNames(ctx, resolved.roleIDs)
+		}
+
+		for _, role := range p.Roles {
+			if slices.Contains(resolved.roleIDs, role) || slices.Contains(roles, role) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// roleNames resolves the names of the given role IDs. Resolved roles are
+// cached.
+func (shp *SingelHostProxy) roleNames(ctx context.Context, ids []string) []string {
+	names := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		shp.rw.RLock()
+		name, ok := shp.roles[id]
+		shp.rw.RUnlock()
+
+		if !ok {
+			res, err, _ := shp.once.Do("role:"+id, func() (any, error) {
+				role, err := shp.roleResolver(ctx, id)
+				if err != nil {
+					return nil, err
+				}
+
+				shp.rw.Lock()
+				defer shp.rw.Unlock()
+
+				shp.roles[id] = role.GetName()
+
+				return role.GetName(), nil
+			})
+
+			if err != nil {
+				slog.Error("failed to resolve role", "id", id, "error", err)
+				continue
+			}
+
+			name = res.(string)
+		}
+
+		names = append(names, name)
+	}
+
+	return names
+}
@@ -0,0 +1,317 @@
+package proxy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/audit"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/urlutils"
+)
+
+const (
+	// unlockPath is the path, relative to the proxy prefix, of the page
+	// used to unlock protected shares.
+	unlockPath = "share/unlock"
+
+	shareSessionCookiePrefix = "share_session_"
+
+	// shareSessionTTL defines how long an unlocked share may be used
+	// before the secret must be entered again.
+	shareSessionTTL = 12 * time.Hour
+
+	// maxUnlockAttempts failed attempts per share and maxClientAttempts
+	// per client IP are allowed within unlockLockout.
+	maxUnlockAttempts = 5
+	maxClientAttempts = 20
+	unlockLockout     = 15 * time.Minute
+
+	lockedShareMessage = "This link has been locked after too many failed attempts. Please ask the clinic to share the study again."
+)
+
+// Prefix returns the path prefix the proxy for the named orthanc instance
+// is served at.
+func Prefix(name string) string {
+	return "/bridge/" + name + "/"
+}
+
+// ViewerURL returns the URL that opens share in the viewer.
+func ViewerURL(publicURL string, share repo.StudyShare) string {
+	u := fmt.Sprintf("%s/viewer/?StudyInstanceUIDs=%s&token=%s", publicURL, share.StudyUID, share.Token)
+
+	if len(share.InstanceUIDs) > 0 {
+		u += "&initialSopInstanceUid=" + share.InstanceUIDs[0]
+	} else if len(share.SeriesUIDs) > 0 {
+		u += "&initialSeriesInstanceUID=" + share.SeriesUIDs[0]
+	}
+
+	return u
+}
+
+// UnlockURL returns the URL of the page used to unlock a protected share.
+// The page redirects to the viewer once the share has been unlocked.
+func UnlockURL(publicURL string, share repo.StudyShare) string {
+	return fmt.Sprintf("%s%s%s?token=%s", strings.TrimSuffix(publicURL, "/"), Prefix(share.Instance), unlockPath, url.QueryEscape(share.Token))
+}
+
+var unlockTemplate = template.Must(template.New("unlock").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Unlock shared study</title>
+	<style>
+		body { font-family: sans-serif; display: flex; justify-content: center; padding-top: 10vh; }
+		form { display: flex; flex-direction: column; gap: 0.75rem; width: 18rem; }
+		input, button { font-size: 1rem; padding: 0.5rem; }
+		.error { color: #b91c1c; }
+	</style>
+</head>
+<body>
+	<form method="post">
+		<h3>Unlock shared study</h3>
+		{{ if .Error }}<span class="error">{{ .Error }}</span>{{ end }}
+		{{ if .Label }}
+		<input type="hidden" name="token" value="{{ .Token }}">
+		<label for="secret">{{ .Label }}</label>
+		{{ if .IsPIN }}
+		<input id="secret" name="secret" type="password" inputmode="numeric" autocomplete="off" autofocus required>
+		{{ else }}
+		<input id="secret" name="secret" type="text" placeholder="DD.MM.YYYY" autocomplete="off" autofocus required>
+		{{ end }}
+		<button type="submit">Open</button>
+		{{ end }}
+	</form>
+</body>
+</html>
+`))
+
+type unlockPage struct {
+	Token string
+	Label string
+	IsPIN bool
+	Error string
+}
+
+// serveUnlock serves the page used to unlock protected shares. A correct
+// PIN or date of birth is exchanged for a short-lived session cookie that
+// is required for all requests using the share token.
+func (shp *SingelHostProxy) serveUnlock(w http.ResponseWriter, r *http.Request) {
+	var token, secret string
+
+	switch r.Method {
+	case http.MethodGet:
+		token = r.URL.Query().Get("token")
+	case http.MethodPost:
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid form", http.StatusBadRequest)
+			return
+		}
+
+		token = r.PostForm.Get("token")
+		secret = r.PostForm.Get("secret")
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	share, ok := shp.lookupShare(r.Context(), token)
+	if !ok {
+		renderUnlockPage(w, http.StatusNotFound, unlockPage{
+			Error: "This link is invalid or has expired.",
+		})
+
+		return
+	}
+
+	if !share.IsProtected() {
+		http.Redirect(w, r, ViewerURL(shp.PublicURL.String(), *share), http.StatusSeeOther)
+		return
+	}
+
+	page := unlockPage{
+		Token: token,
+		Label: "Date of birth of the patient",
+		IsPIN: share.Protection == repo.ShareProtectionPIN,
+	}
+
+	if page.IsPIN {
+		page.Label = "PIN"
+	}
+
+	if share.IsLocked() {
+		renderUnlockPage(w, http.StatusForbidden, unlockPage{
+			Error: lockedShareMessage,
+		})
+
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		renderUnlockPage(w, http.StatusOK, page)
+		return
+	}
+
+	ip := audit.ClientIP(r)
+
+	// reserve the attempts before verifying the secret so parallel
+	// requests cannot exceed the limits.
+	if !shp.shareLimiter.reserve(token) {
+		page.Error = "Too many failed attempts, please try again later."
+		renderUnlockPage(w, http.StatusTooManyRequests, page)
+
+		return
+	}
+
+	if !shp.clientLimiter.reserve(ip) {
+		shp.shareLimiter.release(token)
+
+		page.Error = "Too many failed attempts, please try again later."
+		renderUnlockPage(w, http.StatusTooManyRequests, page)
+
+		return
+	}
+
+	// the total number of failed attempts is tracked on the share itself
+	// so it survives restarts and the in-memory lockouts.
+	if _, err := shp.store.ReserveStudyShareUnlock(r.Context(), token); err != nil {
+		shp.shareLimiter.release(token)
+		shp.clientLimiter.release(ip)
+
+		if errors.Is(err, repo.ErrShareLocked) {
+			// drop the cached share so the lock is visible immediately
+			shp.InvalidateToken(token)
+
+			renderUnlockPage(w, http.StatusForbidden, unlockPage{
+				Error: lockedShareMessage,
+			})
+
+			return
+		}
+
+		slog.Error("failed to reserve study share unlock attempt", "token", token, "error", err)
+
+		page.Error = "Failed to verify the entered value, please try again later."
+		renderUnlockPage(w, http.StatusInternalServerError, page)
+
+		return
+	}
+
+	if !share.VerifySecret(secret) {
+		slog.Warn("failed attempt to unlock study share", "token", token, "ip", ip)
+
+		shp.InvalidateToken(token)
+
+		page.Error = "The entered value is not correct."
+		renderUnlockPage(w, http.StatusUnauthorized, page)
+
+		return
+	}
+
+	shp.shareLimiter.reset(token)
+	shp.clientLimiter.release(ip)
+
+	if err := shp.store.ReleaseStudyShareUnlock(r.Context(), token); err != nil {
+		slog.Error("failed to release study share unlock attempt", "token", token, "error", err)
+	}
+
+	expires := time.Now().Add(shareSessionTTL)
+	if !share.ExpiresAt.IsZero() && share.ExpiresAt.Before(expires) {
+		expires = share.ExpiresAt
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName(token),
+		Value:    shp.signSession(token, expires),
+		Path:     urlutils.SingleJoiningSlash(shp.PublicURL.Path, shp.Subdir),
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   shp.PublicURL.Scheme == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, ViewerURL(shp.PublicURL.String(), *share), http.StatusSeeOther)
+}
+
+func renderUnlockPage(w http.ResponseWriter, status int, page unlockPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	if err := unlockTemplate.Execute(w, page); err != nil {
+		slog.Error("failed to render unlock page", "error", err)
+	}
+}
+
+// lookupShare returns the valid study share for token.
+func (shp *SingelHostProxy) lookupShare(ctx context.Context, token string) (*repo.StudyShare, bool) {
+	if !strings.HasPrefix(token, repo.ShareTokenPrefix) {
+		return nil, false
+	}
+
+	res, ok := shp.isValidToken(token)
+	if !ok {
+		res, ok = shp.validateToken(ctx, token)
+	}
+
+	if !ok || res.studShare == nil {
+		return nil, false
+	}
+
+	return res.studShare, true
+}
+
+// hasSession reports whether r carries a valid session cookie for the
+// share token.
+func (shp *SingelHostProxy) hasSession(r *http.Request, token string) bool {
+	c, err := r.Cookie(sessionCookieName(token))
+	if err != nil {
+		return false
+	}
+
+	exp, _, ok := strings.Cut(c.Value, ".")
+	if !ok {
+		return false
+	}
+
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	expires := time.Unix(unix, 0)
+	if time.Now().After(expires) {
+		return false
+	}
+
+	return hmac.Equal([]byte(c.Value), []byte(shp.signSession(token, expires)))
+}
+
+// signSession returns a session cookie value that binds token to the
+// expiration time.
+func (shp *SingelHostProxy) signSession(token string, expires time.Time) string {
+	mac := hmac.New(sha256.New, shp.sessionKey)
+	fmt.Fprintf(mac, "%s|%d", token, expires.Unix())
+
+	return fmt.Sprintf("%d.%s", expires.Unix(), base64.RawURLEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+// sessionCookieName returns a cookie name per share token so multiple
+// shares can be unlocked in the same browser.
+func sessionCookieName(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return shareSessionCookiePrefix + hex.EncodeToString(sum[:6])
+}
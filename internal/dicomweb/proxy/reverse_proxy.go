@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,15 +13,16 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
-	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/bufbuild/connect-go"
-	"github.com/sirupsen/logrus"
 	idmv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/idm/v1"
 	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/idm/v1/idmv1connect"
+	"github.com/tierklinik-dobersberg/apis/pkg/auth"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/audit"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/config"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dicomweb"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
@@ -30,23 +32,30 @@ import (
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
+// qidoMatcher matches paths relative to the DICOMweb prefix.
 var qidoMatcher = []urlpath.Path{
-	urlpath.New("dicom-web/studies/:study/series/:series/instances/:instance"),
-	urlpath.New("dicom-web/studies/:study/series/:series/instances/:instance/*"),
-	urlpath.New("dicom-web/studies/:study/series/:series"),
-	urlpath.New("dicom-web/studies/:study/series/:series/*"),
-	urlpath.New("dicom-web/studies/:study"),
-	urlpath.New("dicom-web/studies/:study/*"),
-	urlpath.New("dicom-web/studies"),
+	urlpath.New("studies/:study/series/:series/instances/:instance"),
+	urlpath.New("studies/:study/series/:series/instances/:instance/*"),
+	urlpath.New("studies/:study/series/:series"),
+	urlpath.New("studies/:study/series/:series/*"),
+	urlpath.New("studies/:study"),
+	urlpath.New("studies/:study/*"),
+	urlpath.New("studies"),
 }
 
 type Storage interface {
 	GetStudyShare(ctx context.Context, token string) (*repo.StudyShare, error)
+	StartStudyShareSession(ctx context.Context, token string) (*repo.StudyShare, error)
+	RecordShareAccess(ctx context.Context, access repo.ShareAccess) error
+	ReserveStudyShareUnlock(ctx context.Context, token string) (*repo.StudyShare, error)
+	ReleaseStudyShareUnlock(ctx context.Context, token string) error
 }
 
 type resolvedAccessToken struct {
 	validUntil    time.Time
 	isUserAccount bool
+	userID        string
+	roleIDs       []string
 	studShare     *repo.StudyShare
 }
 
@@ -57,27 +66,58 @@ type SingelHostProxy struct {
 	Subdir    string
 	PublicURL *url.URL
 
-	userClient idmv1connect.AuthServiceClient
+	// IsDefault is true if the proxy serves the default orthanc instance.
+	IsDefault bool
+
+	userClient   idmv1connect.AuthServiceClient
+	roleResolver auth.RoleResolverFunc
 
 	config.OrthancInstance
 
 	once  *singleflight.Group
 	store Storage
+	audit *audit.Logger
 
 	rw          sync.RWMutex
 	validTokens map[string]resolvedAccessToken
+
+	// roles caches role names by ID.
+	roles map[string]string
+
+	// sessionKey signs the session cookies of unlocked shares.
+	sessionKey    []byte
+	shareLimiter  *attemptLimiter
+	clientLimiter *attemptLimiter
+	sessions      *viewerSessions
 }
 
-func New(name string, storage Storage, subdir string, publicURL *url.URL, cfg config.OrthancInstance, userClient idmv1connect.AuthServiceClient) (*SingelHostProxy, error) {
+func New(name string, isDefault bool, storage Storage, subdir string, publicURL *url.URL, cfg config.OrthancInstance, userClient idmv1connect.AuthServiceClient, roleResolver auth.RoleResolverFunc, auditLog *audit.Logger) (*SingelHostProxy, error) {
+	if err := validatePolicies(cfg.Policies); err != nil {
+		return nil, err
+	}
+
+	sessionKey := make([]byte, 32)
+	if _, err := rand.Read(sessionKey); err != nil {
+		return nil, fmt.Errorf("failed to generate session key: %w", err)
+	}
+
 	i := &SingelHostProxy{
 		Name:            name,
+		IsDefault:       isDefault,
 		Subdir:          subdir,
 		PublicURL:       publicURL,
 		OrthancInstance: cfg,
 		userClient:      userClient,
+		roleResolver:    roleResolver,
 		validTokens:     make(map[string]resolvedAccessToken),
+		roles:           make(map[string]string),
 		once:            new(singleflight.Group),
 		store:           storage,
+		audit:           auditLog,
+		sessionKey:      sessionKey,
+		shareLimiter:    newAttemptLimiter(maxUnlockAttempts, unlockLockout),
+		clientLimiter:   newAttemptLimiter(maxClientAttempts, unlockLockout),
+		sessions:        newViewerSessions(shareSessionTTL),
 	}
 
 	proxy, err := i.buildProxy()
@@ -91,6 +131,11 @@ func New(name string, storage Storage, subdir string, publicURL *url.URL, cfg co
 }
 
 func (shp *SingelHostProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.TrimPrefix(r.URL.Path, "/") == unlockPath {
+		shp.serveUnlock(w, r)
+		return
+	}
+
 	var resolved *resolvedAccessToken
 
 	if token := getToken(r); token != "" {
@@ -110,62 +155,92 @@ func (shp *SingelHostProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// record the request in the audit trail once it has been served
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	w = sw
+
+	defer func() {
+		shp.auditRequest(r, *resolved, sw.status)
+	}()
+
+	// protected shares require a session created by unlocking the share
+	if resolved.studShare != nil && resolved.studShare.IsProtected() && !shp.hasSession(r, resolved.studShare.Token) {
+		http.Error(w, "this study share must be unlocked first", http.StatusUnauthorized)
+		return
+	}
+
+	if !shp.allowedByPolicy(r.Context(), r, *resolved) {
+		http.Error(w, "you are not allowed to perform this request", http.StatusForbidden)
+		return
+	}
+
 	// for a share-token, ensure the user is actually allowed to perform the request
-	if resolved.studShare != nil {
-		match, isQido := isQidoUrl(r.URL.Path)
+	if resolved.studShare != nil && !shp.allowedByShare(w, r, resolved.studShare) {
+		return
+	}
 
-		attr := []slog.Attr{
-			slog.String("path", r.URL.Path),
-			slog.Bool("isQidoRS", isQido),
-		}
+	if resolved.studShare != nil && !shp.trackShareAccess(w, r, resolved.studShare) {
+		return
+	}
 
-		for key, val := range match.Params {
-			attr = append(attr, slog.String(key, val))
-		}
+	r = r.WithContext(
+		context.WithValue(r.Context(), proxyContextKey, *resolved),
+	)
 
-		slog.LogAttrs(r.Context(), slog.LevelInfo, "validating dicomweb request", attr...)
+	shp.proxy.ServeHTTP(w, r)
+}
 
-		if isQido {
+// allowedByShare ensures a request using a share token only accesses the
+// shared study and the series and instances allowed by its scope. Requests
+// outside of the DICOMweb API are always denied. If the request is denied,
+// an error is written to w.
+func (shp *SingelHostProxy) allowedByShare(w http.ResponseWriter, r *http.Request, share *repo.StudyShare) bool {
+	if !shp.isDicomWebPath(r.URL.Path) {
+		http.Error(w, "you are not allowed to access this resource: "+r.URL.Path, http.StatusForbidden)
+		return false
+	}
 
-			study, ok := match.Params["study"]
-			if !ok {
-				// check for StudyInstanceUIDs
-				study = r.URL.Query().Get("StudyInstanceUID")
-				if study == "" {
-					study = r.URL.Query().Get(dicomweb.StudyInstanceUID) // try using the tag value
-				}
+	match, isQido := shp.isQidoUrl(r.URL.Path)
 
-				if study == "" {
-					// this is a study list request which is never allowed for share tokens
-					http.Error(w, "you are not allowed to list studies", http.StatusUnauthorized)
-					return
-				}
-			}
+	attr := []slog.Attr{
+		slog.String("path", r.URL.Path),
+		slog.Bool("isQidoRS", isQido),
+	}
 
-			slog.Info("validating access to study", "study", study, "allowed", resolved.studShare.StudyUID)
-			if resolved.studShare.StudyUID != study {
-				http.Error(w, "you are not allowed to access this study: "+study, http.StatusUnauthorized)
-				return
-			}
+	for key, val := range match.Params {
+		attr = append(attr, slog.String(key, val))
+	}
 
-			instance, ok := match.Params["instance"]
-			if ok && len(resolved.studShare.InstanceUIDs) > 0 {
-				slog.Info("checking if access to study instance is allowed", "instance", instance)
+	slog.LogAttrs(r.Context(), slog.LevelInfo, "validating dicomweb request", attr...)
 
-				// check if the user has access to the requested instance
-				if !slices.Contains(resolved.studShare.InstanceUIDs, instance) {
-					http.Error(w, "you are not allowed to access this study instance: "+instance, http.StatusUnauthorized)
-					return
-				}
-			}
+	study, ok := match.Params["study"]
+	if !ok {
+		// check for StudyInstanceUIDs
+		study = r.URL.Query().Get("StudyInstanceUID")
+		if study == "" {
+			study = r.URL.Query().Get(dicomweb.StudyInstanceUID) // try using the tag value
+		}
+
+		if study == "" {
+			// this is a study list request which is never allowed for share tokens
+			http.Error(w, "you are not allowed to list studies", http.StatusUnauthorized)
+			return false
 		}
 	}
 
-	r = r.WithContext(
-		context.WithValue(r.Context(), proxyContextKey, *resolved),
-	)
+	slog.Info("validating access to study", "study", study, "allowed", share.StudyUID)
+	if share.StudyUID != study {
+		http.Error(w, "you are not allowed to access this study: "+study, http.StatusUnauthorized)
+		return false
+	}
 
-	shp.proxy.ServeHTTP(w, r)
+	// check if the user has access to the requested series or instance
+	if !allowedByScope(share, match) {
+		http.Error(w, "you are not allowed to access this resource: "+r.URL.Path, http.StatusUnauthorized)
+		return false
+	}
+
+	return true
 }
 
 func rewriteRequestURL(req *http.Request, target *url.URL) {
@@ -181,8 +256,11 @@ func rewriteRequestURL(req *http.Request, target *url.URL) {
 	}
 }
 
-func isQidoUrl(path string) (urlpath.Match, bool) {
-	path = strings.TrimPrefix(path, "/")
+func (shp *SingelHostProxy) isQidoUrl(path string) (urlpath.Match, bool) {
+	path, ok := strings.CutPrefix(strings.TrimPrefix(path, "/"), shp.dicomWebPrefix())
+	if !ok {
+		return urlpath.Match{}, false
+	}
 
 	for _, p := range qidoMatcher {
 		res, match := p.Match(path)
@@ -244,7 +322,7 @@ func (p *SingelHostProxy) buildProxy() (*httputil.ReverseProxy, error) {
 				return nil
 			}
 
-			match, isQuido := isQidoUrl(r.Request.URL.Path)
+			match, isQuido := p.isQidoUrl(r.Request.URL.Path)
 
 			slog.Info("checking response body", "contentType", contentType, "isQidoRS", isQuido, "path", r.Request.URL.Path)
 
@@ -305,7 +383,13 @@ func (p *SingelHostProxy) rewriteQidoBody(r *http.Response, token resolvedAccess
 
 	var qido []dicomweb.QIDOResponse
 	if err := json.Unmarshal(blob, &qido); err != nil {
-		logrus.Fatalf("failed to deocde qido reponse: %s", err)
+		// error responses and orthanc REST objects are not QIDO-RS
+		// results and are passed through unchanged.
+		slog.Debug("response body is not a QIDO-RS result, not rewriting", "path", r.Request.URL.Path, "error", err)
+
+		r.Body = io.NopCloser(bytes.NewReader(blob))
+		r.Header.Del("Content-Encoding")
+		r.Header.Set("Content-Length", strconv.Itoa(len(blob)))
 
 		return nil
 	}
@@ -314,9 +398,6 @@ func (p *SingelHostProxy) rewriteQidoBody(r *http.Response, token resolvedAccess
 	count := 0
 	copy := make([]dicomweb.QIDOResponse, 0, len(qido))
 
-	hasInstances := false
-	instanceCount := 0
-
 	for _, s := range qido {
 
 		if token.studShare != nil {
@@ -332,34 +413,14 @@ func (p *SingelHostProxy) rewriteQidoBody(r *http.Response, token resolvedAccess
 				}
 			}
 
-			// Filtering instances breaks OHIF since it expects at least one instance
-			// per series.
-			// We could fix this by checking which series are actually allowed (contains accessible instances).
-			// for now, this solution is good enough.
-
-			/*
-
-				// validate access to the instances
-				if len(token.studShare.InstanceUIDs) > 0 {
-
-					var instanceUid string
-					val, ok := s[dicomweb.SOPInstanceUID]
-					if ok && len(val.Value) > 0 {
-						hasInstances = true
-
-						instanceUid, ok = val.Value[0].(string)
-					}
-
-					if ok && !slices.Contains(token.studShare.InstanceUIDs, instanceUid) {
-						slog.Info("filtered SOPInstanceUID since it's not allowed by the share token", "uid", instanceUid)
-
-						continue
-					} else {
-						instanceCount++
-					}
-				}
+			// remove series and instances that are not shared. Series
+			// without any shared instance are removed completely since
+			// OHIF expects at least one instance per series.
+			if !allowedObject(token.studShare, s) {
+				slog.Info("removing object from response since access is not allowed by the share token")
 
-			*/
+				continue
+			}
 		}
 
 		if retrieveURI, ok := s[dicomweb.RetrieveURI]; ok {
@@ -397,10 +458,6 @@ func (p *SingelHostProxy) rewriteQidoBody(r *http.Response, token resolvedAccess
 		copy = append(copy, s)
 	}
 
-	if hasInstances && instanceCount == 0 {
-		return fmt.Errorf("no instances left")
-	}
-
 	// re-create the response body
 	blobBuf := bytes.NewBuffer([]byte{})
 	enc := json.NewEncoder(blobBuf)
@@ -491,6 +548,12 @@ func (shp *SingelHostProxy) validateToken(ctx context.Context, token string) (re
 			return resolvedAccessToken{}, false
 		}
 
+		// shares created before instance support do not have an instance
+		// set and are only valid for the default instance.
+		if instance := share.Instance; instance != shp.Name && (instance != "" || !shp.IsDefault) {
+			return resolvedAccessToken{}, false
+		}
+
 		r := resolvedAccessToken{
 			validUntil: share.ExpiresAt,
 			studShare:  share,
@@ -504,7 +567,7 @@ func (shp *SingelHostProxy) validateToken(ctx context.Context, token string) (re
 	resolved, _, _ := shp.once.Do(token, func() (interface{}, error) {
 		req := connect.NewRequest(&idmv1.IntrospectRequest{
 			ReadMask: &fieldmaskpb.FieldMask{
-				Paths: []string{"user.id", "user.username", "user.display_name", "valid_time"},
+				Paths: []string{"user.id", "user.username", "user.display_name", "roles", "valid_time"},
 			},
 		})
 
@@ -515,6 +578,11 @@ func (shp *SingelHostProxy) validateToken(ctx context.Context, token string) (re
 		if err == nil {
 			resolved := resolvedAccessToken{
 				isUserAccount: true,
+				userID:        res.Msg.GetProfile().GetUser().GetId(),
+			}
+
+			for _, role := range res.Msg.GetProfile().GetRoles() {
+				resolved.roleIDs = append(resolved.roleIDs, role.GetId())
 			}
 
 			if res.Msg.ValidTime.IsValid() {
@@ -566,6 +634,16 @@ func (shp *SingelHostProxy) cacheToken(token string, resolved resolvedAccessToke
 	shp.validTokens[token] = resolved
 }
 
+// InvalidateToken removes token from the cache of validated tokens so the
+// next request using token is validated again. It must be called whenever a
+// share is revoked or its expiration time changes.
+func (shp *SingelHostProxy) InvalidateToken(token string) {
+	shp.rw.Lock()
+	defer shp.rw.Unlock()
+
+	delete(shp.validTokens, token)
+}
+
 func getToken(r *http.Request) string {
 	if h := r.Header.Get("Authorization"); strings.HasPrefix(strings.ToLower(h), "bearer ") {
 		return strings.TrimSpace(h[7:])
@@ -0,0 +1,73 @@
+package proxy
+
+import (
+	"strings"
+
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dicomweb"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+	"github.com/ucarion/urlpath"
+)
+
+// allowedByScope reports whether the DICOMweb request described by match
+// only accesses series and instances allowed by share. JSON responses
+// (QIDO-RS and WADO-RS metadata) are filtered by rewriteQidoBody so only
+// requests that return complete series or studies need to be denied here.
+func allowedByScope(share *repo.StudyShare, match urlpath.Match) bool {
+	if !share.IsScoped() {
+		return true
+	}
+
+	// study searches are filtered by rewriteQidoBody
+	if _, ok := match.Params["study"]; !ok {
+		return true
+	}
+
+	series, hasSeries := match.Params["series"]
+	instance, hasInstance := match.Params["instance"]
+
+	// the first trailing segment selects the resource of a study or series
+	resource, _, _ := strings.Cut(match.Trailing, "/")
+
+	switch {
+	case hasInstance:
+		return share.AllowsInstance(series, instance)
+
+	case hasSeries:
+		if !share.AllowsSeries(series) {
+			return false
+		}
+
+		switch resource {
+		case "metadata", "instances":
+			return true
+		default:
+			// retrieving or rendering the series would include instances
+			// that are not shared.
+			return share.AllowsCompleteSeries(series)
+		}
+
+	default:
+		switch resource {
+		case "metadata", "series", "instances":
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// allowedObject reports whether a QIDO-RS or metadata result object may be
+// returned for share.
+func allowedObject(share *repo.StudyShare, obj dicomweb.QIDOResponse) bool {
+	series, _ := obj.GetAsString(dicomweb.SeriesInstanceUID)
+
+	if instance, _ := obj.GetAsString(dicomweb.SOPInstanceUID); instance != "" {
+		return share.AllowsInstance(series, instance)
+	}
+
+	if series != "" {
+		return share.AllowsSeries(series)
+	}
+
+	return true
+}
@@ -0,0 +1,159 @@
+package dimse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// DIMSE command fields as defined in DICOM PS3.7 section E.1.
+const (
+	commandCFindRQ  uint16 = 0x0020
+	commandCFindRSP uint16 = 0x8020
+	commandCEchoRQ  uint16 = 0x0030
+	commandCEchoRSP uint16 = 0x8030
+	commandCancelRQ uint16 = 0x0FFF
+)
+
+// Status codes used in DIMSE responses.
+const (
+	statusSuccess                uint16 = 0x0000
+	statusPending                uint16 = 0xFF00
+	statusSOPClassNotSupported   uint16 = 0x0122
+	statusIdentifierDoesNotMatch uint16 = 0xA900
+	statusUnableToProcess        uint16 = 0xC000
+)
+
+// dataSetAbsent is used as CommandDataSetType if no data set follows the
+// command.
+const dataSetAbsent uint16 = 0x0101
+
+// Elements of the command group (0000).
+const (
+	elemGroupLength           uint16 = 0x0000
+	elemAffectedSOPClassUID   uint16 = 0x0002
+	elemCommandField          uint16 = 0x0100
+	elemMessageID             uint16 = 0x0110
+	elemMessageIDRespondingTo uint16 = 0x0120
+	elemCommandDataSetType    uint16 = 0x0800
+	elemStatus                uint16 = 0x0900
+)
+
+// command holds the fields of a DIMSE command set that are used by the
+// server.
+type command struct {
+	affectedSOPClass     string
+	commandField         uint16
+	messageID            uint16
+	messageIDRespondedTo uint16
+	dataSetType          uint16
+	status               uint16
+}
+
+func (c command) hasDataSet() bool {
+	return c.dataSetType != dataSetAbsent
+}
+
+// decodeCommand decodes a command set which is always encoded using the
+// Implicit VR Little Endian transfer syntax.
+func decodeCommand(data []byte) (command, error) {
+	cmd := command{
+		dataSetType: dataSetAbsent,
+	}
+
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return cmd, fmt.Errorf("command element header too short")
+		}
+
+		group := binary.LittleEndian.Uint16(data[0:2])
+		element := binary.LittleEndian.Uint16(data[2:4])
+		length := int(binary.LittleEndian.Uint32(data[4:8]))
+
+		if len(data) < 8+length {
+			return cmd, fmt.Errorf("command element (%04x,%04x) exceeds command set", group, element)
+		}
+
+		value := data[8 : 8+length]
+		data = data[8+length:]
+
+		if group != 0x0000 {
+			continue
+		}
+
+		switch element {
+		case elemAffectedSOPClassUID:
+			cmd.affectedSOPClass = trimUID(value)
+		case elemCommandField:
+			cmd.commandField = uint16Value(value)
+		case elemMessageID:
+			cmd.messageID = uint16Value(value)
+		case elemMessageIDRespondingTo:
+			cmd.messageIDRespondedTo = uint16Value(value)
+		case elemCommandDataSetType:
+			cmd.dataSetType = uint16Value(value)
+		case elemStatus:
+			cmd.status = uint16Value(value)
+		}
+	}
+
+	return cmd, nil
+}
+
+// encodeResponse encodes a response command set.
+func (c command) encodeResponse() []byte {
+	body := new(bytes.Buffer)
+
+	if c.affectedSOPClass != "" {
+		uid := c.affectedSOPClass
+		if len(uid)%2 != 0 {
+			uid += "\x00"
+		}
+
+		writeCommandElement(body, elemAffectedSOPClassUID, []byte(uid))
+	}
+
+	writeCommandElement(body, elemCommandField, binary.LittleEndian.AppendUint16(nil, c.commandField))
+	writeCommandElement(body, elemMessageIDRespondingTo, binary.LittleEndian.AppendUint16(nil, c.messageIDRespondedTo))
+	writeCommandElement(body, elemCommandDataSetType, binary.LittleEndian.AppendUint16(nil, c.dataSetType))
+	writeCommandElement(body, elemStatus, binary.LittleEndian.AppendUint16(nil, c.status))
+
+	buf := new(bytes.Buffer)
+	writeCommandElement(buf, elemGroupLength, binary.LittleEndian.AppendUint32(nil, uint32(body.Len())))
+	buf.Write(body.Bytes())
+
+	return buf.Bytes()
+}
+
+func (c command) String() string {
+	var name string
+
+	switch c.commandField {
+	case commandCEchoRQ:
+		name = "C-ECHO-RQ"
+	case commandCFindRQ:
+		name = "C-FIND-RQ"
+	case commandCancelRQ:
+		name = "C-CANCEL-RQ"
+	default:
+		name = fmt.Sprintf("0x%04x", c.commandField)
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("%s %s", name, c.affectedSOPClass))
+}
+
+func writeCommandElement(buf *bytes.Buffer, element uint16, value []byte) {
+	buf.Write(binary.LittleEndian.AppendUint16(nil, 0x0000))
+	buf.Write(binary.LittleEndian.AppendUint16(nil, element))
+	buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(value))))
+	buf.Write(value)
+}
+
+func uint16Value(b []byte) uint16 {
+	if len(b) < 2 {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint16(b)
+}
@@ -0,0 +1,125 @@
+package dimse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/suyashkumar/dicom/pkg/uid"
+)
+
+func TestDecodeCommand(t *testing.T) {
+	element := func(buf *bytes.Buffer, group, element uint16, value []byte) {
+		buf.Write(binary.LittleEndian.AppendUint16(nil, group))
+		buf.Write(binary.LittleEndian.AppendUint16(nil, element))
+		buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(value))))
+		buf.Write(value)
+	}
+
+	u16 := func(v uint16) []byte {
+		return binary.LittleEndian.AppendUint16(nil, v)
+	}
+
+	findRQ := new(bytes.Buffer)
+	element(findRQ, 0x0000, elemGroupLength, binary.LittleEndian.AppendUint32(nil, 0))
+	element(findRQ, 0x0000, elemAffectedSOPClassUID, []byte("1.2.840.10008.5.1.4.31\x00"))
+	element(findRQ, 0x0000, elemCommandField, u16(commandCFindRQ))
+	element(findRQ, 0x0000, elemMessageID, u16(7))
+	element(findRQ, 0x0000, elemCommandDataSetType, u16(0x0000))
+	// elements of other groups are ignored
+	element(findRQ, 0x0008, 0x0005, []byte("ISO_IR 100"))
+
+	echoRQ := new(bytes.Buffer)
+	element(echoRQ, 0x0000, elemCommandField, u16(commandCEchoRQ))
+	element(echoRQ, 0x0000, elemMessageID, u16(1))
+
+	cases := []struct {
+		name     string
+		data     []byte
+		expected command
+		err      bool
+	}{
+		{
+			name: "C-FIND-RQ",
+			data: findRQ.Bytes(),
+			expected: command{
+				affectedSOPClass: "1.2.840.10008.5.1.4.31",
+				commandField:     commandCFindRQ,
+				messageID:        7,
+				dataSetType:      0x0000,
+			},
+		},
+		{
+			name: "C-ECHO-RQ without data set type",
+			data: echoRQ.Bytes(),
+			expected: command{
+				commandField: commandCEchoRQ,
+				messageID:    1,
+				dataSetType:  dataSetAbsent,
+			},
+		},
+		{
+			name: "element header too short",
+			data: findRQ.Bytes()[:4],
+			err:  true,
+		},
+		{
+			name: "element exceeds command set",
+			data: findRQ.Bytes()[:20],
+			err:  true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd, err := decodeCommand(c.data)
+
+			if c.err {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if cmd != c.expected {
+				t.Errorf("expected %+v, got %+v", c.expected, cmd)
+			}
+		})
+	}
+}
+
+func TestEncodeResponse(t *testing.T) {
+	rsp := command{
+		affectedSOPClass:     uid.VerificationSOPClass,
+		commandField:         commandCEchoRSP,
+		messageIDRespondedTo: 42,
+		dataSetType:          dataSetAbsent,
+		status:               statusSuccess,
+	}
+
+	data := rsp.encodeResponse()
+
+	// the group length must cover all following elements
+	groupLength := binary.LittleEndian.Uint32(data[8:12])
+	if int(groupLength) != len(data)-12 {
+		t.Errorf("expected group length %d, got %d", len(data)-12, groupLength)
+	}
+
+	decoded, err := decodeCommand(data)
+	if err != nil {
+		t.Fatalf("failed to decode response: %s", err)
+	}
+
+	if decoded != rsp {
+		t.Errorf("expected %+v, got %+v", rsp, decoded)
+	}
+
+	if decoded.hasDataSet() {
+		t.Errorf("expected response without data set")
+	}
+}
@@ -0,0 +1,87 @@
+package dimse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/tag"
+	"github.com/suyashkumar/dicom/pkg/uid"
+)
+
+// supportedTransferSyntaxes lists the transfer syntaxes accepted for data
+// sets in the order of preference.
+var supportedTransferSyntaxes = []string{
+	uid.ExplicitVRLittleEndian,
+	uid.ImplicitVRLittleEndian,
+}
+
+// decodeDataSet decodes a data set that has been received using the given
+// transfer syntax.
+func decodeDataSet(data []byte, transferSyntax string) (dicom.Dataset, error) {
+	// The parser of the dicom package expects a DICOM file including the
+	// file meta information. Instead of guessing the transfer syntax a
+	// minimal meta header is prepended to the data set.
+	header := new(bytes.Buffer)
+	header.Write(make([]byte, 128))
+	header.WriteString("DICM")
+
+	ts := transferSyntax
+	if len(ts)%2 != 0 {
+		ts += "\x00"
+	}
+
+	tsElement := new(bytes.Buffer)
+	tsElement.Write([]byte{0x02, 0x00, 0x10, 0x00, 'U', 'I'})
+	tsElement.Write(binary.LittleEndian.AppendUint16(nil, uint16(len(ts))))
+	tsElement.WriteString(ts)
+
+	header.Write([]byte{0x02, 0x00, 0x00, 0x00, 'U', 'L', 0x04, 0x00})
+	header.Write(binary.LittleEndian.AppendUint32(nil, uint32(tsElement.Len())))
+	header.Write(tsElement.Bytes())
+
+	content := append(header.Bytes(), data...)
+
+	ds, err := dicom.Parse(bytes.NewReader(content), int64(len(content)), nil, dicom.SkipPixelData())
+	if err != nil {
+		return dicom.Dataset{}, fmt.Errorf("failed to parse data set: %w", err)
+	}
+
+	ds.Elements = slices.DeleteFunc(ds.Elements, func(el *dicom.Element) bool {
+		return el.Tag.Group == tag.MetadataGroup
+	})
+
+	return ds, nil
+}
+
+// encodeDataSet encodes ds using the given transfer syntax. Elements of the
+// file meta information group are skipped.
+func encodeDataSet(ds dicom.Dataset, transferSyntax string) ([]byte, error) {
+	bo, implicit, err := uid.ParseTransferSyntaxUID(transferSyntax)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+
+	w, err := dicom.NewWriter(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	w.SetTransferSyntax(bo, implicit)
+
+	for _, el := range ds.Elements {
+		if el.Tag.Group == tag.MetadataGroup {
+			continue
+		}
+
+		if err := w.WriteElement(el); err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", el.Tag, err)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,283 @@
+package dimse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PDU types as defined in DICOM PS3.8 section 9.3.
+const (
+	pduAssociateRQ byte = 0x01
+	pduAssociateAC byte = 0x02
+	pduAssociateRJ byte = 0x03
+	pduDataTF      byte = 0x04
+	pduReleaseRQ   byte = 0x05
+	pduReleaseRP   byte = 0x06
+	pduAbort       byte = 0x07
+)
+
+// Item types used in A-ASSOCIATE-RQ and A-ASSOCIATE-AC PDUs.
+const (
+	itemApplicationContext byte = 0x10
+	itemPresentationRQ     byte = 0x20
+	itemPresentationAC     byte = 0x21
+	itemAbstractSyntax     byte = 0x30
+	itemTransferSyntax     byte = 0x40
+	itemUserInformation    byte = 0x50
+	itemMaxLength          byte = 0x51
+	itemImplementationUID  byte = 0x52
+	itemImplementationName byte = 0x55
+)
+
+const applicationContextName = "1.2.840.10008.3.1.1.1"
+
+// ImplementationClassUID identifies the orthanc-bridge in associations and
+// in the meta information of DICOM files it creates. It is a UUID derived
+// UID as defined in PS3.5 B.2.
+const (
+	ImplementationClassUID    = "2.25.71222178296234573774269424519533454031"
+	ImplementationVersionName = "ORTHANC_BRIDGE"
+)
+
+// Results of a presentation context negotiation.
+const (
+	contextAccepted                  byte = 0
+	contextAbstractSyntaxUnsupported byte = 3
+	contextTransferSyntaxUnsupported byte = 4
+)
+
+// Reasons used in A-ASSOCIATE-RJ PDUs.
+const (
+	rejectNoReason                   byte = 1
+	rejectApplicationContextNotKnown byte = 2
+	rejectCalledAENotRecognized      byte = 7
+)
+
+// rejectLocalLimitExceeded is the reason used by the service-provider
+// (presentation) for transient rejections.
+const rejectLocalLimitExceeded byte = 2
+
+// maxPDULength is the maximum length of P-DATA-TF PDUs announced to peers.
+const maxPDULength = 64 * 1024
+
+// maxReadLength limits the size of PDUs accepted from peers that ignore
+// the announced maximum length.
+const maxReadLength = 16 * 1024 * 1024
+
+// maxMessageLength limits the size of the command and the data set of a
+// single DIMSE message assembled from multiple P-DATA-TF PDUs.
+const maxMessageLength = 16 * 1024 * 1024
+
+var (
+	errPDUTooLarge     = errors.New("PDU exceeds maximum length")
+	errMessageTooLarge = errors.New("DIMSE message exceeds maximum length")
+)
+
+type pdu struct {
+	typ  byte
+	data []byte
+}
+
+func readPDU(r io.Reader) (pdu, error) {
+	var header [6]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return pdu{}, err
+	}
+
+	length := binary.BigEndian.Uint32(header[2:])
+	if length > maxReadLength {
+		return pdu{}, errPDUTooLarge
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return pdu{}, err
+	}
+
+	return pdu{
+		typ:  header[0],
+		data: data,
+	}, nil
+}
+
+func writePDU(w io.Writer, typ byte, data []byte) error {
+	buf := make([]byte, 6, 6+len(data))
+	buf[0] = typ
+	binary.BigEndian.PutUint32(buf[2:], uint32(len(data)))
+
+	_, err := w.Write(append(buf, data...))
+
+	return err
+}
+
+// presentationContext is a presentation context proposed by the peer.
+type presentationContext struct {
+	id               byte
+	abstractSyntax   string
+	transferSyntaxes []string
+}
+
+// associateRQ holds the relevant parts of an A-ASSOCIATE-RQ PDU.
+type associateRQ struct {
+	calledAE           string
+	callingAE          string
+	applicationContext string
+	contexts           []presentationContext
+	maxLength          uint32
+}
+
+func parseAssociateRQ(data []byte) (associateRQ, error) {
+	if len(data) < 68 {
+		return associateRQ{}, fmt.Errorf("A-ASSOCIATE-RQ too short")
+	}
+
+	rq := associateRQ{
+		calledAE:  strings.TrimSpace(string(data[4:20])),
+		callingAE: strings.TrimSpace(string(data[20:36])),
+	}
+
+	err := readItems(data[68:], func(typ byte, item []byte) error {
+		switch typ {
+		case itemApplicationContext:
+			rq.applicationContext = trimUID(item)
+
+		case itemPresentationRQ:
+			if len(item) < 4 {
+				return fmt.Errorf("presentation context item too short")
+			}
+
+			pc := presentationContext{
+				id: item[0],
+			}
+
+			err := readItems(item[4:], func(typ byte, sub []byte) error {
+				switch typ {
+				case itemAbstractSyntax:
+					pc.abstractSyntax = trimUID(sub)
+				case itemTransferSyntax:
+					pc.transferSyntaxes = append(pc.transferSyntaxes, trimUID(sub))
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			rq.contexts = append(rq.contexts, pc)
+
+		case itemUserInformation:
+			return readItems(item, func(typ byte, sub []byte) error {
+				if typ == itemMaxLength && len(sub) == 4 {
+					rq.maxLength = binary.BigEndian.Uint32(sub)
+				}
+
+				return nil
+			})
+		}
+
+		return nil
+	})
+
+	return rq, err
+}
+
+// acceptedContext is the result of negotiating a presentation context.
+type acceptedContext struct {
+	id             byte
+	result         byte
+	abstractSyntax string
+	transferSyntax string
+}
+
+func encodeAssociateAC(rq associateRQ, contexts []acceptedContext) []byte {
+	buf := new(bytes.Buffer)
+
+	// protocol version and reserved field
+	buf.Write([]byte{0x00, 0x01, 0x00, 0x00})
+	buf.WriteString(padAE(rq.calledAE))
+	buf.WriteString(padAE(rq.callingAE))
+	buf.Write(make([]byte, 32))
+
+	writeItem(buf, itemApplicationContext, []byte(applicationContextName))
+
+	for _, pc := range contexts {
+		item := new(bytes.Buffer)
+		item.Write([]byte{pc.id, 0x00, pc.result, 0x00})
+
+		// the transfer syntax sub-item is only significant for accepted
+		// contexts but must be present anyway.
+		writeItem(item, itemTransferSyntax, []byte(pc.transferSyntax))
+
+		writeItem(buf, itemPresentationAC, item.Bytes())
+	}
+
+	userInfo := new(bytes.Buffer)
+	writeItem(userInfo, itemMaxLength, binary.BigEndian.AppendUint32(nil, maxPDULength))
+	writeItem(userInfo, itemImplementationUID, []byte(ImplementationClassUID))
+	writeItem(userInfo, itemImplementationName, []byte(ImplementationVersionName))
+
+	writeItem(buf, itemUserInformation, userInfo.Bytes())
+
+	return buf.Bytes()
+}
+
+func encodeAssociateRJ(reason byte) []byte {
+	// reserved, result (rejected-permanent), source (service-user), reason
+	return []byte{0x00, 0x01, 0x01, reason}
+}
+
+func encodeAssociateRJTransient(reason byte) []byte {
+	// reserved, result (rejected-transient), source (service-provider
+	// presentation), reason
+	return []byte{0x00, 0x02, 0x03, reason}
+}
+
+func encodeAbort() []byte {
+	// reserved, reserved, source (service-user), reason (not specified)
+	return []byte{0x00, 0x00, 0x00, 0x00}
+}
+
+func readItems(data []byte, fn func(typ byte, item []byte) error) error {
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return fmt.Errorf("item header too short")
+		}
+
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		if len(data) < 4+length {
+			return fmt.Errorf("item of type 0x%02x exceeds PDU", data[0])
+		}
+
+		if err := fn(data[0], data[4:4+length]); err != nil {
+			return err
+		}
+
+		data = data[4+length:]
+	}
+
+	return nil
+}
+
+func writeItem(buf *bytes.Buffer, typ byte, data []byte) {
+	buf.WriteByte(typ)
+	buf.WriteByte(0x00)
+	buf.Write(binary.BigEndian.AppendUint16(nil, uint16(len(data))))
+	buf.Write(data)
+}
+
+func trimUID(b []byte) string {
+	return strings.TrimRight(string(b), "\x00 ")
+}
+
+func padAE(ae string) string {
+	if len(ae) > 16 {
+		ae = ae[:16]
+	}
+
+	return ae + strings.Repeat(" ", 16-len(ae))
+}
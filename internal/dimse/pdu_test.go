@@ -0,0 +1,226 @@
+package dimse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/suyashkumar/dicom/pkg/uid"
+)
+
+// encodeTestAssociateRQ builds an A-ASSOCIATE-RQ PDU body with the given items.
+func encodeTestAssociateRQ(calledAE, callingAE string, items func(buf *bytes.Buffer)) []byte {
+	buf := new(bytes.Buffer)
+
+	buf.Write([]byte{0x00, 0x01, 0x00, 0x00})
+	buf.WriteString(padAE(calledAE))
+	buf.WriteString(padAE(callingAE))
+	buf.Write(make([]byte, 32))
+
+	items(buf)
+
+	return buf.Bytes()
+}
+
+func presentationItem(id byte, abstractSyntax string, transferSyntaxes ...string) []byte {
+	item := new(bytes.Buffer)
+	item.Write([]byte{id, 0x00, 0x00, 0x00})
+
+	writeItem(item, itemAbstractSyntax, []byte(abstractSyntax+"\x00"))
+	for _, ts := range transferSyntaxes {
+		writeItem(item, itemTransferSyntax, []byte(ts))
+	}
+
+	return item.Bytes()
+}
+
+func TestParseAssociateRQ(t *testing.T) {
+	data := encodeTestAssociateRQ("BRIDGE", "MODALITY", func(buf *bytes.Buffer) {
+		writeItem(buf, itemApplicationContext, []byte(applicationContextName))
+		writeItem(buf, itemPresentationRQ, presentationItem(1, uid.VerificationSOPClass, uid.ImplicitVRLittleEndian))
+		writeItem(buf, itemPresentationRQ, presentationItem(3, "1.2.840.10008.5.1.4.31", uid.ExplicitVRLittleEndian, uid.ImplicitVRLittleEndian))
+
+		userInfo := new(bytes.Buffer)
+		writeItem(userInfo, itemMaxLength, binary.BigEndian.AppendUint32(nil, 16384))
+		writeItem(buf, itemUserInformation, userInfo.Bytes())
+	})
+
+	rq, err := parseAssociateRQ(data)
+	if err != nil {
+		t.Fatalf("failed to parse A-ASSOCIATE-RQ: %s", err)
+	}
+
+	if rq.calledAE != "BRIDGE" || rq.callingAE != "MODALITY" {
+		t.Errorf("unexpected AE titles %q and %q", rq.calledAE, rq.callingAE)
+	}
+
+	if rq.applicationContext != applicationContextName {
+		t.Errorf("unexpected application context %q", rq.applicationContext)
+	}
+
+	if rq.maxLength != 16384 {
+		t.Errorf("expected max length 16384, got %d", rq.maxLength)
+	}
+
+	if len(rq.contexts) != 2 {
+		t.Fatalf("expected 2 presentation contexts, got %d", len(rq.contexts))
+	}
+
+	pc := rq.contexts[1]
+	if pc.id != 3 || pc.abstractSyntax != "1.2.840.10008.5.1.4.31" || len(pc.transferSyntaxes) != 2 {
+		t.Errorf("unexpected presentation context %+v", pc)
+	}
+}
+
+func TestParseAssociateRQErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "too short",
+			data: make([]byte, 10),
+		},
+		{
+			name: "truncated item header",
+			data: encodeTestAssociateRQ("BRIDGE", "MODALITY", func(buf *bytes.Buffer) {
+				buf.Write([]byte{itemApplicationContext, 0x00})
+			}),
+		},
+		{
+			name: "item exceeds PDU",
+			data: encodeTestAssociateRQ("BRIDGE", "MODALITY", func(buf *bytes.Buffer) {
+				buf.Write([]byte{itemApplicationContext, 0x00, 0x00, 0xff, '1'})
+			}),
+		},
+		{
+			name: "presentation context too short",
+			data: encodeTestAssociateRQ("BRIDGE", "MODALITY", func(buf *bytes.Buffer) {
+				writeItem(buf, itemPresentationRQ, []byte{0x01})
+			}),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := parseAssociateRQ(c.data); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestReadPDU(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := writePDU(buf, pduDataTF, []byte("payload")); err != nil {
+		t.Fatalf("failed to write PDU: %s", err)
+	}
+
+	p, err := readPDU(buf)
+	if err != nil {
+		t.Fatalf("failed to read PDU: %s", err)
+	}
+
+	if p.typ != pduDataTF || string(p.data) != "payload" {
+		t.Errorf("unexpected PDU %+v", p)
+	}
+
+	header := []byte{pduDataTF, 0x00}
+	header = binary.BigEndian.AppendUint32(header, maxReadLength+1)
+
+	if _, err := readPDU(bytes.NewReader(header)); !errors.Is(err, errPDUTooLarge) {
+		t.Errorf("expected errPDUTooLarge, got %v", err)
+	}
+
+	if _, err := readPDU(bytes.NewReader(header[:3])); err == nil {
+		t.Errorf("expected an error for a truncated header")
+	}
+}
+
+func TestReadPDVs(t *testing.T) {
+	pdv := func(contextID byte, control byte, value string) []byte {
+		item := binary.BigEndian.AppendUint32(nil, uint32(len(value)+2))
+		item = append(item, contextID, control)
+
+		return append(item, value...)
+	}
+
+	type value struct {
+		contextID byte
+		control   byte
+		value     string
+	}
+
+	cases := []struct {
+		name     string
+		data     []byte
+		expected []value
+		err      bool
+	}{
+		{
+			name: "single item",
+			data: pdv(1, 0x03, "command"),
+			expected: []value{
+				{1, 0x03, "command"},
+			},
+		},
+		{
+			name: "multiple items",
+			data: append(pdv(1, 0x01, "cmd"), pdv(1, 0x02, "data")...),
+			expected: []value{
+				{1, 0x01, "cmd"},
+				{1, 0x02, "data"},
+			},
+		},
+		{
+			name: "header too short",
+			data: []byte{0x00, 0x00, 0x00},
+			err:  true,
+		},
+		{
+			name: "length exceeds PDU",
+			data: pdv(1, 0x03, "command")[:8],
+			err:  true,
+		},
+		{
+			name: "length too small",
+			data: []byte{0x00, 0x00, 0x00, 0x01, 0x01, 0x03},
+			err:  true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got []value
+
+			err := readPDVs(c.data, func(contextID byte, control byte, v []byte) error {
+				got = append(got, value{contextID, control, string(v)})
+
+				return nil
+			})
+
+			if c.err {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(got) != len(c.expected) {
+				t.Fatalf("expected %d items, got %d", len(c.expected), len(got))
+			}
+
+			for idx := range got {
+				if got[idx] != c.expected[idx] {
+					t.Errorf("expected item %d to be %+v, got %+v", idx, c.expected[idx], got[idx])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,477 @@
+// Package dimse implements a minimal DICOM upper layer and DIMSE service
+// provider that supports C-ECHO and C-FIND requests.
+package dimse
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/uid"
+)
+
+const (
+	// associationTimeout limits the time a peer has to request an
+	// association after connecting.
+	associationTimeout = 30 * time.Second
+
+	// idleTimeout defines how long an association may stay idle before it
+	// is aborted.
+	idleTimeout = 5 * time.Minute
+
+	// maxAssociations limits the number of concurrent associations.
+	// Further peers are rejected until an association ends.
+	maxAssociations = 32
+)
+
+// FindFunc handles a C-FIND request and returns all matching data sets.
+type FindFunc func(ctx context.Context, query dicom.Dataset) ([]dicom.Dataset, error)
+
+// Server is a DICOM service class provider that accepts associations and
+// answers C-ECHO requests as well as C-FIND requests for all registered
+// SOP classes.
+type Server struct {
+	aeTitle string
+	find    map[string]FindFunc
+}
+
+// NewServer returns a new server. If aeTitle is set, associations are only
+// accepted if the called AE title matches.
+func NewServer(aeTitle string) *Server {
+	return &Server{
+		aeTitle: aeTitle,
+		find:    make(map[string]FindFunc),
+	}
+}
+
+// HandleFind registers fn to answer C-FIND requests for the given SOP class
+// UID. HandleFind must not be called after Serve.
+func (srv *Server) HandleFind(sopClass string, fn FindFunc) {
+	srv.find[sopClass] = fn
+}
+
+// Serve accepts connections on l until ctx is cancelled. At most
+// maxAssociations connections are served concurrently.
+func (srv *Server) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	slots := make(chan struct{}, maxAssociations)
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			slog.Warn("too many associations, rejecting peer", "remote", conn.RemoteAddr().String())
+
+			conn.SetDeadline(time.Now().Add(associationTimeout))
+			writePDU(conn, pduAssociateRJ, encodeAssociateRJTransient(rejectLocalLimitExceeded))
+			conn.Close()
+
+			continue
+		}
+
+		go func() {
+			defer func() { <-slots }()
+
+			srv.serveConn(ctx, conn)
+		}()
+	}
+}
+
+// association holds the state of a single DICOM association.
+type association struct {
+	srv       *Server
+	conn      net.Conn
+	log       *slog.Logger
+	maxLength uint32
+	contexts  map[byte]acceptedContext
+}
+
+func (srv *Server) serveConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	a := &association{
+		srv:  srv,
+		conn: conn,
+		log:  slog.With("remote", conn.RemoteAddr().String()),
+	}
+
+	if err := a.negotiate(); err != nil {
+		a.log.Error("failed to negotiate association", "error", err)
+		return
+	}
+
+	if err := a.serve(ctx); err != nil {
+		a.log.Error("association aborted", "error", err)
+
+		writePDU(conn, pduAbort, encodeAbort())
+	}
+}
+
+func (a *association) negotiate() error {
+	a.conn.SetDeadline(time.Now().Add(associationTimeout))
+
+	p, err := readPDU(a.conn)
+	if err != nil {
+		return err
+	}
+
+	if p.typ != pduAssociateRQ {
+		writePDU(a.conn, pduAbort, encodeAbort())
+
+		return fmt.Errorf("unexpected PDU type 0x%02x", p.typ)
+	}
+
+	rq, err := parseAssociateRQ(p.data)
+	if err != nil {
+		writePDU(a.conn, pduAbort, encodeAbort())
+
+		return err
+	}
+
+	a.log = a.log.With("callingAE", rq.callingAE, "calledAE", rq.calledAE)
+
+	switch {
+	case rq.applicationContext != applicationContextName:
+		writePDU(a.conn, pduAssociateRJ, encodeAssociateRJ(rejectApplicationContextNotKnown))
+
+		return fmt.Errorf("unsupported application context %q", rq.applicationContext)
+
+	case a.srv.aeTitle != "" && rq.calledAE != a.srv.aeTitle:
+		writePDU(a.conn, pduAssociateRJ, encodeAssociateRJ(rejectCalledAENotRecognized))
+
+		return fmt.Errorf("called AE title %q not recognized", rq.calledAE)
+	}
+
+	a.maxLength = rq.maxLength
+	a.contexts = make(map[byte]acceptedContext, len(rq.contexts))
+
+	var result []acceptedContext
+	for _, pc := range rq.contexts {
+		ac := a.srv.negotiateContext(pc)
+
+		result = append(result, ac)
+
+		if ac.result == contextAccepted {
+			a.contexts[ac.id] = ac
+		}
+	}
+
+	if len(a.contexts) == 0 {
+		writePDU(a.conn, pduAssociateRJ, encodeAssociateRJ(rejectNoReason))
+
+		return fmt.Errorf("no acceptable presentation context")
+	}
+
+	a.log.Info("association accepted", "contexts", len(a.contexts))
+
+	return writePDU(a.conn, pduAssociateAC, encodeAssociateAC(rq, result))
+}
+
+func (srv *Server) negotiateContext(pc presentationContext) acceptedContext {
+	ac := acceptedContext{
+		id:             pc.id,
+		abstractSyntax: pc.abstractSyntax,
+		transferSyntax: uid.ImplicitVRLittleEndian,
+	}
+
+	if _, ok := srv.find[pc.abstractSyntax]; !ok && pc.abstractSyntax != uid.VerificationSOPClass {
+		ac.result = contextAbstractSyntaxUnsupported
+
+		return ac
+	}
+
+	for _, ts := range supportedTransferSyntaxes {
+		if slices.Contains(pc.transferSyntaxes, ts) {
+			ac.result = contextAccepted
+			ac.transferSyntax = ts
+
+			return ac
+		}
+	}
+
+	ac.result = contextTransferSyntaxUnsupported
+
+	return ac
+}
+
+// message is a DIMSE message assembled from one or more PDVs.
+type message struct {
+	contextID byte
+	command   command
+	data      []byte
+}
+
+func (a *association) serve(ctx context.Context) error {
+	var (
+		cmdBuf  bytes.Buffer
+		dataBuf bytes.Buffer
+		pending *message
+	)
+
+	for {
+		a.conn.SetDeadline(time.Now().Add(idleTimeout))
+
+		p, err := readPDU(a.conn)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		switch p.typ {
+		case pduDataTF:
+		case pduReleaseRQ:
+			a.log.Debug("association released")
+
+			return writePDU(a.conn, pduReleaseRP, make([]byte, 4))
+		case pduAbort:
+			a.log.Debug("association aborted by peer")
+
+			return nil
+		default:
+			return fmt.Errorf("unexpected PDU type 0x%02x", p.typ)
+		}
+
+		err = readPDVs(p.data, func(contextID byte, control byte, value []byte) error {
+			isCommand := control&0x01 != 0
+			isLast := control&0x02 != 0
+
+			if isCommand {
+				if cmdBuf.Len()+len(value) > maxMessageLength {
+					return errMessageTooLarge
+				}
+
+				cmdBuf.Write(value)
+
+				if !isLast {
+					return nil
+				}
+
+				cmd, err := decodeCommand(cmdBuf.Bytes())
+				cmdBuf.Reset()
+
+				if err != nil {
+					return err
+				}
+
+				pending = &message{
+					contextID: contextID,
+					command:   cmd,
+				}
+
+				if cmd.hasDataSet() {
+					return nil
+				}
+			} else {
+				if pending == nil {
+					return fmt.Errorf("received data set without command")
+				}
+
+				if dataBuf.Len()+len(value) > maxMessageLength {
+					return errMessageTooLarge
+				}
+
+				dataBuf.Write(value)
+
+				if !isLast {
+					return nil
+				}
+
+				pending.data = bytes.Clone(dataBuf.Bytes())
+				dataBuf.Reset()
+			}
+
+			msg := pending
+			pending = nil
+
+			return a.handle(ctx, msg)
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (a *association) handle(ctx context.Context, msg *message) error {
+	pc, ok := a.contexts[msg.contextID]
+	if !ok {
+		return fmt.Errorf("unknown presentation context %d", msg.contextID)
+	}
+
+	a.log.Debug("received DIMSE message", "command", msg.command.String())
+
+	switch msg.command.commandField {
+	case commandCEchoRQ:
+		return a.send(pc, command{
+			affectedSOPClass:     pc.abstractSyntax,
+			commandField:         commandCEchoRSP,
+			messageIDRespondedTo: msg.command.messageID,
+			dataSetType:          dataSetAbsent,
+			status:               statusSuccess,
+		}, nil)
+
+	case commandCFindRQ:
+		return a.handleFind(ctx, pc, msg)
+
+	case commandCancelRQ:
+		// C-FIND requests are answered synchronously so there is nothing
+		// left to cancel.
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported DIMSE command 0x%04x", msg.command.commandField)
+	}
+}
+
+func (a *association) handleFind(ctx context.Context, pc acceptedContext, msg *message) error {
+	rsp := command{
+		affectedSOPClass:     pc.abstractSyntax,
+		commandField:         commandCFindRSP,
+		messageIDRespondedTo: msg.command.messageID,
+		dataSetType:          dataSetAbsent,
+	}
+
+	fn, ok := a.srv.find[pc.abstractSyntax]
+	if !ok {
+		rsp.status = statusSOPClassNotSupported
+
+		return a.send(pc, rsp, nil)
+	}
+
+	query, err := decodeDataSet(msg.data, pc.transferSyntax)
+	if err != nil {
+		a.log.Error("failed to decode C-FIND identifier", "error", err)
+
+		rsp.status = statusIdentifierDoesNotMatch
+
+		return a.send(pc, rsp, nil)
+	}
+
+	results, err := fn(ctx, query)
+	if err != nil {
+		a.log.Error("failed to handle C-FIND request", "error", err)
+
+		rsp.status = statusUnableToProcess
+
+		return a.send(pc, rsp, nil)
+	}
+
+	for _, ds := range results {
+		data, err := encodeDataSet(ds, pc.transferSyntax)
+		if err != nil {
+			a.log.Error("failed to encode C-FIND result", "error", err)
+			continue
+		}
+
+		pendingRsp := rsp
+		pendingRsp.status = statusPending
+		pendingRsp.dataSetType = 0x0000
+
+		if err := a.send(pc, pendingRsp, data); err != nil {
+			return err
+		}
+	}
+
+	a.log.Info("C-FIND request handled", "matches", len(results))
+
+	rsp.status = statusSuccess
+
+	return a.send(pc, rsp, nil)
+}
+
+// send sends a DIMSE message. The command and data set are split into
+// fragments that respect the maximum PDU length of the peer.
+func (a *association) send(pc acceptedContext, cmd command, data []byte) error {
+	if err := a.sendFragments(pc.id, cmd.encodeResponse(), true); err != nil {
+		return err
+	}
+
+	if data != nil {
+		return a.sendFragments(pc.id, data, false)
+	}
+
+	return nil
+}
+
+func (a *association) sendFragments(contextID byte, value []byte, isCommand bool) error {
+	maxFragment := maxPDULength - 6
+	if a.maxLength > 6 && int(a.maxLength)-6 < maxFragment {
+		maxFragment = int(a.maxLength) - 6
+	}
+
+	for {
+		fragment := value
+		if len(fragment) > maxFragment {
+			fragment = value[:maxFragment]
+		}
+		value = value[len(fragment):]
+
+		var control byte
+		if isCommand {
+			control |= 0x01
+		}
+		if len(value) == 0 {
+			control |= 0x02
+		}
+
+		item := make([]byte, 0, 6+len(fragment))
+		item = binary.BigEndian.AppendUint32(item, uint32(len(fragment)+2))
+		item = append(item, contextID, control)
+		item = append(item, fragment...)
+
+		if err := writePDU(a.conn, pduDataTF, item); err != nil {
+			return err
+		}
+
+		if len(value) == 0 {
+			return nil
+		}
+	}
+}
+
+// readPDVs iterates over the presentation data value items of a P-DATA-TF
+// PDU.
+func readPDVs(data []byte, fn func(contextID byte, control byte, value []byte) error) error {
+	for len(data) > 0 {
+		if len(data) < 6 {
+			return fmt.Errorf("PDV item header too short")
+		}
+
+		length := int(binary.BigEndian.Uint32(data[0:4]))
+		if length < 2 || len(data) < 4+length {
+			return fmt.Errorf("invalid PDV item length %d", length)
+		}
+
+		if err := fn(data[4], data[5], data[6:4+length]); err != nil {
+			return err
+		}
+
+		data = data[4+length:]
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package dimse
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+	"net"
+	"testing"
+)
+
+func TestServeRejectsOversizedMessages(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	a := &association{
+		srv:  NewServer(""),
+		conn: server,
+		log:  slog.Default(),
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- a.serve(context.Background())
+		server.Close()
+	}()
+
+	// send non-last command fragments until the association is aborted
+	fragment := make([]byte, maxPDULength)
+	item := binary.BigEndian.AppendUint32(nil, uint32(len(fragment)+2))
+	item = append(item, 1, 0x01)
+	item = append(item, fragment...)
+
+	for sent := 0; sent <= maxMessageLength; sent += len(fragment) {
+		if err := writePDU(client, pduDataTF, item); err != nil {
+			break
+		}
+	}
+
+	if err := <-result; !errors.Is(err, errMessageTooLarge) {
+		t.Errorf("expected errMessageTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+package dimse
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// NewUID returns a new UUID derived UID as defined in PS3.5 B.2.
+func NewUID() (string, error) {
+	n, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate UID: %w", err)
+	}
+
+	return "2.25." + n.String(), nil
+}
@@ -5,28 +5,48 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
-	"path/filepath"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/orthanc"
 )
 
-func createStudyArchive(ctx context.Context, client *orthanc.Client, studyUid string, instances []orthanc.FindInstancesResponse, renderKinds []orthanc.RenderKind) (string, error) {
-
-	// create a temporary directory and download all files into it
-	dir, err := os.MkdirTemp("", "archive-"+studyUid+"-raw-")
+// createStudyArchive creates a temporary archive file and calls write to
+// stream the archive into it.
+func createStudyArchive(studyUid string, write func(w io.Writer) error) (string, error) {
+	// Create the archive file and stream all instances into it
+	archiveFile, err := os.CreateTemp("", "archive-"+studyUid+"-*.zip")
 	if err != nil {
-		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+		return "", fmt.Errorf("failed to create temporary archive file: %w", err)
+	}
+	defer archiveFile.Close()
+
+	if err := write(archiveFile); err != nil {
+		defer os.Remove(archiveFile.Name())
+
+		return "", err
+	}
+
+	return archiveFile.Name(), nil
+}
+
+// writeStudyArchive renders all instances and streams them into a ZIP
+// archive written to w. Instances are downloaded one after another directly
+// into the archive entries so memory usage does not depend on the size of
+// the study. names holds the entry name of each instance, without
+// extension, keyed by the orthanc instance ID.
+func writeStudyArchive(ctx context.Context, w io.Writer, client *orthanc.Client, instances []orthanc.FindInstancesResponse, names map[string]string, renderKinds []orthanc.RenderKind, progress func(done, total int)) error {
+	archive := zip.NewWriter(w)
+
+	if progress != nil {
+		progress(0, len(instances))
 	}
-	// make sure we clean up afterwards
-	defer os.RemoveAll(dir)
 
-	// download each instance to the temporary directory
-	// TODO(ppacher): instead of reading the images to RAM and then writting
-	// 				  to the file consider streaming the response directly to the FS
-	for _, instance := range instances {
+	for idx, instance := range instances {
 		slog.Info("downloading DICOM instance", "id", instance.ID)
 
 		for _, kind := range renderKinds {
@@ -37,49 +57,63 @@ func createStudyArchive(ctx context.Context, client *orthanc.Client, studyUid st
 				continue
 			}
 
-			blob, err := render(ctx, client, instance, kind)
-			if err != nil {
-				// not applicable to render
-				if errors.Is(err, ErrNotApplicable) {
+			// the archive entry must only be created if the instance can
+			// actually be rendered.
+			if kind == orthanc.KindAVI {
+				if _, err := numberOfFrames(instance); errors.Is(err, ErrNotApplicable) {
 					continue
 				}
-
-				return "", fmt.Errorf("failed to download and render instance %s: %w", instance.ID, err)
 			}
 
 			ext, err := getExtension(kind)
 			if err != nil {
-				return "", err
+				return err
 			}
 
-			dest := filepath.Join(dir, instance.ID+ext)
-			if err := os.WriteFile(dest, blob, 0o600); err != nil {
-				return "", fmt.Errorf("failed to write instance image/file to dist: %w", err)
+			name, ok := names[instance.ID]
+			if !ok {
+				name = instance.ID
 			}
 
-			slog.Info("succesfully downloaded instance file", "name", dest, "id", instance.ID, "size", len(blob))
-		}
-	}
+			// templates might already contain the extension
+			if !strings.HasSuffix(strings.ToLower(name), ext) {
+				name += ext
+			}
 
-	// Create the archive file and a zip writer
-	archiveFile, err := os.CreateTemp("", "archive-"+studyUid+"-*.zip")
-	if err != nil {
-		return "", fmt.Errorf("failed to create temporary archive file: %w", err)
-	}
-	defer archiveFile.Close()
-	archive := zip.NewWriter(archiveFile)
+			entry, err := archive.CreateHeader(&zip.FileHeader{
+				Name:     name,
+				Method:   compressionMethod(kind),
+				Modified: time.Now(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create archive entry: %w", err)
+			}
 
-	if err := archive.AddFS(os.DirFS(dir)); err != nil {
-		defer os.Remove(archiveFile.Name())
+			if err := render(ctx, client, instance, kind, entry); err != nil {
+				return fmt.Errorf("failed to download and render instance %s: %w", instance.ID, err)
+			}
+
+			slog.Info("succesfully downloaded instance file", "name", name, "id", instance.ID)
+		}
 
-		return "", fmt.Errorf("failed to create archive: %w", err)
+		if progress != nil {
+			progress(idx+1, len(instances))
+		}
 	}
 
 	if err := archive.Close(); err != nil {
-		defer os.Remove(archiveFile.Name())
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	return nil
+}
 
-		return "", fmt.Errorf("failed to finish archive: %w", err)
+// compressionMethod returns the ZIP compression method for kind. Images and
+// videos are already compressed and are thus stored as is.
+func compressionMethod(kind orthanc.RenderKind) uint16 {
+	if kind == orthanc.KindDICOM {
+		return zip.Deflate
 	}
 
-	return archiveFile.Name(), nil
+	return zip.Store
 }
@@ -0,0 +1,320 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	bridgev1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// defaultJobWorkers is used if the number of workers is not
+	// configured.
+	defaultJobWorkers = 2
+
+	// maxQueuedJobs limits the number of jobs waiting for a worker.
+	maxQueuedJobs = 64
+
+	// jobRetention defines how long finished jobs are kept.
+	jobRetention = time.Hour
+
+	// publishTimeout limits the time to publish a job event.
+	publishTimeout = 10 * time.Second
+)
+
+var (
+	ErrQueueFull   = errors.New("too many queued export jobs")
+	ErrJobNotFound = errors.New("export job not found")
+)
+
+// JobState describes the state of an export job.
+type JobState string
+
+const (
+	JobPending   = JobState("pending")
+	JobRunning   = JobState("running")
+	JobCompleted = JobState("completed")
+	JobFailed    = JobState("failed")
+)
+
+// Finished reports whether the job has completed or failed.
+func (s JobState) Finished() bool {
+	return s == JobCompleted || s == JobFailed
+}
+
+// Job is a snapshot of an export job.
+type Job struct {
+	ID       string
+	Creator  string
+	Instance string
+	StudyUID string
+	State    JobState
+
+	// Done and Total report the number of exported instances.
+	Done  int
+	Total int
+
+	// Artifact and DownloadLink are set once the job has completed.
+	Artifact     *repo.Artifact
+	DownloadLink string
+
+	Error       string
+	CreatedAt   time.Time
+	CompletedAt time.Time
+}
+
+// EventPublisher publishes event messages. It is implemented by
+// *events.Client.
+type EventPublisher interface {
+	Publish(ctx context.Context, msg proto.Message) error
+}
+
+type exportJob struct {
+	Job
+
+	options ExportOptions
+
+	// changed is closed and replaced whenever the job is updated.
+	changed chan struct{}
+}
+
+// JobQueue runs export jobs on a bounded number of workers. Jobs are kept
+// in memory only and are lost on restart.
+type JobQueue struct {
+	reg       *Registry
+	publicURL string
+	publisher EventPublisher
+
+	queue chan *exportJob
+
+	mu   sync.Mutex
+	jobs map[string]*exportJob
+}
+
+// NewJobQueue returns a new job queue and starts workers until ctx is
+// cancelled. If publisher is nil, no ExportJobEvents are published.
+func NewJobQueue(ctx context.Context, reg *Registry, publicURL string, publisher EventPublisher, workers int) *JobQueue {
+	if workers <= 0 {
+		workers = defaultJobWorkers
+	}
+
+	q := &JobQueue{
+		reg:       reg,
+		publicURL: publicURL,
+		publisher: publisher,
+		queue:     make(chan *exportJob, maxQueuedJobs),
+		jobs:      make(map[string]*exportJob),
+	}
+
+	for range workers {
+		go q.worker(ctx)
+	}
+
+	return q
+}
+
+// Submit queues a new export job. Options.Creator must be set since the
+// job is executed without the request context.
+func (q *JobQueue) Submit(options ExportOptions) (Job, error) {
+	job := &exportJob{
+		Job: Job{
+			ID:        GetRandomString(32),
+			Creator:   options.Creator,
+			Instance:  options.Instance,
+			StudyUID:  options.StudyUID,
+			State:     JobPending,
+			CreatedAt: time.Now(),
+		},
+		options: options,
+		changed: make(chan struct{}),
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for id, j := range q.jobs {
+		if j.State.Finished() && time.Since(j.CompletedAt) > jobRetention {
+			delete(q.jobs, id)
+		}
+	}
+
+	select {
+	case q.queue <- job:
+	default:
+		return Job{}, ErrQueueFull
+	}
+
+	q.jobs[job.ID] = job
+
+	return job.Job, nil
+}
+
+// Get returns the current state of the job with the given ID.
+func (q *JobQueue) Get(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+
+	return job.Job, nil
+}
+
+// Watch calls fn with the current state of the job with the given ID and
+// again whenever it changes. Watch returns once the job has finished, fn
+// returns an error or ctx is cancelled.
+func (q *JobQueue) Watch(ctx context.Context, id string, fn func(Job) error) error {
+	for {
+		q.mu.Lock()
+		job, ok := q.jobs[id]
+		if !ok {
+			q.mu.Unlock()
+			return ErrJobNotFound
+		}
+
+		snapshot, changed := job.Job, job.changed
+		q.mu.Unlock()
+
+		if err := fn(snapshot); err != nil {
+			return err
+		}
+
+		if snapshot.State.Finished() {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// update modifies job using fn and notifies all watchers.
+func (q *JobQueue) update(job *exportJob, fn func(*Job)) Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	fn(&job.Job)
+
+	close(job.changed)
+	job.changed = make(chan struct{})
+
+	return job.Job
+}
+
+func (q *JobQueue) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-q.queue:
+			q.run(ctx, job)
+		}
+	}
+}
+
+func (q *JobQueue) run(ctx context.Context, job *exportJob) {
+	q.update(job, func(j *Job) {
+		j.State = JobRunning
+	})
+
+	options := job.options
+	options.Progress = func(done, total int) {
+		q.update(job, func(j *Job) {
+			j.Done = done
+			j.Total = total
+		})
+	}
+
+	artifact, err := q.reg.Export(ctx, options)
+
+	var link string
+	if err == nil {
+		link, _, err = q.reg.DownloadLink(q.publicURL, artifact, LinkOptions{})
+	}
+
+	result := q.update(job, func(j *Job) {
+		j.CompletedAt = time.Now()
+
+		if err != nil {
+			j.State = JobFailed
+			j.Error = err.Error()
+
+			return
+		}
+
+		j.State = JobCompleted
+		j.Artifact = &artifact
+		j.DownloadLink = link
+	})
+
+	if err != nil {
+		slog.Error("export job failed", "id", job.ID, "study", job.StudyUID, "error", err)
+	}
+
+	q.publish(result)
+}
+
+func (q *JobQueue) publish(job Job) {
+	if q.publisher == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	if err := q.publisher.Publish(ctx, &bridgev1.ExportJobEvent{Job: JobProto(job)}); err != nil {
+		slog.Error("failed to publish export job event", "id", job.ID, "error", err)
+	}
+}
+
+// JobProto converts job to its protobuf representation.
+func JobProto(job Job) *bridgev1.ExportJob {
+	pb := &bridgev1.ExportJob{
+		Id:             job.ID,
+		State:          jobStateProto(job.State),
+		Creator:        job.Creator,
+		Instance:       job.Instance,
+		StudyUid:       job.StudyUID,
+		TotalInstances: int32(job.Total),
+		DoneInstances:  int32(job.Done),
+		DownloadLink:   job.DownloadLink,
+		Error:          job.Error,
+		CreateTime:     timestamppb.New(job.CreatedAt),
+	}
+
+	if job.Artifact != nil {
+		pb.ExpireTime = timestamppb.New(job.Artifact.ExpiresAt)
+	}
+
+	if !job.CompletedAt.IsZero() {
+		pb.CompleteTime = timestamppb.New(job.CompletedAt)
+	}
+
+	return pb
+}
+
+func jobStateProto(state JobState) bridgev1.ExportJobState {
+	switch state {
+	case JobPending:
+		return bridgev1.ExportJobState_EXPORT_JOB_STATE_PENDING
+	case JobRunning:
+		return bridgev1.ExportJobState_EXPORT_JOB_STATE_RUNNING
+	case JobCompleted:
+		return bridgev1.ExportJobState_EXPORT_JOB_STATE_COMPLETED
+	case JobFailed:
+		return bridgev1.ExportJobState_EXPORT_JOB_STATE_FAILED
+	default:
+		return bridgev1.ExportJobState_EXPORT_JOB_STATE_UNSPECIFIED
+	}
+}
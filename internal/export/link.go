@@ -0,0 +1,115 @@
+package export
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+)
+
+// Query parameters of signed download links.
+const (
+	linkExpiresParam   = "exp"
+	linkNonceParam     = "once"
+	linkSignatureParam = "sig"
+)
+
+var (
+	errInvalidSignature = errors.New("invalid download link signature")
+	errLinkExpired      = errors.New("download link expired")
+)
+
+// LinkOptions configures a signed download link.
+type LinkOptions struct {
+	// ValidUntil defines when the link expires. It defaults to and is
+	// capped at the expiration time of the artifact and the maximum time
+	// to live configured for the registry.
+	ValidUntil time.Time
+
+	// OneTime creates a link that can only be used for a single download.
+	OneTime bool
+}
+
+// DownloadLink returns a signed download link for artifact that can be
+// used without authentication until it expires.
+func (reg *Registry) DownloadLink(publicURL string, artifact repo.Artifact, opts LinkOptions) (string, time.Time, error) {
+	u, err := url.Parse(publicURL)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to parse public URL: %w", err)
+	}
+
+	expires := artifact.ExpiresAt
+	if maxExpires := time.Now().Add(reg.maxTTL); expires.After(maxExpires) {
+		expires = maxExpires
+	}
+
+	if !opts.ValidUntil.IsZero() && opts.ValidUntil.Before(expires) {
+		expires = opts.ValidUntil
+	}
+
+	var nonce string
+	if opts.OneTime {
+		nonce = GetRandomString(16)
+	}
+
+	query := url.Values{}
+	query.Set(linkExpiresParam, strconv.FormatInt(expires.Unix(), 10))
+
+	if nonce != "" {
+		query.Set(linkNonceParam, nonce)
+	}
+
+	query.Set(linkSignatureParam, reg.signLink(artifact.ID, expires.Unix(), nonce))
+
+	u.Path = path.Join(u.Path, "download", artifact.ID)
+	u.RawQuery = query.Encode()
+
+	return u.String(), expires, nil
+}
+
+// verifyLink verifies the signature of a download link for the artifact
+// with the given ID and returns the nonce of one-time links.
+func (reg *Registry) verifyLink(id string, query url.Values) (string, error) {
+	exp, err := strconv.ParseInt(query.Get(linkExpiresParam), 10, 64)
+	if err != nil {
+		return "", errInvalidSignature
+	}
+
+	nonce := query.Get(linkNonceParam)
+
+	expected := reg.signLink(id, exp, nonce)
+	if !hmac.Equal([]byte(expected), []byte(query.Get(linkSignatureParam))) {
+		return "", errInvalidSignature
+	}
+
+	if time.Now().Unix() > exp {
+		return "", errLinkExpired
+	}
+
+	return nonce, nil
+}
+
+func (reg *Registry) signLink(id string, exp int64, nonce string) string {
+	mac := hmac.New(sha256.New, reg.signingKey)
+	mac.Write([]byte(strings.Join([]string{id, strconv.FormatInt(exp, 10), nonce}, "|")))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// isInitialRequest reports whether r requests the file from the beginning.
+// Range requests used to resume a download are not counted as separate
+// downloads.
+func isInitialRequest(r *http.Request) bool {
+	rng := r.Header.Get("Range")
+
+	return rng == "" || strings.HasPrefix(rng, "bytes=0-")
+}
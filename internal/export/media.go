@@ -0,0 +1,576 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/tag"
+	"github.com/suyashkumar/dicom/pkg/uid"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dimse"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/orthanc"
+)
+
+const (
+	// mediaStorageDirectoryStorage is the SOP class UID of a DICOMDIR.
+	mediaStorageDirectoryStorage = "1.2.840.10008.1.3.10"
+
+	// mediaHeaderSize is the number of bytes of each DICOM file that are
+	// parsed to build its directory records.
+	mediaHeaderSize = 256 * 1024
+
+	// mediaDirectory is the directory of the media that contains all
+	// DICOM files.
+	mediaDirectory = "DICOM"
+)
+
+// Attributes copied from the DICOM files into the directory records. The
+// tags must be sorted in ascending order.
+var (
+	patientRecordTags = []tag.Tag{tag.PatientName, tag.PatientID}
+	studyRecordTags   = []tag.Tag{tag.StudyDate, tag.StudyTime, tag.AccessionNumber, tag.StudyDescription, tag.StudyInstanceUID, tag.StudyID}
+	seriesRecordTags  = []tag.Tag{tag.Modality, tag.SeriesInstanceUID, tag.SeriesNumber}
+	imageRecordTags   = []tag.Tag{tag.InstanceNumber}
+)
+
+// ValidateOptions ensures the given render kinds can be combined into a
+// single export. DICOMDIR media and PDF reports cannot be combined with
+// other kinds and a viewer can only be included in DICOMDIR media if one
+// is configured.
+func (reg *Registry) ValidateOptions(kinds []orthanc.RenderKind, includeViewer bool) error {
+	isMedia := slices.Contains(kinds, orthanc.KindDICOMDIR)
+
+	if isMedia && len(kinds) > 1 {
+		return fmt.Errorf("DICOMDIR exports cannot be combined with other formats")
+	}
+
+	if slices.Contains(kinds, orthanc.KindPDF) && len(kinds) > 1 {
+		return fmt.Errorf("PDF reports cannot be combined with other formats")
+	}
+
+	if includeViewer && !isMedia {
+		return fmt.Errorf("a viewer can only be included in DICOMDIR exports")
+	}
+
+	if includeViewer && reg.viewerDir == "" {
+		return fmt.Errorf("no media viewer configured")
+	}
+
+	return nil
+}
+
+// writeArchive writes a ZIP archive of res to w using the given render
+// kinds.
+func (reg *Registry) writeArchive(ctx context.Context, w io.Writer, res *studyAndInstances, kinds []orthanc.RenderKind, includeViewer bool, progress func(done, total int)) error {
+	if !slices.Contains(kinds, orthanc.KindDICOMDIR) {
+		return writeStudyArchive(ctx, w, res.cli, res.instances, reg.entryNames(res), kinds, progress)
+	}
+
+	var viewerDir string
+	if includeViewer {
+		viewerDir = reg.viewerDir
+	}
+
+	return writeMediaArchive(ctx, w, res.cli, res.instances, viewerDir, progress)
+}
+
+// mediaFile describes a DICOM file stored on the media.
+type mediaFile struct {
+	// fileID holds the path components of the file relative to the root
+	// of the media.
+	fileID []string
+
+	sopClassUID    string
+	sopInstanceUID string
+	transferSyntax string
+
+	attrs map[tag.Tag][]string
+}
+
+// writeMediaArchive streams a ZIP archive to w that is laid out as a DICOM
+// file-set as defined in PS3.10 and may be copied to a CD or USB drive as
+// is. All instances are stored in the DICOM directory using 8 character
+// file IDs and a DICOMDIR index is added to the root of the archive. If
+// viewerDir is set, its content is added to the root of the archive as
+// well.
+func writeMediaArchive(ctx context.Context, w io.Writer, client *orthanc.Client, instances []orthanc.FindInstancesResponse, viewerDir string, progress func(done, total int)) error {
+	archive := zip.NewWriter(w)
+
+	if progress != nil {
+		progress(0, len(instances))
+	}
+
+	var (
+		series = make(map[string]int)
+		images = make(map[string]int)
+		files  = make([]mediaFile, 0, len(instances))
+	)
+
+	for idx, instance := range instances {
+		slog.Info("downloading DICOM instance", "id", instance.ID)
+
+		seriesIdx, ok := series[instance.ParentSeries]
+		if !ok {
+			seriesIdx = len(series) + 1
+			series[instance.ParentSeries] = seriesIdx
+		}
+
+		images[instance.ParentSeries]++
+
+		// the export always contains a single study so there is only one
+		// patient and study directory.
+		fileID := []string{
+			mediaDirectory,
+			"PAT00001",
+			"STU00001",
+			fmt.Sprintf("SER%05d", seriesIdx),
+			fmt.Sprintf("IMG%05d", images[instance.ParentSeries]),
+		}
+
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     path.Join(fileID...),
+			Method:   compressionMethod(orthanc.KindDICOM),
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create archive entry: %w", err)
+		}
+
+		header := &headerBuffer{limit: mediaHeaderSize}
+
+		if err := client.StreamRenderedInstance(ctx, instance.ID, 0, orthanc.KindDICOM, io.MultiWriter(entry, header)); err != nil {
+			return fmt.Errorf("failed to download instance %s: %w", instance.ID, err)
+		}
+
+		file, err := parseMediaFile(header.Bytes())
+		if err != nil {
+			return fmt.Errorf("instance %s: %w", instance.ID, err)
+		}
+		file.fileID = fileID
+
+		files = append(files, file)
+
+		if progress != nil {
+			progress(idx+1, len(instances))
+		}
+	}
+
+	entry, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     "DICOMDIR",
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create archive entry: %w", err)
+	}
+
+	if err := writeDicomdir(entry, files); err != nil {
+		return fmt.Errorf("failed to create DICOMDIR: %w", err)
+	}
+
+	if viewerDir != "" {
+		if err := archive.AddFS(os.DirFS(viewerDir)); err != nil {
+			return fmt.Errorf("failed to add viewer: %w", err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	return nil
+}
+
+// headerBuffer keeps the first limit bytes written to it and discards the
+// rest.
+type headerBuffer struct {
+	bytes.Buffer
+
+	limit int
+}
+
+func (b *headerBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.Len(); remaining > 0 {
+		b.Buffer.Write(p[:min(len(p), remaining)])
+	}
+
+	return len(p), nil
+}
+
+// parseMediaFile parses the beginning of a DICOM file and returns the
+// attributes required for its directory records.
+func parseMediaFile(data []byte) (mediaFile, error) {
+	p, err := dicom.NewParser(bytes.NewReader(data), int64(len(data)), nil, dicom.SkipPixelData(), dicom.AllowUnknownSpecificCharacterSet())
+	if err != nil {
+		return mediaFile{}, fmt.Errorf("failed to parse DICOM file: %w", err)
+	}
+
+	file := mediaFile{
+		attrs: make(map[tag.Tag][]string),
+	}
+
+	meta := p.GetMetadata()
+	if elem, err := meta.FindElementByTag(tag.TransferSyntaxUID); err == nil && elem.Value.ValueType() == dicom.Strings {
+		if values := dicom.MustGetStrings(elem.Value); len(values) > 0 {
+			file.transferSyntax = values[0]
+		}
+	}
+
+	// data is truncated so parsing ends with an error at the latest once
+	// all available bytes have been consumed. Missing attributes are
+	// detected below.
+	for {
+		elem, err := p.Next()
+		if err != nil || elem.Tag.Group > 0x0020 {
+			break
+		}
+
+		if elem.Value.ValueType() == dicom.Strings {
+			file.attrs[elem.Tag] = dicom.MustGetStrings(elem.Value)
+		}
+	}
+
+	file.sopClassUID = file.value(tag.SOPClassUID)
+	file.sopInstanceUID = file.value(tag.SOPInstanceUID)
+
+	for name, value := range map[string]string{
+		"TransferSyntaxUID": file.transferSyntax,
+		"SOPClassUID":       file.sopClassUID,
+		"SOPInstanceUID":    file.sopInstanceUID,
+		"StudyInstanceUID":  file.value(tag.StudyInstanceUID),
+		"SeriesInstanceUID": file.value(tag.SeriesInstanceUID),
+	} {
+		if value == "" {
+			return mediaFile{}, fmt.Errorf("DICOM file is missing %s", name)
+		}
+	}
+
+	return file, nil
+}
+
+func (f mediaFile) value(t tag.Tag) string {
+	if values := f.attrs[t]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func (f mediaFile) number(t tag.Tag) int {
+	n, _ := strconv.Atoi(f.value(t))
+
+	return n
+}
+
+// dirRecord is a directory record of a DICOMDIR.
+type dirRecord struct {
+	recordType string
+	elements   []*dicom.Element
+
+	next  *dirRecord
+	lower *dirRecord
+}
+
+// newDirRecord returns a directory record of the given type with the
+// given attributes copied from file.
+func newDirRecord(recordType string, file mediaFile, tags []tag.Tag) (*dirRecord, error) {
+	record := &dirRecord{
+		recordType: recordType,
+	}
+
+	needsUTF8 := false
+
+	for _, t := range tags {
+		values := file.attrs[t]
+		if len(values) == 0 {
+			// the attributes are type 1 or 2 but we can only copy what
+			// the file contains.
+			values = []string{""}
+		}
+
+		for _, v := range values {
+			if !isASCII(v) {
+				needsUTF8 = true
+			}
+		}
+
+		elem, err := dicom.NewElement(t, values)
+		if err != nil {
+			return nil, err
+		}
+
+		record.elements = append(record.elements, elem)
+	}
+
+	// values have been decoded to UTF-8 by the parser.
+	if needsUTF8 {
+		elem, err := dicom.NewElement(tag.SpecificCharacterSet, []string{"ISO_IR 192"})
+		if err != nil {
+			return nil, err
+		}
+
+		record.elements = append([]*dicom.Element{elem}, record.elements...)
+	}
+
+	return record, nil
+}
+
+// item returns the elements of the record. offset is used to resolve the
+// offset of the referenced records.
+func (r *dirRecord) item(offset func(*dirRecord) int) ([]*dicom.Element, error) {
+	values := []struct {
+		tag   tag.Tag
+		value any
+	}{
+		{tag.OffsetOfTheNextDirectoryRecord, []int{offset(r.next)}},
+		{tag.RecordInUseFlag, []int{0xFFFF}},
+		{tag.OffsetOfReferencedLowerLevelDirectoryEntity, []int{offset(r.lower)}},
+		{tag.DirectoryRecordType, []string{r.recordType}},
+	}
+
+	elements := make([]*dicom.Element, 0, len(values)+len(r.elements))
+	for _, v := range values {
+		elem, err := dicom.NewElement(v.tag, v.value)
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, elem)
+	}
+
+	elements = append(elements, r.elements...)
+
+	slices.SortStableFunc(elements, func(a, b *dicom.Element) int {
+		return cmp.Or(cmp.Compare(a.Tag.Group, b.Tag.Group), cmp.Compare(a.Tag.Element, b.Tag.Element))
+	})
+
+	return elements, nil
+}
+
+// writeDicomdir writes a DICOMDIR for files to w. Files are grouped into
+// a PATIENT, STUDY, SERIES and IMAGE record hierarchy.
+func writeDicomdir(w io.Writer, files []mediaFile) error {
+	if len(files) == 0 {
+		return fmt.Errorf("no files")
+	}
+
+	// records are sorted by series and instance number.
+	files = slices.Clone(files)
+	slices.SortStableFunc(files, func(a, b mediaFile) int {
+		return cmp.Or(
+			cmp.Compare(a.number(tag.SeriesNumber), b.number(tag.SeriesNumber)),
+			cmp.Compare(a.value(tag.SeriesInstanceUID), b.value(tag.SeriesInstanceUID)),
+			cmp.Compare(a.number(tag.InstanceNumber), b.number(tag.InstanceNumber)),
+		)
+	})
+
+	patient, err := newDirRecord("PATIENT", files[0], patientRecordTags)
+	if err != nil {
+		return err
+	}
+
+	study, err := newDirRecord("STUDY", files[0], studyRecordTags)
+	if err != nil {
+		return err
+	}
+	patient.lower = study
+
+	records := []*dirRecord{patient, study}
+
+	var (
+		lastSeries    *dirRecord
+		lastImage     *dirRecord
+		lastSeriesUID string
+	)
+
+	for idx, file := range files {
+		if idx == 0 || file.value(tag.SeriesInstanceUID) != lastSeriesUID {
+			series, err := newDirRecord("SERIES", file, seriesRecordTags)
+			if err != nil {
+				return err
+			}
+
+			if lastSeries == nil {
+				study.lower = series
+			} else {
+				lastSeries.next = series
+			}
+
+			records = append(records, series)
+			lastSeries, lastImage = series, nil
+			lastSeriesUID = file.value(tag.SeriesInstanceUID)
+		}
+
+		image, err := newDirRecord("IMAGE", file, imageRecordTags)
+		if err != nil {
+			return err
+		}
+
+		for _, v := range []struct {
+			tag   tag.Tag
+			value []string
+		}{
+			{tag.ReferencedFileID, file.fileID},
+			{tag.ReferencedSOPClassUIDInFile, []string{file.sopClassUID}},
+			{tag.ReferencedSOPInstanceUIDInFile, []string{file.sopInstanceUID}},
+			{tag.ReferencedTransferSyntaxUIDInFile, []string{file.transferSyntax}},
+		} {
+			elem, err := dicom.NewElement(v.tag, v.value)
+			if err != nil {
+				return err
+			}
+
+			image.elements = append(image.elements, elem)
+		}
+
+		if lastImage == nil {
+			lastSeries.lower = image
+		} else {
+			lastImage.next = image
+		}
+
+		records = append(records, image)
+		lastImage = image
+	}
+
+	instanceUID, err := dimse.NewUID()
+	if err != nil {
+		return err
+	}
+
+	// offsets are counted from the beginning of the file. Since all
+	// offsets are encoded using a fixed size, the layout can be computed
+	// using placeholder values.
+	header, err := dicomdirHeader(instanceUID, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := dicom.Write(&buf, dicom.Dataset{Elements: header}); err != nil {
+		return err
+	}
+
+	// explicit VR sequence header with undefined length
+	offset := buf.Len() + 12
+
+	offsets := make(map[*dirRecord]int, len(records))
+	for _, r := range records {
+		offsets[r] = offset
+
+		elements, err := r.item(func(*dirRecord) int { return 0 })
+		if err != nil {
+			return err
+		}
+
+		size, err := encodedSize(elements)
+		if err != nil {
+			return err
+		}
+
+		// item and item delimitation headers
+		offset += 8 + size + 8
+	}
+
+	resolve := func(r *dirRecord) int {
+		if r == nil {
+			return 0
+		}
+
+		return offsets[r]
+	}
+
+	items := make([][]*dicom.Element, len(records))
+	for idx, r := range records {
+		items[idx], err = r.item(resolve)
+		if err != nil {
+			return err
+		}
+	}
+
+	header, err = dicomdirHeader(instanceUID, offsets[patient], offsets[patient])
+	if err != nil {
+		return err
+	}
+
+	sequence, err := dicom.NewElement(tag.DirectoryRecordSequence, items)
+	if err != nil {
+		return err
+	}
+
+	return dicom.Write(w, dicom.Dataset{Elements: append(header, sequence)})
+}
+
+// dicomdirHeader returns the file meta information and file-set
+// attributes of a DICOMDIR.
+func dicomdirHeader(instanceUID string, firstRecord, lastRecord int) ([]*dicom.Element, error) {
+	values := []struct {
+		tag   tag.Tag
+		value any
+	}{
+		{tag.FileMetaInformationVersion, []byte{0x00, 0x01}},
+		{tag.MediaStorageSOPClassUID, []string{mediaStorageDirectoryStorage}},
+		{tag.MediaStorageSOPInstanceUID, []string{instanceUID}},
+		{tag.TransferSyntaxUID, []string{uid.ExplicitVRLittleEndian}},
+		{tag.ImplementationClassUID, []string{dimse.ImplementationClassUID}},
+		{tag.ImplementationVersionName, []string{dimse.ImplementationVersionName}},
+		{tag.FileSetID, []string{""}},
+		{tag.OffsetOfTheFirstDirectoryRecordOfTheRootDirectoryEntity, []int{firstRecord}},
+		{tag.OffsetOfTheLastDirectoryRecordOfTheRootDirectoryEntity, []int{lastRecord}},
+		{tag.FileSetConsistencyFlag, []int{0}},
+	}
+
+	elements := make([]*dicom.Element, 0, len(values))
+	for _, v := range values {
+		elem, err := dicom.NewElement(v.tag, v.value)
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, elem)
+	}
+
+	return elements, nil
+}
+
+// encodedSize returns the number of bytes required to encode elements
+// using explicit VR little endian.
+func encodedSize(elements []*dicom.Element) (int, error) {
+	var buf bytes.Buffer
+
+	w, err := dicom.NewWriter(&buf)
+	if err != nil {
+		return 0, err
+	}
+	w.SetTransferSyntax(binary.LittleEndian, false)
+
+	for _, elem := range elements {
+		if err := w.WriteElement(elem); err != nil {
+			return 0, err
+		}
+	}
+
+	return buf.Len(), nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,126 @@
+package export
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultNameTemplate is used to name archive entries if no template is
+// configured.
+const DefaultNameTemplate = "{SeriesNumber:3}-{SeriesDescription}/{InstanceNumber:4}"
+
+var templatePlaceholder = regexp.MustCompile(`\{([A-Za-z0-9]+)(?::([0-9]+))?\}`)
+
+// NameTemplate builds the names of archive entries from DICOM tags.
+//
+// Placeholders like {SeriesDescription} are replaced by the value of the
+// tag taken from the instance, its series, study or patient. An optional
+// width like in {InstanceNumber:4} pads numeric values with leading zeros
+// so entries sort in acquisition order. Slashes in the template create
+// folders and the extension of the render kind is appended to the name.
+type NameTemplate string
+
+func parseNameTemplate(s string) (NameTemplate, error) {
+	if s == "" {
+		return DefaultNameTemplate, nil
+	}
+
+	if !templatePlaceholder.MatchString(s) {
+		return "", fmt.Errorf("name template %q does not contain any placeholders", s)
+	}
+
+	if rest := templatePlaceholder.ReplaceAllString(s, ""); strings.ContainsAny(rest, "{}") {
+		return "", fmt.Errorf("name template %q contains invalid placeholders", s)
+	}
+
+	return NameTemplate(s), nil
+}
+
+// render returns the name for an instance. lookup returns the value of a
+// tag or an empty string. Empty folder names are removed and an empty
+// string is returned if nothing is left.
+func (t NameTemplate) render(lookup func(tag string) string) string {
+	name := templatePlaceholder.ReplaceAllStringFunc(string(t), func(placeholder string) string {
+		match := templatePlaceholder.FindStringSubmatch(placeholder)
+
+		// values must not create additional folders.
+		value := strings.NewReplacer("/", "-", "\\", "-").Replace(lookup(match[1]))
+		value = sanitizeName(value)
+
+		if match[2] != "" {
+			width, _ := strconv.Atoi(match[2])
+
+			if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+				value = fmt.Sprintf("%0*d", width, n)
+			}
+		}
+
+		return value
+	})
+
+	segments := strings.Split(name, "/")
+
+	cleaned := segments[:0]
+	for _, s := range segments {
+		// trimming dots also prevents path traversal using ".."
+		if s = strings.Trim(s, "-_. "); s != "" {
+			cleaned = append(cleaned, s)
+		}
+	}
+
+	return strings.Join(cleaned, "/")
+}
+
+// sanitizeName removes characters from s that are not suitable for file
+// names.
+func sanitizeName(s string) string {
+	s = strings.ReplaceAll(s, "ERROR", "")
+	s = strings.ReplaceAll(s, ",", "-")
+	s = strings.ReplaceAll(s, " ", "-")
+	s = strings.ReplaceAll(s, "\n", "")
+
+	// characters that are reserved on Windows
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"|?*`, r) || r < 0x20 {
+			return -1
+		}
+
+		return r
+	}, s)
+
+	for strings.Contains(s, "--") {
+		s = strings.ReplaceAll(s, "--", "-")
+	}
+
+	return strings.TrimSpace(s)
+}
+
+// entryNames returns the archive entry name of each instance of res,
+// without extension, keyed by the orthanc instance ID. Names are unique
+// within the archive.
+func (reg *Registry) entryNames(res *studyAndInstances) map[string]string {
+	names := make(map[string]string, len(res.instances))
+	taken := make(map[string]bool, len(res.instances))
+
+	for _, instance := range res.instances {
+		name := reg.nameTemplate.render(func(tag string) string {
+			return res.tagValue(instance, tag)
+		})
+
+		if name == "" {
+			name = instance.ID
+		}
+
+		unique := name
+		for i := 2; taken[strings.ToLower(unique)]; i++ {
+			unique = fmt.Sprintf("%s-%d", name, i)
+		}
+
+		taken[strings.ToLower(unique)] = true
+		names[instance.ID] = unique
+	}
+
+	return names
+}
@@ -1,11 +1,14 @@
 package export
 
 import (
+	"cmp"
 	"context"
+	cryptorand "crypto/rand"
 	"crypto/sha1"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
@@ -18,38 +21,124 @@ import (
 
 	"github.com/bufbuild/connect-go"
 	"github.com/tierklinik-dobersberg/apis/pkg/auth"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/audit"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/orthanc"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
-	"golang.org/x/exp/rand"
 )
 
+// defaultMaxTTL is used if RegistryOptions.MaxTTL is not set.
+const defaultMaxTTL = 24 * time.Hour
+
 type Storage interface {
 	AddArtifact(context.Context, repo.Artifact) error
 	FindArtifact(context.Context, string) (*repo.Artifact, error)
 	FindCleanupCandidates(context.Context, time.Time) ([]repo.Artifact, error)
 	DeleteArtifacts(context.Context, []string) error
+	RecordArtifactDownload(ctx context.Context, id string, nonce string) (*repo.Artifact, error)
 	FindByHashAndUpdateExpiry(ctx context.Context, hash string, expiry time.Time) (*repo.Artifact, error)
 }
 
 type Registry struct {
-	repo Storage
+	repo  Storage
+	audit *audit.Logger
+
+	// signingKey is used to sign download links.
+	signingKey []byte
+
+	// viewerDir holds a portable DICOM viewer that may be included in
+	// DICOMDIR media.
+	viewerDir string
+
+	// nameTemplate is used to name archive entries.
+	nameTemplate NameTemplate
 
-	cli *orthanc.Client
+	// reportColumns and reportRows define the preview grid of PDF reports.
+	reportColumns int
+	reportRows    int
+
+	// maxTTL caps the time to live of artifacts and the validity of
+	// download links.
+	maxTTL time.Duration
+
+	clients map[string]*orthanc.Client
 
 	wg sync.WaitGroup
 }
 
-func NewRegistry(ctx context.Context, cli *orthanc.Client, repo Storage) *Registry {
+// RegistryOptions configures a Registry.
+type RegistryOptions struct {
+	// SigningKey is used to sign download links. If empty, a random key is
+	// used so signed download links do not survive a restart.
+	SigningKey []byte
+
+	// ViewerDirectory may be set to a directory containing a portable
+	// DICOM viewer that is included in DICOMDIR media on request.
+	ViewerDirectory string
+
+	// NameTemplate is used to name archive entries. Defaults to
+	// DefaultNameTemplate.
+	NameTemplate string
+
+	// ReportColumns and ReportRows define the grid of previews on each
+	// page of a PDF report. Default to 2 columns and 3 rows.
+	ReportColumns int
+	ReportRows    int
+
+	// MaxTTL caps the time to live of artifacts and the validity of
+	// download links. Defaults to 24 hours.
+	MaxTTL time.Duration
+}
+
+// NewRegistry returns a new artifact registry.
+func NewRegistry(ctx context.Context, clients map[string]*orthanc.Client, repo Storage, auditLog *audit.Logger, opts RegistryOptions) (*Registry, error) {
+	signingKey := opts.SigningKey
+	if len(signingKey) == 0 {
+		signingKey = make([]byte, 32)
+		if _, err := cryptorand.Read(signingKey); err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+	}
+
+	nameTemplate, err := parseNameTemplate(opts.NameTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	reportColumns := opts.ReportColumns
+	if reportColumns <= 0 {
+		reportColumns = defaultReportColumns
+	}
+
+	reportRows := opts.ReportRows
+	if reportRows <= 0 {
+		reportRows = defaultReportRows
+	}
+
+	maxTTL := opts.MaxTTL
+	if maxTTL <= 0 {
+		maxTTL = defaultMaxTTL
+	}
+
 	reg := &Registry{
-		repo: repo,
-		cli:  cli,
+		repo:          repo,
+		audit:         auditLog,
+		signingKey:    signingKey,
+		viewerDir:     opts.ViewerDirectory,
+		nameTemplate:  nameTemplate,
+		reportColumns: reportColumns,
+		reportRows:    reportRows,
+		maxTTL:        maxTTL,
+		clients:       clients,
 	}
 
 	reg.start(ctx)
 
-	return reg
+	return reg, nil
 }
 
+// ServeHTTP serves artifacts. Requests must either use a signed download
+// link or be authenticated as the creator of the artifact using the
+// X-Remote-User-ID header set by the forward authentication endpoint.
 func (reg *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -57,23 +146,111 @@ func (reg *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	archiveId := r.PathValue("id")
+	userId := r.Header.Get("X-Remote-User-ID")
 
-	archive, err := reg.repo.FindArtifact(r.Context(), archiveId)
+	entry := repo.AuditEntry{
+		Action:   repo.AuditDownloadArtifact,
+		User:     userId,
+		ClientIP: audit.ClientIP(r),
+	}
+
+	archive, status, err := reg.authorizeDownload(r, archiveId, userId)
 	if err != nil {
-		if errors.Is(err, repo.ErrNotFound) {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
+		entry.Outcome = repo.AuditOutcomeFailed
+		if status == http.StatusUnauthorized || status == http.StatusForbidden {
+			entry.Outcome = repo.AuditOutcomeDenied
+		}
+
+		entry.Detail = fmt.Sprintf("artifact %s: %s", archiveId, err)
+		if archive != nil {
+			entry.Instance = archive.Instance
+			entry.StudyUIDs = []string{archive.StudyUID}
 		}
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		reg.audit.Record(entry)
+
+		http.Error(w, err.Error(), status)
 		return
 	}
 
+	entry.Instance = archive.Instance
+	entry.StudyUIDs = []string{archive.StudyUID}
+	entry.Outcome = repo.AuditOutcomeSuccess
+	entry.Detail = fmt.Sprintf("artifact %s created by %s, download #%d", archive.ID, archive.Creator, archive.Downloads)
+
+	reg.audit.Record(entry)
+
 	w.Header().Set("Content-Disposition", "attachment; filename=\""+archive.DownloadName+"\"")
 
 	http.ServeFile(w, r, archive.Filepath)
 }
 
+// authorizeDownload ensures the request may download the artifact with the
+// given ID and records the download. In case of an error, the HTTP status
+// code to reply with is returned.
+func (reg *Registry) authorizeDownload(r *http.Request, id string, userId string) (*repo.Artifact, int, error) {
+	var nonce string
+
+	if r.URL.Query().Has(linkSignatureParam) {
+		var err error
+
+		nonce, err = reg.verifyLink(id, r.URL.Query())
+		if err != nil {
+			return nil, http.StatusForbidden, err
+		}
+	} else {
+		if userId == "" {
+			return nil, http.StatusUnauthorized, fmt.Errorf("authentication required")
+		}
+
+		archive, err := reg.repo.FindArtifact(r.Context(), id)
+		if err != nil {
+			return nil, downloadErrorStatus(err), err
+		}
+
+		if archive.Creator != userId {
+			return archive, http.StatusForbidden, fmt.Errorf("artifact has been created by a different user")
+		}
+	}
+
+	// requests resuming a download are not counted again. One-time links
+	// cannot be resumed.
+	if !isInitialRequest(r) {
+		if nonce != "" {
+			return nil, http.StatusGone, repo.ErrLinkUsed
+		}
+
+		archive, err := reg.repo.FindArtifact(r.Context(), id)
+		if err != nil {
+			return nil, downloadErrorStatus(err), err
+		}
+
+		return archive, http.StatusOK, nil
+	}
+
+	archive, err := reg.repo.RecordArtifactDownload(r.Context(), id, nonce)
+	if err != nil {
+		return nil, downloadErrorStatus(err), err
+	}
+
+	if time.Now().After(archive.ExpiresAt) {
+		return archive, http.StatusGone, fmt.Errorf("artifact expired")
+	}
+
+	return archive, http.StatusOK, nil
+}
+
+func downloadErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repo.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repo.ErrLinkUsed):
+		return http.StatusGone
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func (reg *Registry) start(ctx context.Context) {
 	reg.wg.Add(1)
 	go func() {
@@ -120,20 +297,77 @@ func (reg *Registry) Wait() {
 
 type ExportOptions struct {
 	TTL          time.Duration
+	Instance     string
 	StudyUID     string
 	InstanceUIDs []string
 	Kinds        []orthanc.RenderKind
+
+	// IncludeViewer adds the configured portable viewer to DICOMDIR
+	// media.
+	IncludeViewer bool
+
+	// Creator is the ID of the user that requested the export. It defaults
+	// to the authenticated user of the context passed to Export.
+	Creator string
+
+	// Progress may be set to get notified whenever an instance has been
+	// exported.
+	Progress func(done, total int)
 }
 
 type studyAndInstances struct {
+	creator           string
+	instance          string
+	cli               *orthanc.Client
 	studyUID          string
 	patientName       string
 	responsiblePerson string
 	instances         []orthanc.FindInstancesResponse
+
+	// tags of the study and its patient and series, the latter keyed by
+	// the orthanc series ID.
+	studyTags   map[string]any
+	patientTags map[string]any
+	series      map[string]orthanc.FindSeriesResponse
+}
+
+// tagValue returns the value of the given main DICOM tag of instance, its
+// series, study or patient.
+func (res *studyAndInstances) tagValue(instance orthanc.FindInstancesResponse, tag string) string {
+	for _, tags := range []map[string]any{
+		instance.MainDicomTags,
+		res.series[instance.ParentSeries].MainDicomTags,
+		res.studyTags,
+		res.patientTags,
+	} {
+		if v, ok := tags[tag].(string); ok {
+			return v
+		}
+	}
+
+	return ""
 }
 
 func (reg *Registry) Export(ctx context.Context, options ExportOptions) (repo.Artifact, error) {
-	hash := getHash(options.StudyUID, options.InstanceUIDs, options.Kinds)
+	cli, ok := reg.clients[options.Instance]
+	if !ok {
+		return repo.Artifact{}, connect.NewError(connect.CodeNotFound, fmt.Errorf("orthanc instance %q not found", options.Instance))
+	}
+
+	if err := reg.ValidateOptions(options.Kinds, options.IncludeViewer); err != nil {
+		return repo.Artifact{}, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	options.TTL = min(options.TTL, reg.maxTTL)
+
+	// artifacts are bound to their creator so they are not shared between
+	// users.
+	creator := options.Creator
+	if user := auth.From(ctx); creator == "" && user != nil {
+		creator = user.ID
+	}
+
+	hash := getHash(creator, options.Instance, options.StudyUID, options.InstanceUIDs, options.Kinds, options.IncludeViewer)
 	existing, err := reg.repo.FindByHashAndUpdateExpiry(ctx, hash, time.Now().Add(options.TTL))
 	if err == nil {
 		return *existing, nil
@@ -144,26 +378,32 @@ func (reg *Registry) Export(ctx context.Context, options ExportOptions) (repo.Ar
 		// still continue to generate the artifact
 	}
 
-	res, err := reg.fetchStudyAndInstances(ctx, options.StudyUID, options.InstanceUIDs)
+	res, err := reg.fetchStudyAndInstances(ctx, cli, options.StudyUID, options.InstanceUIDs)
 	if err != nil {
 		return repo.Artifact{}, fmt.Errorf("failed to fetch study instances: %w", err)
 	}
+	res.instance = options.Instance
+	res.creator = creator
 
 	if len(res.instances) == 0 {
 		return repo.Artifact{}, fmt.Errorf("instance not found")
 	}
 
-	needsArchive := len(options.InstanceUIDs) != 1 || len(options.Kinds) != 1
+	if options.Kinds[0] == orthanc.KindPDF {
+		return reg.exportReport(ctx, options, res, hash)
+	}
+
+	needsArchive := len(options.InstanceUIDs) != 1 || len(options.Kinds) != 1 || options.Kinds[0] == orthanc.KindDICOMDIR
 	if needsArchive {
-		return reg.exportArchive(ctx, options.TTL, res, options.Kinds, hash)
+		return reg.exportArchive(ctx, options, res, hash)
 	}
 
-	return reg.exportSingle(ctx, options.TTL, res, options.Kinds[0], hash)
+	return reg.exportSingle(ctx, options.TTL, res, options.Kinds[0], hash, options.Progress)
 }
 
-func (reg *Registry) fetchStudyAndInstances(ctx context.Context, studyUid string, filterInstanceUids []string) (*studyAndInstances, error) {
+func (reg *Registry) fetchStudyAndInstances(ctx context.Context, cli *orthanc.Client, studyUid string, filterInstanceUids []string) (*studyAndInstances, error) {
 	// first, read the study metadata
-	studies, err := reg.cli.FindStudy(ctx, orthanc.ByStudyUID(studyUid))
+	studies, err := cli.FindStudy(ctx, orthanc.ByStudyUID(studyUid))
 	if err != nil {
 		return nil, fmt.Errorf("failed to find study: %w", err)
 	}
@@ -181,7 +421,7 @@ func (reg *Registry) fetchStudyAndInstances(ctx context.Context, studyUid string
 	patientName, _ := study.PatientMainDicomTags["PatientName"].(string)
 	ownerName, _ := study.PatientMainDicomTags["ResponsiblePerson"].(string)
 
-	instances, err := reg.cli.FindInstances(ctx, orthanc.ByStudyUID(studyUid))
+	instances, err := cli.FindInstances(ctx, orthanc.ByStudyUID(studyUid))
 	if err != nil {
 		return nil, fmt.Errorf("failed to contact orthanc API: %w", err)
 	}
@@ -206,78 +446,76 @@ func (reg *Registry) fetchStudyAndInstances(ctx context.Context, studyUid string
 		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("no instances to download"))
 	}
 
+	seriesList, err := cli.FindSeries(ctx, orthanc.ByStudyUID(studyUid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find study series: %w", err)
+	}
+
+	series := make(map[string]orthanc.FindSeriesResponse, len(seriesList))
+	for _, s := range seriesList {
+		series[s.ID] = s
+	}
+
+	// instances are exported in acquisition order
+	number := func(tags map[string]any, name string) int {
+		s, _ := tags[name].(string)
+		n, _ := strconv.Atoi(strings.TrimSpace(s))
+
+		return n
+	}
+
+	slices.SortStableFunc(filteredInstances, func(a, b orthanc.FindInstancesResponse) int {
+		return cmp.Or(
+			cmp.Compare(number(series[a.ParentSeries].MainDicomTags, "SeriesNumber"), number(series[b.ParentSeries].MainDicomTags, "SeriesNumber")),
+			cmp.Compare(a.ParentSeries, b.ParentSeries),
+			cmp.Compare(number(a.MainDicomTags, "InstanceNumber"), number(b.MainDicomTags, "InstanceNumber")),
+		)
+	})
+
 	return &studyAndInstances{
+		cli:               cli,
 		studyUID:          studyUid,
 		patientName:       patientName,
 		responsiblePerson: ownerName,
 		instances:         filteredInstances,
+		studyTags:         study.MainDicomTags,
+		patientTags:       study.PatientMainDicomTags,
+		series:            series,
 	}, nil
 }
 
-func (reg *Registry) exportArchive(ctx context.Context, ttl time.Duration, res *studyAndInstances, renderKinds []orthanc.RenderKind, hash string) (repo.Artifact, error) {
-	path, err := createStudyArchive(ctx, reg.cli, res.studyUID, res.instances, renderKinds)
+func (reg *Registry) exportArchive(ctx context.Context, options ExportOptions, res *studyAndInstances, hash string) (repo.Artifact, error) {
+	path, err := createStudyArchive(res.studyUID, func(w io.Writer) error {
+		return reg.writeArchive(ctx, w, res, options.Kinds, options.IncludeViewer, options.Progress)
+	})
 	if err != nil {
 		return repo.Artifact{}, err
 	}
 
-	return reg.storeArtifact(ctx, path, ttl, res, renderKinds, hash)
+	return reg.storeArtifact(ctx, path, options.TTL, res, options.Kinds, hash)
 }
 
-func (reg *Registry) exportSingle(ctx context.Context, ttl time.Duration, res *studyAndInstances, kind orthanc.RenderKind, hash string) (repo.Artifact, error) {
-	path, err := exportSingle(ctx, res.studyUID, res.instances, reg.cli, kind)
+func (reg *Registry) exportSingle(ctx context.Context, ttl time.Duration, res *studyAndInstances, kind orthanc.RenderKind, hash string, progress func(done, total int)) (repo.Artifact, error) {
+	path, err := exportSingle(ctx, res.studyUID, res.instances, res.cli, kind)
 	if err != nil {
 		return repo.Artifact{}, err
 	}
 
+	if progress != nil {
+		progress(1, 1)
+	}
+
 	return reg.storeArtifact(ctx, path, ttl, res, []orthanc.RenderKind{kind}, hash)
 }
 
 func (reg *Registry) storeArtifact(ctx context.Context, path string, ttl time.Duration, res *studyAndInstances, kinds []orthanc.RenderKind, hash string) (repo.Artifact, error) {
-	creator := ""
-
-	if user := auth.From(ctx); user != nil {
-		creator = user.ID
-	}
-
 	filterUids := make([]string, len(res.instances))
 	for idx, i := range res.instances {
 		filterUids[idx], _ = i.MainDicomTags["SOPInstanceUID"].(string)
 	}
 
 	// Construct the artifact file name
-	filename := filepath.Base(path)
-	replace := func(s string) string {
-		s = strings.ReplaceAll(s, "ERROR", "")
-		s = strings.ReplaceAll(s, ",", "-")
-		s = strings.ReplaceAll(s, " ", "-")
-		s = strings.ReplaceAll(s, "\n", "")
-
-		for strings.Contains(s, "--") {
-			s = strings.ReplaceAll(s, "--", "-")
-		}
-
-		return strings.TrimSpace(s)
-	}
-
-	if res.responsiblePerson != "" || res.patientName != "" {
-		parts := []string{}
-
-		if on := replace(res.responsiblePerson); on != "" {
-			parts = append(parts, on)
-		}
-
-		if pn := replace(res.patientName); pn != "" {
-			parts = append(parts, pn)
-		}
-
-		if len(parts) == 0 {
-			parts = []string{
-				res.studyUID,
-			}
-		}
-
-		filename = strings.Join(parts, "-") + filepath.Ext(path)
-	}
+	filename := downloadName(res, filepath.Base(path), filepath.Ext(path))
 
 	artifact := repo.Artifact{
 		ID:           GetRandomString(32),
@@ -285,7 +523,8 @@ func (reg *Registry) storeArtifact(ctx context.Context, path string, ttl time.Du
 		DownloadName: filename,
 		CreatedAt:    time.Now(),
 		ExpiresAt:    time.Now().Add(ttl),
-		Creator:      creator,
+		Creator:      res.creator,
+		Instance:     res.instance,
 		StudyUID:     res.studyUID,
 		InstanceUIDs: filterUids,
 		RenderTypes:  kinds,
@@ -301,21 +540,66 @@ func (reg *Registry) storeArtifact(ctx context.Context, path string, ttl time.Du
 	return artifact, nil
 }
 
-var letterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+// downloadName returns the file name used to download an export of res.
+// The name is built from the responsible person and the patient name and
+// falls back to fallback if both are empty.
+func downloadName(res *studyAndInstances, fallback string, ext string) string {
+	if res.responsiblePerson == "" && res.patientName == "" {
+		return fallback
+	}
 
-var source = rand.New(rand.NewSource(uint64(time.Now().UnixNano())))
+	parts := []string{}
+
+	if on := sanitizeName(res.responsiblePerson); on != "" {
+		parts = append(parts, on)
+	}
+
+	if pn := sanitizeName(res.patientName); pn != "" {
+		parts = append(parts, pn)
+	}
 
+	if len(parts) == 0 {
+		parts = []string{
+			res.studyUID,
+		}
+	}
+
+	return strings.Join(parts, "-") + ext
+}
+
+var letterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+
+// GetRandomString returns a random string of n letters. It is used for
+// secrets like share tokens and download nonces so it reads from
+// crypto/rand.
 func GetRandomString(n int) string {
-	b := make([]rune, n)
-	for i := range b {
-		b[i] = letterRunes[source.Intn(len(letterRunes))]
+	// bytes at or above limit are discarded so every letter is equally
+	// likely.
+	limit := 256 - 256%len(letterRunes)
+
+	b := make([]rune, 0, n)
+	buf := make([]byte, n)
+
+	for len(b) < n {
+		if _, err := cryptorand.Read(buf); err != nil {
+			panic(fmt.Sprintf("failed to read random bytes: %s", err))
+		}
+
+		for _, v := range buf {
+			if int(v) < limit && len(b) < n {
+				b = append(b, letterRunes[int(v)%len(letterRunes)])
+			}
+		}
 	}
+
 	return string(b)
 }
 
-func getHash(studyUid string, instanceUids []string, kinds []orthanc.RenderKind) string {
+func getHash(creator string, instance string, studyUid string, instanceUids []string, kinds []orthanc.RenderKind, includeViewer bool) string {
 	hasher := sha1.New()
 
+	_, _ = hasher.Write([]byte(creator))
+	_, _ = hasher.Write([]byte(instance))
 	_, _ = hasher.Write([]byte(studyUid))
 
 	slices.Sort(instanceUids)
@@ -328,5 +612,7 @@ func getHash(studyUid string, instanceUids []string, kinds []orthanc.RenderKind)
 		_, _ = hasher.Write([]byte(strconv.Itoa(int(k))))
 	}
 
+	_, _ = hasher.Write([]byte(strconv.FormatBool(includeViewer)))
+
 	return hex.EncodeToString(hasher.Sum(nil))
 }
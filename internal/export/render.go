@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"image/jpeg"
+	"io"
 	"os"
 	"strconv"
 
@@ -15,24 +16,38 @@ import (
 
 var ErrNotApplicable = errors.New("render type not applicable for instance")
 
-func render(ctx context.Context, cli *orthanc.Client, instance orthanc.FindInstancesResponse, kind orthanc.RenderKind) ([]byte, error) {
-	if kind != orthanc.KindAVI {
-		return cli.GetRenderedInstance(ctx, instance.ID, 0, kind)
-	}
-
+// numberOfFrames returns the number of frames of a multi-frame instance.
+// ErrNotApplicable is returned for single-frame instances.
+func numberOfFrames(instance orthanc.FindInstancesResponse) (int, error) {
 	numberOfFrames, ok := instance.MainDicomTags["NumberOfFrames"].(string)
 	if !ok {
-		return nil, ErrNotApplicable
+		return 0, ErrNotApplicable
 	}
 
 	conv, err := strconv.Atoi(numberOfFrames)
 	if err != nil {
-		return nil, fmt.Errorf("invalid value for NumberOfFrames: %v (%T)", numberOfFrames, numberOfFrames)
+		return 0, fmt.Errorf("invalid value for NumberOfFrames: %v (%T)", numberOfFrames, numberOfFrames)
+	}
+
+	return conv, nil
+}
+
+// render renders instance using the given kind and writes the result to w.
+// ErrNotApplicable is returned before anything is written to w if kind
+// cannot be used for instance.
+func render(ctx context.Context, cli *orthanc.Client, instance orthanc.FindInstancesResponse, kind orthanc.RenderKind, w io.Writer) error {
+	if kind != orthanc.KindAVI {
+		return cli.StreamRenderedInstance(ctx, instance.ID, 0, kind, w)
+	}
+
+	conv, err := numberOfFrames(instance)
+	if err != nil {
+		return err
 	}
 
 	tmpFile, err := os.CreateTemp("", instance.ID+"-*.avi")
 	if err != nil {
-		return nil, err
+		return err
 	}
 	tmpFile.Close()
 	defer os.Remove(tmpFile.Name())
@@ -42,29 +57,39 @@ func render(ctx context.Context, cli *orthanc.Client, instance orthanc.FindInsta
 	for i := 1; i <= int(conv); i++ {
 		blob, err := cli.GetRenderedInstance(ctx, instance.ID, i, orthanc.KindJPEG)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get rendered frame: %w", err)
+			return fmt.Errorf("failed to get rendered frame: %w", err)
 		}
 
 		if writer == nil {
 			img, err := jpeg.Decode(bytes.NewReader(blob))
 			if err != nil {
-				return nil, fmt.Errorf("failed to decode JPEG image: %w", err)
+				return fmt.Errorf("failed to decode JPEG image: %w", err)
 			}
 
 			writer, err = mjpeg.New(tmpFile.Name(), int32(img.Bounds().Dx()), int32(img.Bounds().Dy()), 10)
 			if err != nil {
-				return nil, err
+				return err
 			}
 		}
 
 		if err := writer.AddFrame(blob); err != nil {
-			return nil, fmt.Errorf("failed to create frame: %w", err)
+			return fmt.Errorf("failed to create frame: %w", err)
 		}
 	}
 
 	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close mjpeg writer: %w", err)
+		return fmt.Errorf("failed to close mjpeg writer: %w", err)
 	}
 
-	return os.ReadFile(tmpFile.Name())
+	// the AVI is written to a file by the mjpeg package and copied to w
+	// afterwards.
+	f, err := os.Open(tmpFile.Name())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+
+	return err
 }
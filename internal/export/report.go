@@ -0,0 +1,256 @@
+package export
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/orthanc"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/pdf"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+)
+
+const (
+	// defaultReportColumns and defaultReportRows define the grid of
+	// previews on each page of a report if not configured otherwise.
+	defaultReportColumns = 2
+	defaultReportRows    = 3
+
+	reportMargin      = 40.0
+	reportHeaderSize  = 50.0
+	reportCaptionSize = 16.0
+	reportCellPadding = 6.0
+)
+
+// reportSeries holds the instances of a series included in a report.
+type reportSeries struct {
+	tags      map[string]any
+	instances []orthanc.FindInstancesResponse
+}
+
+// exportReport renders a PDF report of res and stores it as an artifact.
+func (reg *Registry) exportReport(ctx context.Context, options ExportOptions, res *studyAndInstances, hash string) (repo.Artifact, error) {
+	tmpFile, err := os.CreateTemp("", "report-"+res.studyUID+"-*.pdf")
+	if err != nil {
+		return repo.Artifact{}, fmt.Errorf("failed to create temporary report file: %w", err)
+	}
+	defer tmpFile.Close()
+
+	if err := reg.writeReport(ctx, tmpFile, res, options.Progress); err != nil {
+		defer os.Remove(tmpFile.Name())
+
+		return repo.Artifact{}, fmt.Errorf("failed to create report: %w", err)
+	}
+
+	return reg.storeArtifact(ctx, tmpFile.Name(), options.TTL, res, options.Kinds, hash)
+}
+
+// writeReport writes a PDF report of res to w. The report starts with a
+// cover page describing the study followed by a grid of JPEG previews for
+// each series.
+func (reg *Registry) writeReport(ctx context.Context, w io.Writer, res *studyAndInstances, progress func(done, total int)) error {
+	doc := pdf.NewWriter(w)
+
+	// instances are already sorted in acquisition order so they only need
+	// to be grouped by series.
+	var series []*reportSeries
+	for _, instance := range res.instances {
+		if len(series) == 0 || series[len(series)-1].instances[0].ParentSeries != instance.ParentSeries {
+			series = append(series, &reportSeries{
+				tags: res.series[instance.ParentSeries].MainDicomTags,
+			})
+		}
+
+		s := series[len(series)-1]
+		s.instances = append(s.instances, instance)
+	}
+
+	if err := doc.AddPage(reportCover(res, series)); err != nil {
+		return err
+	}
+
+	if progress != nil {
+		progress(0, len(res.instances))
+	}
+
+	columns, rows := reg.reportColumns, reg.reportRows
+	perPage := columns * rows
+	done := 0
+
+	for idx, s := range series {
+		for start := 0; start < len(s.instances); start += perPage {
+			page := pdf.NewPage()
+
+			title := fmt.Sprintf("Series %s", cmp.Or(tagString(s.tags, "SeriesNumber"), fmt.Sprint(idx+1)))
+			if desc := tagString(s.tags, "SeriesDescription"); desc != "" {
+				title += ": " + desc
+			}
+			if modality := tagString(s.tags, "Modality"); modality != "" {
+				title += " (" + modality + ")"
+			}
+
+			reportHeader(page, res, title)
+
+			var (
+				cellWidth  = (page.Width - 2*reportMargin) / float64(columns)
+				cellHeight = (page.Height - 2*reportMargin - reportHeaderSize) / float64(rows)
+			)
+
+			for cell, instance := range s.instances[start:min(start+perPage, len(s.instances))] {
+				x := reportMargin + float64(cell%columns)*cellWidth
+				y := reportMargin + reportHeaderSize + float64(cell/columns)*cellHeight
+
+				if err := reportCell(ctx, doc, page, res.cli, instance, x, y, cellWidth, cellHeight); err != nil {
+					return err
+				}
+
+				done++
+				if progress != nil {
+					progress(done, len(res.instances))
+				}
+			}
+
+			if err := doc.AddPage(page); err != nil {
+				return err
+			}
+		}
+	}
+
+	return doc.Close()
+}
+
+// reportCover returns the cover page of a report.
+func reportCover(res *studyAndInstances, series []*reportSeries) *pdf.Page {
+	page := pdf.NewPage()
+
+	var modalities []string
+	for _, s := range series {
+		if m := tagString(s.tags, "Modality"); m != "" && !slices.Contains(modalities, m) {
+			modalities = append(modalities, m)
+		}
+	}
+
+	fields := []struct {
+		label string
+		value string
+	}{
+		{"Patient", res.patientName},
+		{"Patient ID", tagString(res.patientTags, "PatientID")},
+		{"Owner", res.responsiblePerson},
+		{"Study date", formatDicomDate(tagString(res.studyTags, "StudyDate"))},
+		{"Study", tagString(res.studyTags, "StudyDescription")},
+		{"Modality", strings.Join(modalities, ", ")},
+		{"Institution", tagString(res.studyTags, "InstitutionName")},
+		{"Referring physician", tagString(res.studyTags, "ReferringPhysicianName")},
+		{"Series", fmt.Sprint(len(series))},
+		{"Images", fmt.Sprint(len(res.instances))},
+	}
+
+	y := reportMargin + 60
+	page.Text(reportMargin, y, pdf.HelveticaBold, 24, "Study Report")
+
+	y += 16
+	page.Line(reportMargin, y, page.Width-reportMargin, y)
+
+	y += 40
+	for _, f := range fields {
+		value := strings.ReplaceAll(f.value, "^", " ")
+		if value == "" {
+			value = "-"
+		}
+
+		page.Text(reportMargin, y, pdf.HelveticaBold, 12, f.label)
+		page.Text(reportMargin+150, y, pdf.Helvetica, 12, pdf.Truncate(pdf.Helvetica, 12, value, page.Width-2*reportMargin-150))
+
+		y += 24
+	}
+
+	page.Text(reportMargin, page.Height-reportMargin, pdf.Helvetica, 8, "Created "+time.Now().Format("2006-01-02 15:04"))
+
+	return page
+}
+
+// reportHeader draws the page header of a series page.
+func reportHeader(page *pdf.Page, res *studyAndInstances, title string) {
+	maxWidth := page.Width - 2*reportMargin
+
+	page.Text(reportMargin, reportMargin+12, pdf.HelveticaBold, 12, pdf.Truncate(pdf.HelveticaBold, 12, title, maxWidth))
+
+	subtitle := strings.ReplaceAll(strings.Join(slices.DeleteFunc([]string{res.responsiblePerson, res.patientName}, func(s string) bool { return s == "" }), " - "), "^", " ")
+	page.Text(reportMargin, reportMargin+28, pdf.Helvetica, 9, pdf.Truncate(pdf.Helvetica, 9, subtitle, maxWidth))
+
+	page.Line(reportMargin, reportMargin+36, page.Width-reportMargin, reportMargin+36)
+}
+
+// reportCell renders a preview of instance and draws it with a caption
+// into the given grid cell.
+func reportCell(ctx context.Context, doc *pdf.Writer, page *pdf.Page, cli *orthanc.Client, instance orthanc.FindInstancesResponse, x, y, width, height float64) error {
+	caption := "Image"
+	if number := tagString(instance.MainDicomTags, "InstanceNumber"); number != "" {
+		caption += " " + number
+	}
+	if frames, err := numberOfFrames(instance); err == nil {
+		caption += fmt.Sprintf(" (%d frames)", frames)
+	}
+
+	var (
+		maxWidth  = width - 2*reportCellPadding
+		maxHeight = height - 2*reportCellPadding - reportCaptionSize
+	)
+
+	// a single broken preview should not fail the whole report. Instances
+	// that cannot be rendered, like structured reports, are listed without
+	// preview.
+	img, err := reportPreview(ctx, doc, cli, instance)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		slog.Error("failed to add preview to report", "id", instance.ID, "error", err)
+
+		caption += " (preview not available)"
+	} else {
+		w, h := pdf.FitImage(img, maxWidth, maxHeight)
+		page.Image(img, x+(width-w)/2, y+reportCellPadding+(maxHeight-h)/2, w, h)
+	}
+
+	caption = pdf.Truncate(pdf.Helvetica, 9, caption, maxWidth)
+	page.Text(x+(width-pdf.TextWidth(pdf.Helvetica, 9, caption))/2, y+height-reportCellPadding-4, pdf.Helvetica, 9, caption)
+
+	return nil
+}
+
+// reportPreview renders the first frame of instance as JPEG and adds it to
+// doc.
+func reportPreview(ctx context.Context, doc *pdf.Writer, cli *orthanc.Client, instance orthanc.FindInstancesResponse) (pdf.Image, error) {
+	blob, err := cli.GetRenderedInstance(ctx, instance.ID, 0, orthanc.KindJPEG)
+	if err != nil {
+		return pdf.Image{}, fmt.Errorf("failed to render instance: %w", err)
+	}
+
+	return doc.AddJPEG(blob)
+}
+
+func tagString(tags map[string]any, name string) string {
+	s, _ := tags[name].(string)
+
+	return strings.TrimSpace(s)
+}
+
+// formatDicomDate formats a DICOM DA value. Invalid values are returned
+// as is.
+func formatDicomDate(s string) string {
+	t, err := time.Parse("20060102", s)
+	if err != nil {
+		return s
+	}
+
+	return t.Format("2006-01-02")
+}
@@ -1,10 +1,8 @@
 package export
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"os"
 
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/orthanc"
@@ -18,18 +16,13 @@ func exportSingle(ctx context.Context, studyUid string, instances []orthanc.Find
 		return "", err
 	}
 
-	blob, err := render(ctx, client, instance, kind)
-	if err != nil {
-		return "", err
-	}
-
 	tmpFile, err := os.CreateTemp("", instance.ID+"-*-"+ext)
 	if err != nil {
 		return "", err
 	}
 	defer tmpFile.Close()
 
-	if _, err := io.Copy(tmpFile, bytes.NewReader(blob)); err != nil {
+	if err := render(ctx, client, instance, kind, tmpFile); err != nil {
 		defer os.Remove(tmpFile.Name())
 
 		return "", fmt.Errorf("failed to write file: %w", err)
@@ -48,6 +41,8 @@ func getExtension(kind orthanc.RenderKind) (string, error) {
 		return ".png", nil
 	case orthanc.KindAVI:
 		return ".avi", nil
+	case orthanc.KindPDF:
+		return ".pdf", nil
 
 	default:
 		return "", fmt.Errorf("unsupported render kind")
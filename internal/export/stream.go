@@ -0,0 +1,161 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/audit"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/orthanc"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+)
+
+// ParseRenderKind parses the name of a render kind as used in query
+// parameters.
+func ParseRenderKind(s string) (orthanc.RenderKind, error) {
+	switch strings.ToLower(s) {
+	case "dicom", "dcm":
+		return orthanc.KindDICOM, nil
+	case "jpeg", "jpg":
+		return orthanc.KindJPEG, nil
+	case "png":
+		return orthanc.KindPNG, nil
+	case "avi":
+		return orthanc.KindAVI, nil
+	case "dicomdir":
+		return orthanc.KindDICOMDIR, nil
+	case "pdf":
+		return orthanc.KindPDF, nil
+	default:
+		return 0, fmt.Errorf("unsupported render kind %q", s)
+	}
+}
+
+// ServeArchive streams a ZIP archive of a study directly to the client
+// without storing it as an artifact. It is meant for exports that are only
+// downloaded once and thus do not need to be cached.
+//
+// The orthanc instance and study are taken from the {instance} and {study}
+// path values. The render kinds are selected using the "type" query
+// parameter and the "instance" query parameter may be used to limit the
+// archive to the given SOP instance UIDs. For DICOMDIR media, "viewer=true"
+// adds the configured portable viewer. Requests must be authenticated
+// using the X-Remote-User-ID header set by the forward authentication
+// endpoint.
+func (reg *Registry) ServeArchive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	entry := repo.AuditEntry{
+		Action:   repo.AuditDownloadStudy,
+		User:     r.Header.Get("X-Remote-User-ID"),
+		Instance: r.PathValue("instance"),
+		Outcome:  repo.AuditOutcomeSuccess,
+		Detail:   "streamed archive",
+		ClientIP: audit.ClientIP(r),
+	}
+
+	if study := r.PathValue("study"); study != "" {
+		entry.StudyUIDs = []string{study}
+	}
+
+	defer func() {
+		reg.audit.Record(entry)
+	}()
+
+	fail := func(status int, err error) {
+		entry.Outcome = repo.AuditOutcomeFailed
+		if status == http.StatusUnauthorized {
+			entry.Outcome = repo.AuditOutcomeDenied
+		}
+		entry.Detail = err.Error()
+
+		http.Error(w, err.Error(), status)
+	}
+
+	if entry.User == "" {
+		fail(http.StatusUnauthorized, fmt.Errorf("authentication required"))
+		return
+	}
+
+	cli, ok := reg.clients[entry.Instance]
+	if !ok {
+		fail(http.StatusNotFound, fmt.Errorf("orthanc instance %q not found", entry.Instance))
+		return
+	}
+
+	query := r.URL.Query()
+
+	var kinds []orthanc.RenderKind
+	for _, t := range query["type"] {
+		kind, err := ParseRenderKind(t)
+		if err != nil {
+			fail(http.StatusBadRequest, err)
+			return
+		}
+
+		kinds = append(kinds, kind)
+	}
+
+	slices.Sort(kinds)
+	kinds = slices.Compact(kinds)
+
+	if len(kinds) == 0 {
+		kinds = []orthanc.RenderKind{orthanc.KindDICOM}
+	}
+
+	var includeViewer bool
+	if v := query.Get("viewer"); v != "" {
+		var err error
+
+		includeViewer, err = strconv.ParseBool(v)
+		if err != nil {
+			fail(http.StatusBadRequest, fmt.Errorf("invalid value for viewer: %w", err))
+			return
+		}
+	}
+
+	if err := reg.ValidateOptions(kinds, includeViewer); err != nil {
+		fail(http.StatusBadRequest, err)
+		return
+	}
+
+	if slices.Contains(kinds, orthanc.KindPDF) {
+		fail(http.StatusBadRequest, fmt.Errorf("PDF reports cannot be streamed as an archive"))
+		return
+	}
+
+	res, err := reg.fetchStudyAndInstances(r.Context(), cli, r.PathValue("study"), query["instance"])
+	if err != nil {
+		status := http.StatusInternalServerError
+
+		var cerr *connect.Error
+		if errors.As(err, &cerr) && cerr.Code() == connect.CodeNotFound {
+			status = http.StatusNotFound
+		}
+
+		fail(status, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+downloadName(res, res.studyUID+".zip", ".zip")+"\"")
+
+	// once the first byte has been written, errors can only be reported by
+	// aborting the response.
+	if err := reg.writeArchive(r.Context(), w, res, kinds, includeViewer, nil); err != nil {
+		slog.Error("failed to stream study archive", "study", res.studyUID, "error", err)
+
+		entry.Outcome = repo.AuditOutcomeFailed
+		entry.Detail = err.Error()
+
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,258 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/changes"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dicomweb"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/orthanc"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+)
+
+type Storage interface {
+	changes.Storage
+
+	UpsertIndexedStudy(ctx context.Context, study repo.IndexedStudy) error
+	DeleteIndexedStudy(ctx context.Context, instance string, orthancId string) error
+	FindIndexedStudyByResource(ctx context.Context, instance string, orthancId string) (*repo.IndexedStudy, error)
+}
+
+// Indexer keeps the study index of a single orthanc instance up-to-date by
+// tailing the /changes feed of orthanc.
+type Indexer struct {
+	instance string
+	cli      *orthanc.Client
+	repo     Storage
+
+	feed *changes.Feed
+}
+
+func New(ctx context.Context, instance string, cli *orthanc.Client, repo Storage, interval time.Duration) *Indexer {
+	idx := &Indexer{
+		instance: instance,
+		cli:      cli,
+		repo:     repo,
+	}
+
+	idx.feed = changes.Tail(ctx, "index/"+instance, cli, repo, interval, idx.initialSync, idx.applyChanges)
+
+	return idx
+}
+
+func (idx *Indexer) Wait() {
+	idx.feed.Wait()
+}
+
+// initialSync indexes all studies of the orthanc instance and returns the
+// sequence number of the change log at the time the sync started.
+func (idx *Indexer) initialSync(ctx context.Context) (int, error) {
+	// remember the current position in the change log before listing
+	// the studies so we do not miss changes during the initial sync.
+	last, err := idx.cli.GetLastChange(ctx)
+	if err != nil {
+		return -1, fmt.Errorf("failed to fetch last change: %w", err)
+	}
+
+	studies, err := idx.cli.ListStudies(ctx)
+	if err != nil {
+		return -1, fmt.Errorf("failed to list studies: %w", err)
+	}
+
+	slog.Info("building study index", "instance", idx.instance, "studies", len(studies))
+
+	// a single broken study should not prevent the index from being built.
+	// Failed studies are indexed again once they change.
+	failed := 0
+	for _, study := range studies {
+		if err := idx.indexStudy(ctx, study.ID); err != nil {
+			if ctx.Err() != nil {
+				return -1, ctx.Err()
+			}
+
+			slog.Error("failed to index study", "instance", idx.instance, "id", study.ID, "error", err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		slog.Warn("study index built with errors", "instance", idx.instance, "studies", len(studies), "failed", failed)
+	}
+
+	return last.Last, nil
+}
+
+func (idx *Indexer) applyChanges(ctx context.Context, changes []orthanc.ChangeResult) error {
+	// collect the orthanc IDs of all studies that need to be re-indexed so
+	// each study is only fetched once per page.
+	var studies []string
+
+	for _, change := range changes {
+		switch change.ChangeType {
+		case orthanc.ChangeNewInstance:
+			study, err := idx.cli.GetInstanceStudy(ctx, change.ID)
+			if err != nil {
+				if errors.Is(err, orthanc.ErrNotFound) {
+					// the instance has already been deleted again
+					continue
+				}
+
+				return fmt.Errorf("failed to get study for instance %q: %w", change.ID, err)
+			}
+
+			studies = append(studies, study.ID)
+
+		case orthanc.ChangeNewStudy, orthanc.ChangeStableStudy:
+			studies = append(studies, change.ID)
+
+		case orthanc.ChangeDeleted:
+			switch change.ResourceType {
+			case orthanc.ResourceStudy:
+				if err := idx.repo.DeleteIndexedStudy(ctx, idx.instance, change.ID); err != nil {
+					return err
+				}
+
+				studies = slices.DeleteFunc(studies, func(id string) bool {
+					return id == change.ID
+				})
+
+			case orthanc.ResourceSeries, orthanc.ResourceInstance:
+				study, err := idx.repo.FindIndexedStudyByResource(ctx, idx.instance, change.ID)
+				if err != nil {
+					if errors.Is(err, repo.ErrNotFound) {
+						continue
+					}
+
+					return err
+				}
+
+				studies = append(studies, study.OrthancID)
+			}
+		}
+	}
+
+	slices.Sort(studies)
+	studies = slices.Compact(studies)
+
+	// Failing studies do not block the change feed. They are logged and
+	// indexed again once they change.
+	for _, id := range studies {
+		if err := idx.indexStudy(ctx, id); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			slog.Error("failed to index study", "instance", idx.instance, "id", id, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// indexStudy fetches the study with the given orthanc ID, including all series
+// and instances, and updates the study index. If the study does not exist
+// anymore it is removed from the index.
+func (idx *Indexer) indexStudy(ctx context.Context, id string) error {
+	study, err := idx.cli.GetStudy(ctx, id)
+	if err != nil {
+		if errors.Is(err, orthanc.ErrNotFound) {
+			return idx.repo.DeleteIndexedStudy(ctx, idx.instance, id)
+		}
+
+		return err
+	}
+
+	series, err := idx.cli.GetStudySeries(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to fetch series: %w", err)
+	}
+
+	instances, err := idx.cli.GetStudyInstances(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to fetch instances: %w", err)
+	}
+
+	doc := BuildIndexedStudy(idx.instance, study, series, instances)
+
+	return idx.repo.UpsertIndexedStudy(ctx, doc)
+}
+
+// BuildIndexedStudy creates the denormalized study document from the
+// responses of the orthanc REST API.
+func BuildIndexedStudy(instance string, study orthanc.GetStudyResponse, series orthanc.ListSeriesResponse, instances orthanc.ListInstanceResponse) repo.IndexedStudy {
+	tags := make(map[string]string, len(study.MainDicomTags)+len(study.PatientMainDicomTags))
+	for key, value := range study.PatientMainDicomTags {
+		tags[key] = value
+	}
+	for key, value := range study.MainDicomTags {
+		tags[key] = value
+	}
+
+	doc := repo.IndexedStudy{
+		Instance:    instance,
+		OrthancID:   study.ID,
+		StudyUID:    tags["StudyInstanceUID"],
+		Time:        parseDateAndTime(tags["StudyDate"], tags["StudyTime"]),
+		PatientID:   strings.TrimSpace(tags["PatientID"]),
+		PatientName: strings.TrimSpace(tags["PatientName"]),
+		OwnerName:   strings.TrimSpace(tags["ResponsiblePerson"]),
+		Tags:        tags,
+		IsStable:    study.IsStable,
+		UpdatedAt:   time.Now(),
+	}
+
+	instancesBySeries := make(map[string][]orthanc.GetInstanceResponse)
+	for _, i := range instances {
+		instancesBySeries[i.ParentSeries] = append(instancesBySeries[i.ParentSeries], i)
+	}
+
+	for _, s := range series {
+		if m := s.MainDicomTags["Modality"]; m != "" && !slices.Contains(doc.Modalities, m) {
+			doc.Modalities = append(doc.Modalities, m)
+		}
+
+		seriesDoc := repo.IndexedSeries{
+			OrthancID: s.ID,
+			SeriesUID: s.MainDicomTags["SeriesInstanceUID"],
+			Time:      parseDateAndTime(s.MainDicomTags["SeriesDate"], s.MainDicomTags["SeriesTime"]),
+			Tags:      s.MainDicomTags,
+		}
+
+		seriesInstances := instancesBySeries[s.ID]
+		slices.SortStableFunc(seriesInstances, func(a, b orthanc.GetInstanceResponse) int {
+			return a.IndexInSeries - b.IndexInSeries
+		})
+
+		for _, i := range seriesInstances {
+			seriesDoc.Instances = append(seriesDoc.Instances, repo.IndexedInstance{
+				OrthancID:   i.ID,
+				InstanceUID: i.MainDicomTags["SOPInstanceUID"],
+				Time:        parseDateAndTime(i.MainDicomTags["InstanceCreationDate"], i.MainDicomTags["InstanceCreationTime"]),
+				Tags:        i.MainDicomTags,
+			})
+		}
+
+		doc.Series = append(doc.Series, seriesDoc)
+	}
+
+	return doc
+}
+
+func parseDateAndTime(date, tm string) time.Time {
+	dates, err := dicomweb.ParseDA(dicomweb.Tag{VR: "DA", Value: []any{date}})
+	if err != nil || len(dates) == 0 {
+		return time.Time{}
+	}
+
+	times, err := dicomweb.ParseTM(dicomweb.Tag{VR: "TM", Value: []any{tm}})
+	if err != nil || len(times) == 0 {
+		return dates[0]
+	}
+
+	return times[0].At(dates[0])
+}
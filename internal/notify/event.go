@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	bridgev1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// EventPublisher publishes event messages. It is implemented by
+// *events.Client.
+type EventPublisher interface {
+	Publish(ctx context.Context, msg proto.Message) error
+}
+
+// Event hands messages over to the notification service by publishing a
+// ShareDeliveryEvent.
+type Event struct {
+	publisher EventPublisher
+}
+
+// NewEvent returns a new notifier that publishes messages using publisher.
+func NewEvent(publisher EventPublisher) *Event {
+	return &Event{
+		publisher: publisher,
+	}
+}
+
+// Notify implements Notifier.
+func (e *Event) Notify(ctx context.Context, msg Message) (Status, error) {
+	evt := &bridgev1.ShareDeliveryEvent{
+		Token:     msg.Token,
+		Recipient: msg.Recipient,
+		Channel:   ChannelProto(msg.Channel),
+		Subject:   msg.Subject,
+		Body:      msg.Body,
+		ViewerUrl: msg.ViewerURL,
+	}
+
+	if !msg.ExpiresAt.IsZero() {
+		evt.ExpireTime = timestamppb.New(msg.ExpiresAt)
+	}
+
+	if err := e.publisher.Publish(ctx, evt); err != nil {
+		return StatusFailed, fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	return StatusQueued, nil
+}
+
+// ChannelProto converts ch to its protobuf representation.
+func ChannelProto(ch Channel) bridgev1.ShareDeliveryChannel {
+	switch ch {
+	case ChannelEmail:
+		return bridgev1.ShareDeliveryChannel_SHARE_DELIVERY_CHANNEL_EMAIL
+	case ChannelSMS:
+		return bridgev1.ShareDeliveryChannel_SHARE_DELIVERY_CHANNEL_SMS
+	default:
+		return bridgev1.ShareDeliveryChannel_SHARE_DELIVERY_CHANNEL_UNSPECIFIED
+	}
+}
+
+// StatusProto converts s to its protobuf representation.
+func StatusProto(s Status) bridgev1.ShareDeliveryStatus {
+	switch s {
+	case StatusSent:
+		return bridgev1.ShareDeliveryStatus_SHARE_DELIVERY_STATUS_SENT
+	case StatusQueued:
+		return bridgev1.ShareDeliveryStatus_SHARE_DELIVERY_STATUS_QUEUED
+	case StatusFailed:
+		return bridgev1.ShareDeliveryStatus_SHARE_DELIVERY_STATUS_FAILED
+	default:
+		return bridgev1.ShareDeliveryStatus_SHARE_DELIVERY_STATUS_UNSPECIFIED
+	}
+}
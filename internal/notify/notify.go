@@ -0,0 +1,119 @@
+// Package notify delivers study share messages to recipients.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Channel is the channel used to reach a recipient.
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	ChannelSMS   Channel = "sms"
+)
+
+// Status describes the result of a successful delivery.
+type Status string
+
+const (
+	// StatusSent is returned if the message has been delivered to the
+	// recipient's mail server.
+	StatusSent Status = "sent"
+
+	// StatusQueued is returned if the message has been handed over to a
+	// different service for delivery.
+	StatusQueued Status = "queued"
+
+	// StatusFailed is not returned by notifiers but used to record failed
+	// deliveries.
+	StatusFailed Status = "failed"
+)
+
+// Message is a message that should be delivered to a single recipient.
+type Message struct {
+	Channel   Channel
+	Recipient string
+	Subject   string
+	Body      string
+
+	// Token and ViewerURL identify the study share the message is about.
+	Token     string
+	ViewerURL string
+	ExpiresAt time.Time
+}
+
+// Notifier delivers messages.
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) (Status, error)
+}
+
+// ChannelFor returns the channel used to reach recipient. Recipients are
+// either e-mail addresses or phone numbers.
+func ChannelFor(recipient string) (Channel, error) {
+	if strings.Contains(recipient, "@") {
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return "", fmt.Errorf("invalid e-mail address %q: %w", recipient, err)
+		}
+
+		return ChannelEmail, nil
+	}
+
+	digits := 0
+	for idx, r := range recipient {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && idx == 0:
+		case r == ' ' || r == '-' || r == '/' || r == '(' || r == ')':
+		default:
+			return "", fmt.Errorf("invalid phone number %q", recipient)
+		}
+	}
+
+	if digits < 4 {
+		return "", fmt.Errorf("invalid phone number %q", recipient)
+	}
+
+	return ChannelSMS, nil
+}
+
+// Dispatcher delivers messages using the notifier registered for the
+// message channel.
+type Dispatcher struct {
+	notifiers map[Channel]Notifier
+}
+
+// NewDispatcher returns a new dispatcher without any registered notifiers.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		notifiers: make(map[Channel]Notifier),
+	}
+}
+
+// Register registers n for ch. Register must not be called after the
+// dispatcher is in use.
+func (d *Dispatcher) Register(ch Channel, n Notifier) {
+	d.notifiers[ch] = n
+}
+
+// Supports reports whether a notifier is registered for ch.
+func (d *Dispatcher) Supports(ch Channel) bool {
+	_, ok := d.notifiers[ch]
+
+	return ok
+}
+
+// Notify implements Notifier.
+func (d *Dispatcher) Notify(ctx context.Context, msg Message) (Status, error) {
+	n, ok := d.notifiers[msg.Channel]
+	if !ok {
+		return StatusFailed, fmt.Errorf("no notifier configured for channel %q", msg.Channel)
+	}
+
+	return n.Notify(ctx, msg)
+}
@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTP delivers e-mail messages using an SMTP server.
+type SMTP struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTP returns a new SMTP notifier. If username is empty, messages are
+// sent without authentication.
+func NewSMTP(host string, port int, username, password, from string) *SMTP {
+	if port == 0 {
+		port = 587
+	}
+
+	s := &SMTP{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		from: from,
+	}
+
+	if username != "" {
+		s.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return s
+}
+
+// Notify implements Notifier.
+func (s *SMTP) Notify(ctx context.Context, msg Message) (Status, error) {
+	if msg.Channel != ChannelEmail {
+		return StatusFailed, fmt.Errorf("unsupported channel %q", msg.Channel)
+	}
+
+	to, err := mail.ParseAddress(msg.Recipient)
+	if err != nil {
+		return StatusFailed, fmt.Errorf("invalid e-mail address %q: %w", msg.Recipient, err)
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "From: %s\r\n", s.from)
+	fmt.Fprintf(buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(msg.Body)
+
+	// smtp.SendMail does not support contexts so the message is sent in
+	// the background and abandoned if ctx is cancelled.
+	errc := make(chan error, 1)
+	go func() {
+		errc <- smtp.SendMail(s.addr, s.auth, s.from, []string{to.Address}, buf.Bytes())
+	}()
+
+	select {
+	case err := <-errc:
+		if err != nil {
+			return StatusFailed, fmt.Errorf("failed to send e-mail: %w", err)
+		}
+
+		return StatusSent, nil
+
+	case <-ctx.Done():
+		return StatusFailed, ctx.Err()
+	}
+}
@@ -0,0 +1,57 @@
+package orthanc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/ucarion/urlpath"
+)
+
+var (
+	changesList = urlpath.New("/changes")
+)
+
+// Change types reported by the /changes endpoint.
+const (
+	ChangeNewInstance  = "NewInstance"
+	ChangeNewSeries    = "NewSeries"
+	ChangeNewStudy     = "NewStudy"
+	ChangeNewPatient   = "NewPatient"
+	ChangeStableSeries = "StableSeries"
+	ChangeStableStudy  = "StableStudy"
+	ChangeDeleted      = "Deleted"
+)
+
+// Resource types reported by the /changes endpoint.
+const (
+	ResourcePatient  = "Patient"
+	ResourceStudy    = "Study"
+	ResourceSeries   = "Series"
+	ResourceInstance = "Instance"
+)
+
+// GetChanges returns the changes from orthanc's change log. Use WithSince
+// and WithLimit to page through the log.
+func (c *Client) GetChanges(ctx context.Context, opts ...QueryOption) (res ChangesResult, err error) {
+	if err := c.doRequest(ctx, http.MethodGet, changesList, nil, opts, nil, &res); err != nil {
+		return ChangesResult{}, err
+	}
+
+	return res, nil
+}
+
+// GetLastChange returns the most recent entry in orthanc's change log.
+func (c *Client) GetLastChange(ctx context.Context) (res ChangesResult, err error) {
+	opts := []QueryOption{
+		func(q url.Values) {
+			q.Set("last", "")
+		},
+	}
+
+	if err := c.doRequest(ctx, http.MethodGet, changesList, nil, opts, nil, &res); err != nil {
+		return ChangesResult{}, err
+	}
+
+	return res, nil
+}
@@ -28,8 +28,28 @@ type ClientOption func(c *Client)
 
 var (
 	ErrMissingParameters = errors.New("missing URL path parameters")
+	ErrNotFound          = errors.New("resource not found")
 )
 
+// ResponseError is returned if orthanc replied with an unexpected HTTP
+// status code.
+type ResponseError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (re *ResponseError) Error() string {
+	return fmt.Sprintf("unexpected response status %d: %s", re.StatusCode, string(re.Body))
+}
+
+func (re *ResponseError) Unwrap() error {
+	if re.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
 func WithHTTPClient(cli HTTPDoer) ClientOption {
 	return func(c *Client) {
 		c.cli = cli
@@ -113,6 +133,24 @@ func (cli *Client) doRequest(ctx context.Context, method string, endpoint urlpat
 
 	defer res.Body.Close()
 
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		body, _ := io.ReadAll(res.Body)
+
+		return &ResponseError{
+			StatusCode: res.StatusCode,
+			Body:       body,
+		}
+	}
+
+	// stream the response body if the caller provided a writer
+	if w, ok := response.(io.Writer); ok {
+		if _, err := io.Copy(w, res.Body); err != nil {
+			return fmt.Errorf("failed to read response body: %w", err)
+		}
+
+		return nil
+	}
+
 	if response != nil {
 		body, err := io.ReadAll(res.Body)
 		if err != nil {
@@ -1,10 +1,13 @@
 package orthanc
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/ucarion/urlpath"
 )
@@ -16,6 +19,8 @@ var (
 	getInstancePreview      = urlpath.New("/instances/:id/preview")
 	getInstanceFramePreview = urlpath.New("/instances/:id/frames/:frame/preview")
 	getInstanceTags         = urlpath.New("/instances/:id/simplified-tags")
+	getInstanceStudy        = urlpath.New("/instances/:id/study")
+	getInstanceContent      = urlpath.New("/instances/:id/content/:tag")
 )
 
 type (
@@ -25,11 +30,15 @@ type (
 		ID            string
 		Type          string
 		FileSize      int
+		IndexInSeries int
 		MainDicomTags map[string]string
+		ParentSeries  string
 	}
 
 	FindInstancesResponse struct {
 		ExpandedFindResponse `json:",inline"`
+
+		ParentSeries string
 	}
 )
 
@@ -57,6 +66,27 @@ func (c *Client) GetInstanceTags(ctx context.Context, id string) (res Simplified
 	return res, nil
 }
 
+// GetInstanceStudy returns the study the instance with the given ID
+// belongs to.
+func (c *Client) GetInstanceStudy(ctx context.Context, id string) (res GetStudyResponse, err error) {
+	if err := c.doRequest(ctx, http.MethodGet, getInstanceStudy, map[string]string{"id": id}, nil, nil, &res); err != nil {
+		return GetStudyResponse{}, err
+	}
+
+	return res, nil
+}
+
+// GetInstanceTagValue returns the raw value of a single DICOM tag of the
+// instance with the given ID. The tag must be in the form "gggg-eeee".
+func (c *Client) GetInstanceTagValue(ctx context.Context, id string, tag string) (string, error) {
+	var res []byte
+	if err := c.doRequest(ctx, http.MethodGet, getInstanceContent, map[string]string{"id": id, "tag": tag}, nil, nil, &res); err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(res), "\x00 "), nil
+}
+
 func (c *Client) FindInstances(ctx context.Context, findOpts ...FindOption) (res []FindInstancesResponse, err error) {
 	req := &FindRequest{
 		CaseSensitive: false,
@@ -85,9 +115,23 @@ const (
 	KindPNG
 	KindJPEG
 	KindAVI
+	KindDICOMDIR
+	KindPDF
 )
 
 func (c *Client) GetRenderedInstance(ctx context.Context, instanceId string, frame int, accept RenderKind) ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	if err := c.StreamRenderedInstance(ctx, instanceId, frame, accept, buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// StreamRenderedInstance is like GetRenderedInstance but copies the
+// response to w instead of reading it into memory.
+func (c *Client) StreamRenderedInstance(ctx context.Context, instanceId string, frame int, accept RenderKind, w io.Writer) error {
 	var (
 		p            urlpath.Path
 		acceptHeader string
@@ -113,10 +157,9 @@ func (c *Client) GetRenderedInstance(ctx context.Context, instanceId string, fra
 		}
 
 	default:
-		return nil, fmt.Errorf("invalid download type")
+		return fmt.Errorf("invalid download type")
 	}
 
-	var response []byte
 	if err := c.doRequest(
 		ctx,
 		http.MethodGet,
@@ -127,15 +170,15 @@ func (c *Client) GetRenderedInstance(ctx context.Context, instanceId string, fra
 		},
 		nil,
 		nil,
-		&response,
+		w,
 		func(r *http.Request) {
 			if acceptHeader != "" {
 				r.Header.Set("Accept", acceptHeader)
 			}
 		},
 	); err != nil {
-		return nil, fmt.Errorf("failed to download instance: %w", err)
+		return fmt.Errorf("failed to download instance: %w", err)
 	}
 
-	return ([]byte)(response), nil
+	return nil
 }
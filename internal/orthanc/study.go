@@ -11,6 +11,9 @@ var (
 	studyList    = urlpath.New("/studies")
 	getStudy     = urlpath.New("/studies/:id")
 	getStudyTags = urlpath.New("/studies/:id/simplified-tags")
+
+	getStudySeries    = urlpath.New("/studies/:id/series")
+	getStudyInstances = urlpath.New("/studies/:id/instances")
 )
 
 type (
@@ -57,6 +60,24 @@ func (c *Client) GetStudyTags(ctx context.Context, id string) (res SimplifiedTag
 	return res, nil
 }
 
+// GetStudySeries returns all series of the study with the given ID.
+func (c *Client) GetStudySeries(ctx context.Context, id string) (res ListSeriesResponse, err error) {
+	if err := c.doRequest(ctx, http.MethodGet, getStudySeries, map[string]string{"id": id}, []QueryOption{WithExpand()}, nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetStudyInstances returns all instances of the study with the given ID.
+func (c *Client) GetStudyInstances(ctx context.Context, id string) (res ListInstanceResponse, err error) {
+	if err := c.doRequest(ctx, http.MethodGet, getStudyInstances, map[string]string{"id": id}, []QueryOption{WithExpand()}, nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
 func (c *Client) FindStudy(ctx context.Context, findOpts ...FindOption) (res []FindStudiesResponse, err error) {
 	req := &FindRequest{
 		CaseSensitive: false,
@@ -0,0 +1,287 @@
+// Package pdf implements a minimal PDF writer that supports text using the
+// standard Helvetica fonts and JPEG images. Images are written as soon as
+// they are added so documents with many images do not need to be kept in
+// memory.
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"strings"
+)
+
+// Page sizes in points.
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Object numbers that are reserved by the writer.
+const (
+	catalogObject = iota + 1
+	pagesObject
+	regularFontObject
+	boldFontObject
+
+	firstFreeObject
+)
+
+// Font selects one of the standard fonts.
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+func (f Font) resourceName() string {
+	if f == HelveticaBold {
+		return "F2"
+	}
+
+	return "F1"
+}
+
+// Image is a JPEG image that has been added to a document.
+type Image struct {
+	Width  int
+	Height int
+
+	object int
+}
+
+// Writer writes a PDF document to an io.Writer.
+type Writer struct {
+	w      *bufio.Writer
+	offset int64
+
+	// offsets holds the byte offset of each object, indexed by the object
+	// number minus one.
+	offsets []int64
+	pages   []int
+	err     error
+}
+
+// NewWriter returns a new writer and writes the document header to w. The
+// document is not complete until Close has been called.
+func NewWriter(w io.Writer) *Writer {
+	pw := &Writer{
+		w:       bufio.NewWriter(w),
+		offsets: make([]int64, firstFreeObject-1),
+	}
+
+	pw.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+
+	for _, font := range []struct {
+		object int
+		name   string
+	}{
+		{regularFontObject, "Helvetica"},
+		{boldFontObject, "Helvetica-Bold"},
+	} {
+		pw.beginObject(font.object)
+		pw.printf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>\n", font.name)
+		pw.endObject()
+	}
+
+	return pw
+}
+
+// AddJPEG adds a JPEG image to the document.
+func (w *Writer) AddJPEG(data []byte) (Image, error) {
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to decode JPEG image: %w", err)
+	}
+
+	colorSpace := "/DeviceRGB"
+	switch cfg.ColorModel {
+	case color.GrayModel:
+		colorSpace = "/DeviceGray"
+	case color.CMYKModel:
+		colorSpace = "/DeviceCMYK"
+	}
+
+	img := Image{
+		Width:  cfg.Width,
+		Height: cfg.Height,
+		object: w.newObject(),
+	}
+
+	w.beginObject(img.object)
+	w.printf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n", cfg.Width, cfg.Height, colorSpace, len(data))
+	w.write(data)
+	w.printf("\nendstream\n")
+	w.endObject()
+
+	return img, w.err
+}
+
+// Page is a single page of a document. Use AddPage to add it to the
+// document once its content is complete.
+type Page struct {
+	Width  float64
+	Height float64
+
+	content bytes.Buffer
+	images  []int
+}
+
+// NewPage returns a new empty A4 page.
+func NewPage() *Page {
+	return &Page{
+		Width:  A4Width,
+		Height: A4Height,
+	}
+}
+
+// Text draws s with its baseline starting at x and y. Coordinates are
+// measured in points from the top-left corner of the page.
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font.resourceName(), size, x, p.Height-y, escape(s))
+}
+
+// Line draws a line from x1, y1 to x2, y2.
+func (p *Page) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, p.Height-y1, x2, p.Height-y2)
+}
+
+// Image draws img into the rectangle at x and y with the given width and
+// height. Coordinates are measured from the top-left corner of the page.
+func (p *Page) Image(img Image, x, y, width, height float64) {
+	p.images = append(p.images, img.object)
+
+	fmt.Fprintf(&p.content, "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", width, height, x, p.Height-y-height, img.object)
+}
+
+// AddPage writes p to the document.
+func (w *Writer) AddPage(p *Page) error {
+	var content bytes.Buffer
+
+	zw := zlib.NewWriter(&content)
+	if _, err := zw.Write(p.content.Bytes()); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	contentObject := w.newObject()
+	w.beginObject(contentObject)
+	w.printf("<< /Length %d /Filter /FlateDecode >>\nstream\n", content.Len())
+	w.write(content.Bytes())
+	w.printf("\nendstream\n")
+	w.endObject()
+
+	var xobjects strings.Builder
+	for _, obj := range p.images {
+		fmt.Fprintf(&xobjects, " /Im%d %d 0 R", obj, obj)
+	}
+
+	pageObject := w.newObject()
+	w.beginObject(pageObject)
+	w.printf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Contents %d 0 R /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> /XObject <<%s >> >> >>\n",
+		pagesObject, p.Width, p.Height, contentObject, regularFontObject, boldFontObject, xobjects.String())
+	w.endObject()
+
+	w.pages = append(w.pages, pageObject)
+
+	return w.err
+}
+
+// Close writes the page tree and the cross-reference table. It does not
+// close the underlying writer.
+func (w *Writer) Close() error {
+	var kids strings.Builder
+	for _, obj := range w.pages {
+		fmt.Fprintf(&kids, "%d 0 R ", obj)
+	}
+
+	w.beginObject(pagesObject)
+	w.printf("<< /Type /Pages /Kids [%s] /Count %d >>\n", kids.String(), len(w.pages))
+	w.endObject()
+
+	w.beginObject(catalogObject)
+	w.printf("<< /Type /Catalog /Pages %d 0 R >>\n", pagesObject)
+	w.endObject()
+
+	xref := w.offset
+	w.printf("xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		w.printf("%010d 00000 n \n", offset)
+	}
+	w.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, catalogObject, xref)
+
+	if w.err != nil {
+		return w.err
+	}
+
+	return w.w.Flush()
+}
+
+func (w *Writer) newObject() int {
+	w.offsets = append(w.offsets, 0)
+
+	return len(w.offsets)
+}
+
+func (w *Writer) beginObject(obj int) {
+	w.offsets[obj-1] = w.offset
+	w.printf("%d 0 obj\n", obj)
+}
+
+func (w *Writer) endObject() {
+	w.printf("endobj\n")
+}
+
+func (w *Writer) printf(format string, args ...any) {
+	w.write(fmt.Appendf(nil, format, args...))
+}
+
+func (w *Writer) write(data []byte) {
+	if w.err != nil {
+		return
+	}
+
+	n, err := w.w.Write(data)
+	w.offset += int64(n)
+	w.err = err
+}
+
+// FitImage returns the size of img scaled to fit into a box of the given
+// size while keeping its aspect ratio.
+func FitImage(img Image, maxWidth, maxHeight float64) (float64, float64) {
+	if img.Width <= 0 || img.Height <= 0 {
+		return 0, 0
+	}
+
+	scale := min(maxWidth/float64(img.Width), maxHeight/float64(img.Height))
+
+	return float64(img.Width) * scale, float64(img.Height) * scale
+}
+
+// escape encodes s using WinAnsiEncoding and escapes it for use in a PDF
+// string. Characters that cannot be encoded are replaced by a question
+// mark.
+func escape(s string) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AddAuditEntries appends entries to the audit trail. Audit entries are
+// never updated or deleted.
+func (r *Repo) AddAuditEntries(ctx context.Context, entries []AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	docs := make([]any, len(entries))
+	for idx, e := range entries {
+		docs[idx] = e
+	}
+
+	if _, err := r.audit.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("failed to perform insert operation: %w", err)
+	}
+
+	return nil
+}
+
+// ListAuditEntries returns all audit entries matching query, most recent
+// first.
+func (r *Repo) ListAuditEntries(ctx context.Context, query AuditQuery) ([]AuditEntry, error) {
+	filter := bson.M{}
+
+	if query.User != "" {
+		filter["user"] = query.User
+	}
+
+	if query.StudyUID != "" {
+		filter["studyUids"] = query.StudyUID
+	}
+
+	if query.Action != "" {
+		filter["action"] = query.Action
+	}
+
+	timeFilter := bson.M{}
+	if !query.From.IsZero() {
+		timeFilter["$gte"] = query.From
+	}
+
+	if !query.To.IsZero() {
+		timeFilter["$lte"] = query.To
+	}
+
+	if len(timeFilter) > 0 {
+		filter["time"] = timeFilter
+	}
+
+	opts := options.Find().SetSort(bson.M{"time": -1})
+
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit))
+	}
+
+	if query.Offset > 0 {
+		opts.SetSkip(int64(query.Offset))
+	}
+
+	res, err := r.audit.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform find operation: %w", err)
+	}
+
+	var result []AuditEntry
+	if err := res.All(ctx, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode BSON documents: %w", err)
+	}
+
+	return result, nil
+}
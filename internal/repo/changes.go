@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type changeFeedState struct {
+	Consumer  string    `bson:"consumer"`
+	Seq       int       `bson:"seq"`
+	UpdatedAt time.Time `bson:"updatedAt"`
+}
+
+// GetChangeSeq returns the sequence number of the last orthanc change that
+// has been processed by the given consumer.
+func (r *Repo) GetChangeSeq(ctx context.Context, consumer string) (int, error) {
+	res := r.changeFeeds.FindOne(ctx, bson.M{"consumer": consumer})
+	if err := res.Err(); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, ErrNotFound
+		}
+
+		return 0, err
+	}
+
+	var state changeFeedState
+	if err := res.Decode(&state); err != nil {
+		return 0, fmt.Errorf("failed to decode BSON document: %w", err)
+	}
+
+	return state.Seq, nil
+}
+
+// SaveChangeSeq stores the sequence number of the last orthanc change that
+// has been processed by the given consumer.
+func (r *Repo) SaveChangeSeq(ctx context.Context, consumer string, seq int) error {
+	_, err := r.changeFeeds.ReplaceOne(
+		ctx,
+		bson.M{"consumer": consumer},
+		changeFeedState{
+			Consumer:  consumer,
+			Seq:       seq,
+			UpdatedAt: time.Now(),
+		},
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store change sequence: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *Repo) UpsertIndexedStudy(ctx context.Context, study IndexedStudy) error {
+	_, err := r.studyIndex.ReplaceOne(
+		ctx,
+		bson.M{
+			"instance":  study.Instance,
+			"orthancId": study.OrthancID,
+		},
+		study,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store indexed study: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repo) DeleteIndexedStudy(ctx context.Context, instance string, orthancId string) error {
+	_, err := r.studyIndex.DeleteOne(ctx, bson.M{
+		"instance":  instance,
+		"orthancId": orthancId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to perform delete operation: %w", err)
+	}
+
+	return nil
+}
+
+// FindIndexedStudyByResource returns the indexed study that either is or
+// contains the orthanc resource (study, series or instance) with the given ID.
+func (r *Repo) FindIndexedStudyByResource(ctx context.Context, instance string, orthancId string) (*IndexedStudy, error) {
+	res := r.studyIndex.FindOne(ctx, bson.M{
+		"instance": instance,
+		"$or": bson.A{
+			bson.M{"orthancId": orthancId},
+			bson.M{"series.orthancId": orthancId},
+			bson.M{"series.instances.orthancId": orthancId},
+		},
+	})
+
+	if err := res.Err(); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	var study IndexedStudy
+	if err := res.Decode(&study); err != nil {
+		return nil, fmt.Errorf("failed to decode BSON document: %w", err)
+	}
+
+	return &study, nil
+}
+
+// QueryIndexedStudies searches the study index and returns all matching
+// studies, most recent first, together with the total number of matches.
+func (r *Repo) QueryIndexedStudies(ctx context.Context, query StudyIndexQuery) ([]IndexedStudy, int64, error) {
+	filter := bson.M{}
+
+	if query.Instance != "" {
+		filter["instance"] = query.Instance
+	}
+
+	if !query.From.IsZero() || !query.To.IsZero() {
+		timeFilter := bson.M{}
+
+		if !query.From.IsZero() {
+			timeFilter["$gte"] = query.From
+		}
+
+		if !query.To.IsZero() {
+			timeFilter["$lte"] = query.To
+		}
+
+		filter["time"] = timeFilter
+	}
+
+	if query.Modality != "" {
+		filter["modalities"] = query.Modality
+	}
+
+	for tagName, values := range query.Tags {
+		conditions := make(bson.A, 0, len(values))
+
+		for _, value := range values {
+			conditions = append(conditions, matchTagValue(value, query.CaseInsensitive))
+		}
+
+		if len(conditions) == 0 {
+			continue
+		}
+
+		filter["tags."+tagName] = bson.M{"$in": conditions}
+	}
+
+	count, err := r.studyIndex.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count indexed studies: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "time", Value: -1}})
+
+	if query.Offset > 0 {
+		opts.SetSkip(int64(query.Offset))
+	}
+
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit))
+	}
+
+	res, err := r.studyIndex.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to perform find operation: %w", err)
+	}
+
+	var result []IndexedStudy
+	if err := res.All(ctx, &result); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode BSON documents: %w", err)
+	}
+
+	return result, count, nil
+}
+
+// matchTagValue returns a value suitable for the $in operator that matches
+// value using DICOM wildcard semantics.
+func matchTagValue(value string, caseInsensitive bool) any {
+	if !caseInsensitive && !strings.ContainsAny(value, "*?") {
+		return value
+	}
+
+	expr := regexp.QuoteMeta(value)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+
+	opts := ""
+	if caseInsensitive {
+		opts = "i"
+	}
+
+	return primitive.Regex{
+		Pattern: "^" + expr + "$",
+		Options: opts,
+	}
+}
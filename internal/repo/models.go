@@ -15,10 +15,19 @@ type Artifact struct {
 	CreatedAt    time.Time            `bson:"createdAt"`
 	ExpiresAt    time.Time            `bson:"expiresAt"`
 	Creator      string               `bson:"creator"`
+	Instance     string               `bson:"instance"`
 	StudyUID     string               `bson:"studyUid"`
 	InstanceUIDs []string             `bson:"instanceUids"`
 	Hash         string               `bson:"hash"`
 	RenderTypes  []orthanc.RenderKind `bson:"renderKinds"`
+
+	// Downloads counts how often the artifact has been downloaded.
+	Downloads    int       `bson:"downloads"`
+	LastDownload time.Time `bson:"lastDownload,omitempty"`
+
+	// UsedLinks holds the nonces of one-time download links that have
+	// already been used.
+	UsedLinks []string `bson:"usedLinks,omitempty"`
 }
 
 type StudyShare struct {
@@ -26,15 +35,199 @@ type StudyShare struct {
 	CreatedAt    time.Time `bson:"createdAt"`
 	ExpiresAt    time.Time `bson:"expiresAt"`
 	Creator      string    `bson:"creator"`
+	Instance     string    `bson:"instance"`
 	StudyUID     string    `bson:"studyUid"`
 	InstanceUIDs []string  `bson:"instanceUids"`
 	Recipients   []string  `bson:"recipients"`
+
+	// SeriesUIDs may be set to share complete series of the study. See
+	// AllowsSeries and AllowsInstance.
+	SeriesUIDs []string `bson:"seriesUids,omitempty"`
+
+	// InstanceSeriesUIDs holds the series of all InstanceUIDs.
+	InstanceSeriesUIDs []string `bson:"instanceSeriesUids,omitempty"`
+
+	// RevokedAt is set if the share has been revoked before it expired.
+	RevokedAt time.Time `bson:"revokedAt,omitempty"`
+
+	// Subject and MessageTemplate are used to deliver the share to the
+	// recipients.
+	Subject         string `bson:"subject,omitempty"`
+	MessageTemplate string `bson:"messageTemplate,omitempty"`
+
+	// Deliveries records each attempt to deliver the share to a recipient.
+	Deliveries []ShareDelivery `bson:"deliveries,omitempty"`
+
+	// Protection is set if the share must be unlocked using a PIN or the
+	// patient's date of birth before it can be used. SecretHash holds the
+	// bcrypt hash of the expected value.
+	Protection string `bson:"protection,omitempty"`
+	SecretHash []byte `bson:"secretHash,omitempty"`
+
+	// MaxUses limits the number of viewer sessions that may be started
+	// using the share. Zero means unlimited. Uses counts the sessions
+	// started so far.
+	MaxUses int `bson:"maxUses,omitempty"`
+	Uses    int `bson:"uses"`
+
+	// FailedUnlocks counts the failed attempts to unlock a protected
+	// share. The share is locked once MaxFailedUnlocks is reached and
+	// must be re-issued.
+	FailedUnlocks int `bson:"failedUnlocks,omitempty"`
+}
+
+// Resource types recorded in ShareAccess.
+const (
+	ShareResourceQIDO     = "qido"
+	ShareResourceWADO     = "wado"
+	ShareResourceRendered = "rendered"
+)
+
+// ShareAccess records a single request authorized by a study share.
+type ShareAccess struct {
+	Token     string    `bson:"token"`
+	Time      time.Time `bson:"time"`
+	ClientIP  string    `bson:"clientIp"`
+	UserAgent string    `bson:"userAgent"`
+	Resource  string    `bson:"resource"`
+	Path      string    `bson:"path"`
+}
+
+// ShareDelivery records the result of delivering a study share to a single
+// recipient.
+type ShareDelivery struct {
+	Recipient string    `bson:"recipient"`
+	Channel   string    `bson:"channel"`
+	Status    string    `bson:"status"`
+	Error     string    `bson:"error,omitempty"`
+	Time      time.Time `bson:"time"`
 }
 
 func (share StudyShare) IsValid() bool {
+	if !share.RevokedAt.IsZero() {
+		return false
+	}
+
 	if share.ExpiresAt.IsZero() {
 		return true
 	}
 
 	return time.Now().Before(share.ExpiresAt)
 }
+
+// StudyShareQuery describes a search for study shares.
+type StudyShareQuery struct {
+	// Creator may be set to only return shares created by the given user.
+	Creator string
+
+	// StudyUID may be set to only return shares of the given study.
+	StudyUID string
+
+	// IncludeInvalid includes expired and revoked shares.
+	IncludeInvalid bool
+}
+
+// Outcomes recorded in AuditEntry.
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeDenied  = "denied"
+	AuditOutcomeFailed  = "failed"
+)
+
+// Actions recorded in AuditEntry.
+const (
+	AuditListStudies      = "listStudies"
+	AuditDownloadStudy    = "downloadStudy"
+	AuditShareStudy       = "shareStudy"
+	AuditUpdateShare      = "updateShare"
+	AuditDownloadArtifact = "downloadArtifact"
+	AuditProxyRequest     = "proxyRequest"
+)
+
+// AuditEntry records an access to patient imaging data.
+type AuditEntry struct {
+	Time time.Time `bson:"time"`
+
+	// User is the ID of the user that performed the action. ShareToken is
+	// set instead for requests authorized by a study share.
+	User       string `bson:"user,omitempty"`
+	ShareToken string `bson:"shareToken,omitempty"`
+
+	Action    string   `bson:"action"`
+	Instance  string   `bson:"instance,omitempty"`
+	StudyUIDs []string `bson:"studyUids,omitempty"`
+	Outcome   string   `bson:"outcome"`
+	Detail    string   `bson:"detail,omitempty"`
+	ClientIP  string   `bson:"clientIp,omitempty"`
+}
+
+type AuditQuery struct {
+	User     string
+	StudyUID string
+	Action   string
+
+	// From and To limit the time range of returned entries if set.
+	From time.Time
+	To   time.Time
+
+	Limit  int
+	Offset int
+}
+
+// IndexedStudy is a denormalized study document, including all series and
+// instances, as stored in the study index.
+type IndexedStudy struct {
+	Instance    string            `bson:"instance"`
+	OrthancID   string            `bson:"orthancId"`
+	StudyUID    string            `bson:"studyUid"`
+	Time        time.Time         `bson:"time"`
+	PatientID   string            `bson:"patientId"`
+	PatientName string            `bson:"patientName"`
+	OwnerName   string            `bson:"ownerName"`
+	Modalities  []string          `bson:"modalities"`
+	Tags        map[string]string `bson:"tags"`
+	Series      []IndexedSeries   `bson:"series"`
+	IsStable    bool              `bson:"isStable"`
+	UpdatedAt   time.Time         `bson:"updatedAt"`
+}
+
+type IndexedSeries struct {
+	OrthancID string            `bson:"orthancId"`
+	SeriesUID string            `bson:"seriesUid"`
+	Time      time.Time         `bson:"time"`
+	Tags      map[string]string `bson:"tags"`
+	Instances []IndexedInstance `bson:"instances"`
+}
+
+type IndexedInstance struct {
+	OrthancID   string            `bson:"orthancId"`
+	InstanceUID string            `bson:"instanceUid"`
+	Time        time.Time         `bson:"time"`
+	Tags        map[string]string `bson:"tags"`
+}
+
+// StudyIndexQuery describes a search in the study index.
+type StudyIndexQuery struct {
+	// Instance limits the search to studies of the given orthanc instance.
+	// If empty, studies of all instances are returned.
+	Instance string
+
+	// From and To may be set to limit the search to studies created within
+	// the specified time range.
+	From time.Time
+	To   time.Time
+
+	// Tags holds DICOM tag filters keyed by the tag name. Values may
+	// contain the wildcard characters '*' and '?'.
+	Tags map[string][]string
+
+	// Modality may be set to only return studies which contain a series
+	// of the given modality.
+	Modality string
+
+	// CaseInsensitive enables case-insensitive matching of tag values.
+	CaseInsensitive bool
+
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Supported values for StudyShare.Protection.
+const (
+	ShareProtectionPIN       = "pin"
+	ShareProtectionBirthDate = "birthDate"
+)
+
+// MaxFailedUnlocks is the total number of failed unlock attempts after
+// which a protected share is locked permanently.
+const MaxFailedUnlocks = 20
+
+// birthDateLayouts lists the date formats accepted when unlocking a share
+// using the patient's date of birth.
+var birthDateLayouts = []string{
+	"20060102",
+	"2006-01-02",
+	"2.1.2006",
+	"2/1/2006",
+	"2.1.06",
+}
+
+// IsProtected reports whether share must be unlocked before it can be used.
+func (share StudyShare) IsProtected() bool {
+	return share.Protection != ""
+}
+
+// IsLocked reports whether share has been locked after too many failed
+// unlock attempts.
+func (share StudyShare) IsLocked() bool {
+	return share.IsProtected() && share.FailedUnlocks >= MaxFailedUnlocks
+}
+
+// VerifySecret reports whether value unlocks share.
+func (share StudyShare) VerifySecret(value string) bool {
+	normalized, err := normalizeShareSecret(share.Protection, value)
+	if err != nil {
+		return false
+	}
+
+	return bcrypt.CompareHashAndPassword(share.SecretHash, []byte(normalized)) == nil
+}
+
+// ProtectStudyShare protects share using the given protection and secret.
+// For ShareProtectionBirthDate the secret is the patient's date of birth.
+func ProtectStudyShare(share *StudyShare, protection string, secret string) error {
+	normalized, err := normalizeShareSecret(protection, secret)
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(normalized), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash secret: %w", err)
+	}
+
+	share.Protection = protection
+	share.SecretHash = hash
+
+	return nil
+}
+
+func normalizeShareSecret(protection string, value string) (string, error) {
+	value = strings.TrimSpace(value)
+
+	switch protection {
+	case ShareProtectionPIN:
+		value = strings.ReplaceAll(value, " ", "")
+		if value == "" {
+			return "", fmt.Errorf("empty PIN")
+		}
+
+		return value, nil
+
+	case ShareProtectionBirthDate:
+		for _, layout := range birthDateLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t.Format("20060102"), nil
+			}
+		}
+
+		return "", fmt.Errorf("invalid date of birth %q", value)
+
+	default:
+		return "", fmt.Errorf("unsupported share protection %q", protection)
+	}
+}
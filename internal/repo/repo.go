@@ -13,11 +13,27 @@ import (
 
 var (
 	ErrNotFound = errors.New("not found")
+
+	// ErrShareExhausted is returned if the maximum number of viewer
+	// sessions of a study share has been reached.
+	ErrShareExhausted = errors.New("maximum number of share uses reached")
+
+	// ErrShareLocked is returned if a protected study share has been
+	// locked after too many failed unlock attempts.
+	ErrShareLocked = errors.New("study share locked after too many failed unlock attempts")
+
+	// ErrLinkUsed is returned if a one-time download link has already
+	// been used.
+	ErrLinkUsed = errors.New("download link has already been used")
 )
 
 type Repo struct {
-	artifacts *mongo.Collection
-	shares    *mongo.Collection
+	artifacts   *mongo.Collection
+	shares      *mongo.Collection
+	shareAccess *mongo.Collection
+	audit       *mongo.Collection
+	studyIndex  *mongo.Collection
+	changeFeeds *mongo.Collection
 }
 
 func New(ctx context.Context, url string, db string) (*Repo, error) {
@@ -31,8 +47,12 @@ func New(ctx context.Context, url string, db string) (*Repo, error) {
 	}
 
 	r := &Repo{
-		artifacts: cli.Database(db).Collection("artifacts"),
-		shares:    cli.Database(db).Collection("shares"),
+		artifacts:   cli.Database(db).Collection("artifacts"),
+		shares:      cli.Database(db).Collection("shares"),
+		shareAccess: cli.Database(db).Collection("shareAccess"),
+		audit:       cli.Database(db).Collection("audit"),
+		studyIndex:  cli.Database(db).Collection("studyIndex"),
+		changeFeeds: cli.Database(db).Collection("changeFeeds"),
 	}
 
 	// setup indexes
@@ -67,6 +87,140 @@ func New(ctx context.Context, url string, db string) (*Repo, error) {
 		return nil, err
 	}
 
+	if _, err := r.shares.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{
+					Key:   "token",
+					Value: 1,
+				},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{
+					Key:   "creator",
+					Value: 1,
+				},
+			},
+		},
+		{
+			Keys: bson.D{
+				{
+					Key:   "studyUid",
+					Value: 1,
+				},
+			},
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.shareAccess.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{
+					Key:   "token",
+					Value: 1,
+				},
+				{
+					Key:   "time",
+					Value: -1,
+				},
+			},
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.audit.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{
+					Key:   "time",
+					Value: -1,
+				},
+			},
+		},
+		{
+			Keys: bson.D{
+				{
+					Key:   "user",
+					Value: 1,
+				},
+				{
+					Key:   "time",
+					Value: -1,
+				},
+			},
+		},
+		{
+			Keys: bson.D{
+				{
+					Key:   "studyUids",
+					Value: 1,
+				},
+				{
+					Key:   "time",
+					Value: -1,
+				},
+			},
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.studyIndex.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{
+					Key:   "instance",
+					Value: 1,
+				},
+				{
+					Key:   "orthancId",
+					Value: 1,
+				},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{
+					Key:   "studyUid",
+					Value: 1,
+				},
+			},
+		},
+		{
+			Keys: bson.D{
+				{
+					Key:   "time",
+					Value: -1,
+				},
+			},
+		},
+		{
+			Keys: bson.D{
+				{
+					Key:   "series.orthancId",
+					Value: 1,
+				},
+			},
+		},
+		{
+			Keys: bson.D{
+				{
+					Key:   "series.instances.orthancId",
+					Value: 1,
+				},
+			},
+		},
+	}); err != nil {
+		return nil, err
+	}
+
 	return r, nil
 }
 
@@ -125,6 +279,45 @@ func (r *Repo) FindArtifact(ctx context.Context, id string) (*Artifact, error) {
 	return &artifact, nil
 }
 
+// RecordArtifactDownload increments the download counter of the artifact
+// with the given ID. If nonce is set, it is marked as used and ErrLinkUsed
+// is returned if it has been used before.
+func (r *Repo) RecordArtifactDownload(ctx context.Context, id string, nonce string) (*Artifact, error) {
+	filter := bson.M{"artifactId": id}
+	update := bson.M{
+		"$inc": bson.M{"downloads": 1},
+		"$set": bson.M{"lastDownload": time.Now()},
+	}
+
+	if nonce != "" {
+		filter["usedLinks"] = bson.M{"$ne": nonce}
+		update["$addToSet"] = bson.M{"usedLinks": nonce}
+	}
+
+	res := r.artifacts.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After))
+	if err := res.Err(); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+
+		// distinguish between unknown artifacts and used links
+		if nonce != "" {
+			if _, err := r.FindArtifact(ctx, id); err == nil {
+				return nil, ErrLinkUsed
+			}
+		}
+
+		return nil, ErrNotFound
+	}
+
+	var artifact Artifact
+	if err := res.Decode(&artifact); err != nil {
+		return nil, fmt.Errorf("failed to decode BSON document: %w", err)
+	}
+
+	return &artifact, nil
+}
+
 func (r *Repo) FindCleanupCandidates(ctx context.Context, threshold time.Time) ([]Artifact, error) {
 	res, err := r.artifacts.Find(ctx, bson.M{
 		"expiresAt": bson.M{
@@ -198,3 +391,176 @@ func (r *Repo) CreateStudyShare(ctx context.Context, share StudyShare) error {
 
 	return nil
 }
+
+func (r *Repo) ListStudyShares(ctx context.Context, query StudyShareQuery) ([]StudyShare, error) {
+	filter := bson.M{}
+
+	if query.Creator != "" {
+		filter["creator"] = query.Creator
+	}
+
+	if query.StudyUID != "" {
+		filter["studyUid"] = query.StudyUID
+	}
+
+	if !query.IncludeInvalid {
+		filter["revokedAt"] = bson.M{
+			"$exists": false,
+		}
+
+		// shares without an expiration time are stored with a zero time
+		filter["$or"] = bson.A{
+			bson.M{"expiresAt": bson.M{"$gt": time.Now()}},
+			bson.M{"expiresAt": time.Time{}},
+		}
+	}
+
+	res, err := r.shares.Find(ctx, filter, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform find operation: %w", err)
+	}
+
+	var result []StudyShare
+	if err := res.All(ctx, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode BSON documents: %w", err)
+	}
+
+	return result, nil
+}
+
+// RevokeStudyShare marks the share as revoked and returns the updated
+// share.
+func (r *Repo) RevokeStudyShare(ctx context.Context, token string) (*StudyShare, error) {
+	return r.updateStudyShare(ctx, token, bson.M{
+		"$set": bson.M{
+			"revokedAt": time.Now(),
+		},
+	})
+}
+
+// UpdateStudyShareExpiry updates the expiration time of the share and
+// returns the updated share.
+func (r *Repo) UpdateStudyShareExpiry(ctx context.Context, token string, expiresAt time.Time) (*StudyShare, error) {
+	return r.updateStudyShare(ctx, token, bson.M{
+		"$set": bson.M{
+			"expiresAt": expiresAt,
+		},
+	})
+}
+
+// AddStudyShareDeliveries records the results of delivering the share with
+// the given token and returns the updated share.
+func (r *Repo) AddStudyShareDeliveries(ctx context.Context, token string, deliveries []ShareDelivery) (*StudyShare, error) {
+	return r.updateStudyShare(ctx, token, bson.M{
+		"$push": bson.M{
+			"deliveries": bson.M{
+				"$each": deliveries,
+			},
+		},
+	})
+}
+
+// StartStudyShareSession counts a new viewer session for the share with the
+// given token. ErrShareExhausted is returned if the share has already been
+// used MaxUses times.
+func (r *Repo) StartStudyShareSession(ctx context.Context, token string) (*StudyShare, error) {
+	share, err := r.updateStudyShareWhere(ctx, bson.M{
+		"token": token,
+		"$or": bson.A{
+			bson.M{"maxUses": bson.M{"$exists": false}},
+			bson.M{"maxUses": 0},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$uses", "$maxUses"}}},
+		},
+	}, bson.M{
+		"$inc": bson.M{
+			"uses": 1,
+		},
+	})
+
+	if errors.Is(err, ErrNotFound) {
+		// distinguish between unknown and exhausted shares
+		if _, err := r.GetStudyShare(ctx, token); err != nil {
+			return nil, err
+		}
+
+		return nil, ErrShareExhausted
+	}
+
+	return share, err
+}
+
+// ReserveStudyShareUnlock counts an attempt to unlock the protected share
+// with the given token before the secret is verified. ErrShareLocked is
+// returned if the share already reached MaxFailedUnlocks failed attempts.
+// Successful attempts must be given back using ReleaseStudyShareUnlock.
+func (r *Repo) ReserveStudyShareUnlock(ctx context.Context, token string) (*StudyShare, error) {
+	share, err := r.updateStudyShareWhere(ctx, bson.M{
+		"token": token,
+		"$or": bson.A{
+			bson.M{"failedUnlocks": bson.M{"$exists": false}},
+			bson.M{"failedUnlocks": bson.M{"$lt": MaxFailedUnlocks}},
+		},
+	}, bson.M{
+		"$inc": bson.M{
+			"failedUnlocks": 1,
+		},
+	})
+
+	if errors.Is(err, ErrNotFound) {
+		// distinguish between unknown and locked shares
+		if _, err := r.GetStudyShare(ctx, token); err != nil {
+			return nil, err
+		}
+
+		return nil, ErrShareLocked
+	}
+
+	return share, err
+}
+
+// ReleaseStudyShareUnlock gives back an unlock attempt reserved using
+// ReserveStudyShareUnlock once the secret has been verified.
+func (r *Repo) ReleaseStudyShareUnlock(ctx context.Context, token string) error {
+	_, err := r.updateStudyShareWhere(ctx, bson.M{
+		"token":         token,
+		"failedUnlocks": bson.M{"$gt": 0},
+	}, bson.M{
+		"$inc": bson.M{
+			"failedUnlocks": -1,
+		},
+	})
+
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repo) updateStudyShare(ctx context.Context, token string, update bson.M) (*StudyShare, error) {
+	return r.updateStudyShareWhere(ctx, bson.M{"token": token}, update)
+}
+
+func (r *Repo) updateStudyShareWhere(ctx context.Context, filter bson.M, update bson.M) (*StudyShare, error) {
+	res := r.shares.FindOneAndUpdate(
+		ctx,
+		filter,
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	if err := res.Err(); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	var share StudyShare
+	if err := res.Decode(&share); err != nil {
+		return nil, fmt.Errorf("failed to decode BSON document: %w", err)
+	}
+
+	return &share, nil
+}
@@ -0,0 +1,41 @@
+package repo
+
+import "slices"
+
+// IsScoped reports whether share is restricted to some series or instances
+// of the study.
+func (share StudyShare) IsScoped() bool {
+	return len(share.SeriesUIDs) > 0 || len(share.InstanceUIDs) > 0
+}
+
+// AllowsSeries reports whether at least one instance of the series may be
+// accessed.
+func (share StudyShare) AllowsSeries(series string) bool {
+	if !share.IsScoped() || slices.Contains(share.SeriesUIDs, series) {
+		return true
+	}
+
+	// shares created before series scopes were supported do not know the
+	// series of their instances.
+	if len(share.InstanceUIDs) > 0 && len(share.InstanceSeriesUIDs) == 0 {
+		return true
+	}
+
+	return slices.Contains(share.InstanceSeriesUIDs, series)
+}
+
+// AllowsCompleteSeries reports whether all instances of the series may be
+// accessed.
+func (share StudyShare) AllowsCompleteSeries(series string) bool {
+	return !share.IsScoped() || slices.Contains(share.SeriesUIDs, series)
+}
+
+// AllowsInstance reports whether the instance may be accessed. series may
+// be empty if unknown.
+func (share StudyShare) AllowsInstance(series, instance string) bool {
+	if !share.IsScoped() || slices.Contains(share.InstanceUIDs, instance) {
+		return true
+	}
+
+	return series != "" && slices.Contains(share.SeriesUIDs, series)
+}
@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RecordShareAccess stores a request authorized by a study share.
+func (r *Repo) RecordShareAccess(ctx context.Context, access ShareAccess) error {
+	if _, err := r.shareAccess.InsertOne(ctx, access); err != nil {
+		return fmt.Errorf("failed to perform insert operation: %w", err)
+	}
+
+	return nil
+}
+
+// ListShareAccess returns the access history of the share with the given
+// token, most recent first. If limit is greater than zero, at most limit
+// records are returned.
+func (r *Repo) ListShareAccess(ctx context.Context, token string, limit int) ([]ShareAccess, error) {
+	opts := options.Find().SetSort(bson.M{"time": -1})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	res, err := r.shareAccess.Find(ctx, bson.M{"token": token}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform find operation: %w", err)
+	}
+
+	var result []ShareAccess
+	if err := res.All(ctx, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode BSON documents: %w", err)
+	}
+
+	return result, nil
+}
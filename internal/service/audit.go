@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+
+	"github.com/bufbuild/connect-go"
+	v1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1"
+	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1/orthanc_bridgev1connect"
+	"github.com/tierklinik-dobersberg/apis/pkg/auth"
+	bridgev1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1/bridgev1connect"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/audit"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/config"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultAuditLimit is used if ListAuditEntries does not specify a limit.
+const defaultAuditLimit = 100
+
+// auditedProcedures maps the RPCs that access patient imaging data to the
+// action recorded in the audit trail.
+var auditedProcedures = map[string]string{
+	orthanc_bridgev1connect.OrthancBridgeListStudiesProcedure:       repo.AuditListStudies,
+	orthanc_bridgev1connect.OrthancBridgeDownloadStudyProcedure:     repo.AuditDownloadStudy,
+	orthanc_bridgev1connect.OrthancBridgeShareStudyProcedure:        repo.AuditShareStudy,
+	bridgev1connect.StudySearchServiceListFederatedStudiesProcedure: repo.AuditListStudies,
+	bridgev1connect.ShareServiceCreateShareProcedure:                repo.AuditShareStudy,
+	bridgev1connect.ExportServiceCreateExportJobProcedure:           repo.AuditDownloadStudy,
+	bridgev1connect.ShareServiceRevokeShareProcedure:                repo.AuditUpdateShare,
+	bridgev1connect.ShareServiceUpdateShareExpirationProcedure:      repo.AuditUpdateShare,
+}
+
+// NewAuditInterceptor returns an interceptor that records calls to RPCs
+// accessing patient imaging data in the audit trail. It must be installed
+// before the authentication interceptor so calls denied by it are recorded
+// as well.
+func NewAuditInterceptor(p *config.Providers) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			action, ok := auditedProcedures[req.Spec().Procedure]
+			if !ok {
+				return next(ctx, req)
+			}
+
+			res, err := next(ctx, req)
+
+			instance := instanceName(p, req)
+			if req.Spec().Procedure == bridgev1connect.StudySearchServiceListFederatedStudiesProcedure {
+				instance = AllInstances
+			}
+
+			entry := repo.AuditEntry{
+				Action:    action,
+				Instance:  instance,
+				StudyUIDs: auditStudies(req, res),
+				Outcome:   repo.AuditOutcomeSuccess,
+				ClientIP:  audit.RemoteIP(req.Header(), req.Peer().Addr),
+			}
+
+			// the authentication interceptor runs after this one so the
+			// user is taken from the same headers it uses.
+			if remote, err := auth.RemoteHeaderExtractor(ctx, req); err == nil {
+				entry.User = remote.ID
+			}
+
+			if err != nil {
+				entry.Outcome = repo.AuditOutcomeFailed
+				entry.Detail = err.Error()
+
+				switch connect.CodeOf(err) {
+				case connect.CodePermissionDenied, connect.CodeUnauthenticated:
+					entry.Outcome = repo.AuditOutcomeDenied
+				}
+			}
+
+			p.Audit.Record(entry)
+
+			return res, err
+		}
+	}
+}
+
+// auditStudies returns the study UIDs accessed by an RPC.
+func auditStudies(req connect.AnyRequest, res connect.AnyResponse) []string {
+	if r, ok := req.Any().(interface{ GetStudyUid() string }); ok && r.GetStudyUid() != "" {
+		return []string{r.GetStudyUid()}
+	}
+
+	if res == nil {
+		return nil
+	}
+
+	switch msg := res.Any().(type) {
+	case *v1.ListStudiesResponse:
+		uids := make([]string, len(msg.Studies))
+		for idx, s := range msg.Studies {
+			uids[idx] = s.StudyUid
+		}
+
+		return uids
+
+	case *bridgev1.ListFederatedStudiesResponse:
+		uids := make([]string, len(msg.Studies))
+		for idx, s := range msg.Studies {
+			uids[idx] = s.GetStudy().GetStudyUid()
+		}
+
+		return uids
+
+	case interface{ GetShare() *bridgev1.Share }:
+		if uid := msg.GetShare().GetStudyUid(); uid != "" {
+			return []string{uid}
+		}
+	}
+
+	return nil
+}
+
+// AuditService implements the bridgev1connect.AuditServiceHandler
+// interface.
+type AuditService struct {
+	bridgev1connect.UnimplementedAuditServiceHandler
+
+	*config.Providers
+}
+
+func NewAuditService(p *config.Providers) *AuditService {
+	return &AuditService{
+		Providers: p,
+	}
+}
+
+func (svc *AuditService) ListAuditEntries(ctx context.Context, req *connect.Request[bridgev1.ListAuditEntriesRequest]) (*connect.Response[bridgev1.ListAuditEntriesResponse], error) {
+	query := repo.AuditQuery{
+		User:     req.Msg.User,
+		StudyUID: req.Msg.StudyUid,
+		Action:   req.Msg.Action,
+		Limit:    int(req.Msg.Limit),
+		Offset:   int(req.Msg.Offset),
+	}
+
+	if query.Limit == 0 {
+		query.Limit = defaultAuditLimit
+	}
+
+	if req.Msg.From.IsValid() {
+		query.From = req.Msg.From.AsTime()
+	}
+
+	if req.Msg.To.IsValid() {
+		query.To = req.Msg.To.AsTime()
+	}
+
+	entries, err := svc.Repo.ListAuditEntries(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &bridgev1.ListAuditEntriesResponse{
+		Entries: make([]*bridgev1.AuditEntry, len(entries)),
+	}
+
+	for idx, e := range entries {
+		res.Entries[idx] = &bridgev1.AuditEntry{
+			Time:       timestamppb.New(e.Time),
+			User:       e.User,
+			ShareToken: e.ShareToken,
+			Action:     e.Action,
+			Instance:   e.Instance,
+			StudyUids:  e.StudyUIDs,
+			Outcome:    auditOutcomeProto(e.Outcome),
+			Detail:     e.Detail,
+			ClientIp:   e.ClientIP,
+		}
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func auditOutcomeProto(outcome string) bridgev1.AuditOutcome {
+	switch outcome {
+	case repo.AuditOutcomeSuccess:
+		return bridgev1.AuditOutcome_AUDIT_OUTCOME_SUCCESS
+	case repo.AuditOutcomeDenied:
+		return bridgev1.AuditOutcome_AUDIT_OUTCOME_DENIED
+	case repo.AuditOutcomeFailed:
+		return bridgev1.AuditOutcome_AUDIT_OUTCOME_FAILED
+	default:
+		return bridgev1.AuditOutcome_AUDIT_OUTCOME_UNSPECIFIED
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/tierklinik-dobersberg/apis/pkg/auth"
+	bridgev1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1/bridgev1connect"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/config"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/export"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type DownloadService struct {
+	bridgev1connect.UnimplementedDownloadServiceHandler
+
+	*config.Providers
+}
+
+func NewDownloadService(p *config.Providers) *DownloadService {
+	return &DownloadService{
+		Providers: p,
+	}
+}
+
+func (svc *DownloadService) CreateDownloadLink(ctx context.Context, req *connect.Request[bridgev1.CreateDownloadLinkRequest]) (*connect.Response[bridgev1.CreateDownloadLinkResponse], error) {
+	id, err := artifactID(req.Msg.Artifact)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	artifact, err := svc.Repo.FindArtifact(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		}
+
+		return nil, err
+	}
+
+	remote := auth.From(ctx)
+	if remote == nil || (remote.ID != artifact.Creator && !remote.Admin) {
+		return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("artifact has been created by a different user"))
+	}
+
+	opts := export.LinkOptions{
+		OneTime: req.Msg.OneTime,
+	}
+
+	if req.Msg.ValidDuration != nil {
+		opts.ValidUntil = time.Now().Add(req.Msg.ValidDuration.AsDuration())
+	}
+
+	link, expires, err := svc.Artifacts.DownloadLink(svc.Config.PublicURL, *artifact, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&bridgev1.CreateDownloadLinkResponse{
+		DownloadLink: link,
+		ExpireTime:   timestamppb.New(expires),
+		Artifact:     artifactProto(*artifact),
+	}), nil
+}
+
+// artifactID returns the artifact ID from either an ID or a download link.
+func artifactID(value string) (string, error) {
+	if !strings.Contains(value, "/") {
+		return value, nil
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid download link: %w", err)
+	}
+
+	return path.Base(u.Path), nil
+}
+
+func artifactProto(artifact repo.Artifact) *bridgev1.Artifact {
+	pb := &bridgev1.Artifact{
+		Id:         artifact.ID,
+		CreateTime: timestamppb.New(artifact.CreatedAt),
+		ExpireTime: timestamppb.New(artifact.ExpiresAt),
+		Creator:    artifact.Creator,
+		Instance:   artifact.Instance,
+		StudyUid:   artifact.StudyUID,
+		Downloads:  int32(artifact.Downloads),
+	}
+
+	if !artifact.LastDownload.IsZero() {
+		pb.LastDownloadTime = timestamppb.New(artifact.LastDownload)
+	}
+
+	return pb
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/tierklinik-dobersberg/apis/pkg/auth"
+	bridgev1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1/bridgev1connect"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/config"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/export"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/orthanc"
+)
+
+// defaultExportTTL is used if an export request does not specify a time to
+// live.
+const defaultExportTTL = 30 * time.Minute
+
+// ExportService implements the bridgev1connect.ExportServiceHandler
+// interface.
+type ExportService struct {
+	bridgev1connect.UnimplementedExportServiceHandler
+
+	*config.Providers
+}
+
+func NewExportService(p *config.Providers) *ExportService {
+	return &ExportService{
+		Providers: p,
+	}
+}
+
+func (svc *ExportService) CreateExportJob(ctx context.Context, req *connect.Request[bridgev1.CreateExportJobRequest]) (*connect.Response[bridgev1.CreateExportJobResponse], error) {
+	clients, err := resolveInstance(svc.Providers, req)
+	if err != nil {
+		return nil, err
+	}
+
+	remote := auth.From(ctx)
+	if remote == nil {
+		return nil, connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("authentication required"))
+	}
+
+	renderKinds := make([]orthanc.RenderKind, len(req.Msg.Formats))
+	for idx, f := range req.Msg.Formats {
+		var v orthanc.RenderKind
+
+		switch f {
+		case bridgev1.ExportFormat_EXPORT_FORMAT_DICOM:
+			v = orthanc.KindDICOM
+		case bridgev1.ExportFormat_EXPORT_FORMAT_JPEG:
+			v = orthanc.KindJPEG
+		case bridgev1.ExportFormat_EXPORT_FORMAT_PNG:
+			v = orthanc.KindPNG
+		case bridgev1.ExportFormat_EXPORT_FORMAT_AVI:
+			v = orthanc.KindAVI
+		case bridgev1.ExportFormat_EXPORT_FORMAT_DICOMDIR:
+			v = orthanc.KindDICOMDIR
+		case bridgev1.ExportFormat_EXPORT_FORMAT_PDF:
+			v = orthanc.KindPDF
+		default:
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("unsupported or unspecified export format: %q", f))
+		}
+
+		renderKinds[idx] = v
+	}
+
+	renderKinds, err = normalizeRenderKinds(renderKinds)
+	if err != nil {
+		return nil, err
+	}
+
+	// validate the options before queuing the job so clients get
+	// notified immediately.
+	if err := svc.Artifacts.ValidateOptions(renderKinds, req.Msg.IncludeViewer); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	ttl := defaultExportTTL
+	if req.Msg.TimeToLive != nil {
+		ttl = req.Msg.TimeToLive.AsDuration()
+	}
+
+	job, err := svc.ExportJobs.Submit(export.ExportOptions{
+		TTL:           ttl,
+		Instance:      clients.Name,
+		StudyUID:      req.Msg.StudyUid,
+		InstanceUIDs:  req.Msg.InstanceUids,
+		Kinds:         renderKinds,
+		IncludeViewer: req.Msg.IncludeViewer,
+		Creator:       remote.ID,
+	})
+	if err != nil {
+		if errors.Is(err, export.ErrQueueFull) {
+			return nil, connect.NewError(connect.CodeResourceExhausted, err)
+		}
+
+		return nil, err
+	}
+
+	return connect.NewResponse(&bridgev1.CreateExportJobResponse{
+		Job: export.JobProto(job),
+	}), nil
+}
+
+func (svc *ExportService) GetExportJob(ctx context.Context, req *connect.Request[bridgev1.GetExportJobRequest]) (*connect.Response[bridgev1.GetExportJobResponse], error) {
+	var userId string
+	if remote := auth.From(ctx); remote != nil {
+		userId = remote.ID
+	}
+
+	job, err := svc.exportJob(req.Msg.Id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&bridgev1.GetExportJobResponse{
+		Job: export.JobProto(job),
+	}), nil
+}
+
+func (svc *ExportService) WatchExportJob(ctx context.Context, req *connect.Request[bridgev1.WatchExportJobRequest], stream *connect.ServerStream[bridgev1.WatchExportJobResponse]) error {
+	// the auth and validation interceptors only handle unary calls so the
+	// remote user is extracted from the request headers.
+	remote, err := auth.RemoteHeaderExtractor(ctx, req)
+	if err != nil || remote.ID == "" {
+		return connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("authentication required"))
+	}
+
+	if req.Msg.Id == "" {
+		return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("missing job id"))
+	}
+
+	if _, err := svc.exportJob(req.Msg.Id, remote.ID); err != nil {
+		return err
+	}
+
+	err = svc.ExportJobs.Watch(ctx, req.Msg.Id, func(job export.Job) error {
+		return stream.Send(&bridgev1.WatchExportJobResponse{
+			Job: export.JobProto(job),
+		})
+	})
+
+	if errors.Is(err, export.ErrJobNotFound) {
+		return connect.NewError(connect.CodeNotFound, err)
+	}
+
+	return err
+}
+
+// exportJob returns the export job with the given ID. Jobs are only
+// visible to their creator.
+func (svc *ExportService) exportJob(id string, userId string) (export.Job, error) {
+	job, err := svc.ExportJobs.Get(id)
+	if err != nil || job.Creator != userId {
+		return export.Job{}, connect.NewError(connect.CodeNotFound, export.ErrJobNotFound)
+	}
+
+	return job, nil
+}
+
+// normalizeRenderKinds sorts and compacts kinds and ensures at least one
+// render kind is requested.
+func normalizeRenderKinds(kinds []orthanc.RenderKind) ([]orthanc.RenderKind, error) {
+	slices.SortFunc(kinds, func(a, b orthanc.RenderKind) int {
+		return int(b) - int(a)
+	})
+	kinds = slices.Compact(kinds)
+
+	if len(kinds) == 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("no valid render kinds specified"))
+	}
+
+	return kinds, nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"sync"
+
+	connect "github.com/bufbuild/connect-go"
+	"github.com/hashicorp/go-multierror"
+	orthanc_bridgev1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1"
+	bridgev1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1/bridgev1connect"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dicomweb"
+)
+
+// AllInstances may be used as the value of InstanceHeader to search
+// all configured Orthanc instances at once.
+const AllInstances = "*"
+
+// FailedInstanceHeader is added to federated ListStudies responses once
+// for each Orthanc instance that could not be searched. The response holds
+// partial results in this case.
+const FailedInstanceHeader = "X-Orthanc-Failed-Instance"
+
+// StudySearchService searches studies on all configured Orthanc instances
+// and reports the instance that holds each study.
+type StudySearchService struct {
+	bridgev1connect.UnimplementedStudySearchServiceHandler
+
+	svc *Service
+}
+
+func NewStudySearchService(svc *Service) *StudySearchService {
+	return &StudySearchService{
+		svc: svc,
+	}
+}
+
+func (s *StudySearchService) ListFederatedStudies(ctx context.Context, req *connect.Request[orthanc_bridgev1.ListStudiesRequest]) (*connect.Response[bridgev1.ListFederatedStudiesResponse], error) {
+	studies, total, failed, err := s.svc.searchAllInstances(ctx, studyQuery(req.Msg))
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&bridgev1.ListFederatedStudiesResponse{
+		Studies:         studies,
+		TotalCount:      total,
+		FailedInstances: failed,
+	}), nil
+}
+
+// listFederatedStudies serves ListStudies requests for AllInstances. Use
+// StudySearchService.ListFederatedStudies to also learn which instance
+// holds each study.
+func (svc *Service) listFederatedStudies(ctx context.Context, qidoReq dicomweb.QIDORequest) (*connect.Response[orthanc_bridgev1.ListStudiesResponse], error) {
+	studies, total, failed, err := svc.searchAllInstances(ctx, qidoReq)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &orthanc_bridgev1.ListStudiesResponse{
+		TotalCount: total,
+	}
+
+	for _, study := range studies {
+		response.Studies = append(response.Studies, study.Study)
+	}
+
+	res := connect.NewResponse(response)
+
+	for _, name := range failed {
+		res.Header().Add(FailedInstanceHeader, name)
+	}
+
+	return res, nil
+}
+
+// searchAllInstances sends qidoReq to all configured Orthanc instances
+// concurrently and merges the results. It returns the requested page of
+// studies, the total number of studies and the names of all instances that
+// could not be searched.
+func (svc *Service) searchAllInstances(ctx context.Context, qidoReq dicomweb.QIDORequest) ([]*bridgev1.FederatedStudy, int64, []string, error) {
+	// Pagination can only be applied after the results have been merged.
+	limit, offset := qidoReq.Limit, qidoReq.Offset
+	qidoReq.Limit = 0
+	qidoReq.Offset = 0
+
+	// Query instances in a stable order with the default instance first
+	// so duplicate studies are always resolved the same way.
+	names := make([]string, 0, len(svc.Providers.Instances))
+	for name := range svc.Providers.Instances {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		switch svc.Config.DefaultInstance {
+		case a:
+			return -1
+		case b:
+			return 1
+		}
+
+		if a < b {
+			return -1
+		}
+
+		return 1
+	})
+
+	results := make([][]*orthanc_bridgev1.Study, len(names))
+	errs := make([]error, len(names))
+
+	var wg sync.WaitGroup
+	for idx, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			results[idx], errs[idx] = svc.loadStudies(ctx, svc.Providers.Instances[name], qidoReq)
+		}()
+	}
+	wg.Wait()
+
+	merr := new(multierror.Error)
+	var failed []string
+
+	// instances maps the UID of each study to the instance that holds it.
+	instances := make(map[string]string)
+	studies := make([]*orthanc_bridgev1.Study, 0)
+
+	for idx, name := range names {
+		if errs[idx] != nil {
+			slog.Error("failed to query orthanc instance for studies", "instance", name, "error", errs[idx])
+			merr.Errors = append(merr.Errors, fmt.Errorf("%s: %w", name, errs[idx]))
+			failed = append(failed, name)
+
+			continue
+		}
+
+		for _, study := range results[idx] {
+			if _, ok := instances[study.StudyUid]; ok {
+				continue
+			}
+
+			instances[study.StudyUid] = name
+			studies = append(studies, study)
+		}
+	}
+
+	if len(merr.Errors) == len(names) && len(names) > 0 {
+		return nil, 0, nil, merr.ErrorOrNil()
+	}
+
+	sort.Stable(
+		sort.Reverse(StudyListByTime(studies)),
+	)
+
+	total := int64(len(studies))
+
+	if offset >= len(studies) {
+		return nil, total, failed, nil
+	}
+
+	studies = studies[offset:]
+
+	if limit > 0 && limit < len(studies) {
+		studies = studies[:limit]
+	}
+
+	result := make([]*bridgev1.FederatedStudy, len(studies))
+	for idx, study := range studies {
+		result[idx] = &bridgev1.FederatedStudy{
+			Study:    study,
+			Instance: instances[study.StudyUid],
+		}
+	}
+
+	return result, total, failed, nil
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/suyashkumar/dicom/pkg/tag"
+	v1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/config"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dicomweb"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// isIndexed returns true if the study index is enabled for the orthanc
+// instance with the given name.
+func (svc *Service) isIndexed(instance string) bool {
+	_, ok := svc.Providers.Indexers[instance]
+
+	return ok
+}
+
+// loadStudies returns all studies matching qidoReq from the study index, if
+// enabled for the instance, or by querying the DICOMweb interface.
+func (svc *Service) loadStudies(ctx context.Context, clients *config.InstanceClients, qidoReq dicomweb.QIDORequest) ([]*v1.Study, error) {
+	if !svc.isIndexed(clients.Name) {
+		return svc.fetchStudies(ctx, clients, qidoReq)
+	}
+
+	studies, _, err := svc.queryIndex(ctx, clients.Name, qidoReq)
+
+	return studies, err
+}
+
+// queryIndex searches the study index using the filters from qidoReq and
+// returns the matching studies and the total number of matches.
+func (svc *Service) queryIndex(ctx context.Context, instance string, qidoReq dicomweb.QIDORequest) ([]*v1.Study, int64, error) {
+	query, err := indexQueryFromQIDO(instance, qidoReq)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res, total, err := svc.Repo.QueryIndexedStudies(ctx, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query study index: %w", err)
+	}
+
+	studies := make([]*v1.Study, len(res))
+	for idx, s := range res {
+		studies[idx] = indexedStudyToProto(s)
+	}
+
+	return studies, total, nil
+}
+
+func indexQueryFromQIDO(instance string, qidoReq dicomweb.QIDORequest) (repo.StudyIndexQuery, error) {
+	query := repo.StudyIndexQuery{
+		Instance:        instance,
+		Tags:            make(map[string][]string),
+		CaseInsensitive: qidoReq.FuzzyMatching,
+		Limit:           qidoReq.Limit,
+		Offset:          qidoReq.Offset,
+	}
+
+	for key, values := range qidoReq.FilterTags {
+		name, ok := dicomweb.TagToName[key]
+		if !ok {
+			name = key
+		}
+
+		switch name {
+		case "StudyDate":
+			if len(values) == 0 {
+				continue
+			}
+
+			from, to, err := parseDateRange(values[0])
+			if err != nil {
+				return query, fmt.Errorf("invalid value for StudyDate: %w", err)
+			}
+
+			query.From, query.To = from, to
+
+		case "ModalitiesInStudy":
+			if len(values) > 0 {
+				query.Modality = values[0]
+			}
+
+		default:
+			query.Tags[name] = values
+		}
+	}
+
+	return query, nil
+}
+
+// parseDateRange parses a DICOM date range matching value. Either side of
+// the range may be omitted.
+func parseDateRange(value string) (time.Time, time.Time, error) {
+	parse := func(s string) (time.Time, error) {
+		if s == "" {
+			return time.Time{}, nil
+		}
+
+		return time.ParseInLocation("20060102", s, time.UTC)
+	}
+
+	fromStr, toStr, isRange := strings.Cut(value, "-")
+	if !isRange {
+		toStr = fromStr
+	}
+
+	from, err := parse(fromStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	to, err := parse(toStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if !to.IsZero() {
+		// include the whole day
+		to = to.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return from, to, nil
+}
+
+func indexedStudyToProto(s repo.IndexedStudy) *v1.Study {
+	study := &v1.Study{
+		StudyUid:    s.StudyUID,
+		Time:        timestamppb.New(s.Time),
+		PatientName: s.PatientName,
+		OwnerName:   s.OwnerName,
+		PatientId:   s.PatientID,
+		Modalities:  s.Modalities,
+		Tags:        indexedTagsToProto(s.Tags),
+	}
+
+	for _, series := range s.Series {
+		seriesPb := &v1.Series{
+			SeriesUid: series.SeriesUID,
+			Time:      timestamppb.New(series.Time),
+			Tags:      indexedTagsToProto(series.Tags),
+		}
+
+		for _, instance := range series.Instances {
+			seriesPb.Instances = append(seriesPb.Instances, &v1.Instance{
+				InstanceUid: instance.InstanceUID,
+				Time:        timestamppb.New(instance.Time),
+				Tags:        indexedTagsToProto(instance.Tags),
+			})
+		}
+
+		study.Series = append(study.Series, seriesPb)
+	}
+
+	return study
+}
+
+// indexedTagsToProto converts tags stored by name in the study index to the
+// same representation as returned by QIDO-RS.
+func indexedTagsToProto(tags map[string]string) []*v1.DICOMTag {
+	result := make([]*v1.DICOMTag, 0, len(tags))
+
+	for name, value := range tags {
+		info, err := tag.FindByKeyword(name)
+		if err != nil {
+			continue
+		}
+
+		vr := ""
+		if len(info.VRs) > 0 {
+			vr = info.VRs[0]
+		}
+
+		var values []*structpb.Value
+		for _, v := range strings.Split(value, `\`) {
+			switch vr {
+			case "PN":
+				values = append(values, structpb.NewStructValue(&structpb.Struct{
+					Fields: map[string]*structpb.Value{
+						"Alphabetic": structpb.NewStringValue(v),
+					},
+				}))
+
+			case "IS", "DS", "US", "UL", "SS", "SL", "FL", "FD":
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					values = append(values, structpb.NewNumberValue(f))
+				}
+
+			default:
+				values = append(values, structpb.NewStringValue(v))
+			}
+		}
+
+		result = append(result, &v1.DICOMTag{
+			Tag:                 fmt.Sprintf("%04X%04X", info.Tag.Group, info.Tag.Element),
+			ValueRepresentation: vr,
+			Value:               values,
+			Name:                name,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Tag < result[j].Tag
+	})
+
+	return result
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+
+	connect "github.com/bufbuild/connect-go"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/config"
+)
+
+// InstanceHeader may be set on requests to select the Orthanc instance
+// that should be used. Requests that have an instance field, like
+// CreateShareRequest, should use the field instead. If neither is set, the
+// configured default instance is used. ListStudies also accepts
+// AllInstances to search every configured instance.
+const InstanceHeader = "X-Orthanc-Instance"
+
+func (svc *Service) instanceFromRequest(req connect.AnyRequest) (*config.InstanceClients, error) {
+	return resolveInstance(svc.Providers, req)
+}
+
+// resolveInstance returns the clients of the instance selected by req.
+func resolveInstance(p *config.Providers, req connect.AnyRequest) (*config.InstanceClients, error) {
+	name := instanceName(p, req)
+
+	clients, ok := p.Instance(name)
+	if !ok {
+		if name == "" {
+			return nil, connect.NewError(connect.CodeUnavailable, fmt.Errorf("no default orthanc instance configured"))
+		}
+
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("orthanc instance %q not found", name))
+	}
+
+	return clients, nil
+}
+
+// instanceName returns the name of the instance selected by the instance
+// field of the request message or the InstanceHeader, falling back to the
+// configured default instance.
+func instanceName(p *config.Providers, req connect.AnyRequest) string {
+	if msg, ok := req.Any().(interface{ GetInstance() string }); ok && msg.GetInstance() != "" {
+		return msg.GetInstance()
+	}
+
+	if name := req.Header().Get(InstanceHeader); name != "" {
+		return name
+	}
+
+	return p.Config.DefaultInstance
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	v1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dicomweb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultLoaderWorkers is used if the StudyLoaderWorkers setting is unset.
+const defaultLoaderWorkers = 8
+
+// studyLoader populates the series and instances of studies returned by a
+// study-level QIDO-RS query.
+//
+// Each study is loaded using exactly two QIDO-RS requests (one for all series
+// and one for all instances of the study), independent of the number of
+// series in the study. Studies are loaded concurrently by a bounded number of
+// workers and an error while loading one study does not affect the others.
+type studyLoader struct {
+	cli           *dicomweb.Client
+	includeFields []string
+	workers       int
+}
+
+// load populates the series of all studies and returns the studies that
+// have been loaded successfully in their original order.
+func (l *studyLoader) load(ctx context.Context, studies []*v1.Study) []*v1.Study {
+	workers := l.workers
+	if workers <= 0 {
+		workers = defaultLoaderWorkers
+	}
+
+	var (
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, workers)
+		failed = make([]bool, len(studies))
+	)
+
+	for idx, study := range studies {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			failed[idx] = true
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := l.loadStudy(ctx, study); err != nil {
+				slog.Info("failed to load study", "id", study.StudyUid, "error", err)
+				failed[idx] = true
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	result := make([]*v1.Study, 0, len(studies))
+	for idx, study := range studies {
+		if !failed[idx] {
+			result = append(result, study)
+		}
+	}
+
+	return result
+}
+
+func (l *studyLoader) loadStudy(ctx context.Context, study *v1.Study) error {
+	series, err := l.cli.Query(ctx, dicomweb.QIDORequest{
+		Type:             dicomweb.Series,
+		StudyInstanceUID: study.StudyUid,
+		IncludeFields:    l.includeFields,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch study series: %w", err)
+	}
+
+	// fetch all instances of the study at once, they are assigned to their
+	// series using the SeriesInstanceUID below.
+	instances, err := l.cli.Query(ctx, dicomweb.QIDORequest{
+		Type:             dicomweb.Instance,
+		StudyInstanceUID: study.StudyUid,
+		IncludeFields:    l.includeFields,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch study instances: %w", err)
+	}
+
+	instancesBySeries := make(map[string][]*v1.Instance)
+	for _, i := range instances {
+		merr := new(multierror.Error)
+
+		seriesUid := parseFirstString(i, dicomweb.SeriesInstanceUID, merr)
+
+		ipb := &v1.Instance{
+			InstanceUid: parseFirstString(i, dicomweb.SOPInstanceUID, merr),
+			Time:        timestamppb.New(parseDateAndTime(i, dicomweb.InstanceCreationDate, dicomweb.InstanceCreationTime, nil)),
+			Tags:        parseTags(i),
+		}
+
+		if err := merr.ErrorOrNil(); err != nil {
+			slog.Error("failed to convert instance", "id", study.StudyUid, "series", seriesUid, "instance", ipb.InstanceUid, "error", err)
+			continue
+		}
+
+		instancesBySeries[seriesUid] = append(instancesBySeries[seriesUid], ipb)
+	}
+
+	for _, s := range series {
+		merr := new(multierror.Error)
+
+		seriesPb := &v1.Series{
+			SeriesUid: parseFirstString(s, dicomweb.SeriesInstanceUID, merr),
+			Time:      timestamppb.New(parseDateAndTime(s, dicomweb.SeriesDate, dicomweb.SeriesTime, nil)),
+			Tags:      parseTags(s),
+		}
+
+		// bail out if there were errors
+		if err := merr.ErrorOrNil(); err != nil {
+			slog.Info("failed to convert series", "id", study.StudyUid, "series", seriesPb.SeriesUid, "error", err)
+			continue
+		}
+
+		seriesPb.Instances = instancesBySeries[seriesPb.SeriesUid]
+
+		study.Series = append(study.Series, seriesPb)
+	}
+
+	return nil
+}
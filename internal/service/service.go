@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-	"net/url"
-	"path"
 	"slices"
 	"sort"
 	"sync"
@@ -18,7 +16,7 @@ import (
 	orthanc_bridgev1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1"
 	v1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1"
 	"github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1/orthanc_bridgev1connect"
-	"github.com/tierklinik-dobersberg/apis/pkg/auth"
+	bridgev1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/config"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dicomweb"
 	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/export"
@@ -38,16 +36,34 @@ type Service struct {
 }
 
 func (svc *Service) watchRecentStudies(ctx context.Context) {
+	clients, ok := svc.Providers.Instance("")
+	if !ok {
+		slog.Error("no default orthanc instance configured, not watching for recent studies")
+		return
+	}
+
+	// recent studies are served from the study index
+	if svc.isIndexed(clients.Name) {
+		return
+	}
+
 	ticker := time.NewTicker(time.Minute * 5)
-	var events <-chan *eventsv1.Event
+	var instanceEvents, stableEvents <-chan *eventsv1.Event
 
 	if svc.Providers.EventClient != nil {
 		var err error
 
-		events, err = svc.Providers.EventClient.SubscribeMessage(ctx, &orthanc_bridgev1.InstanceReceivedEvent{})
+		// refresh once a study is complete. Older bridges only publish
+		// InstanceReceivedEvent so keep listening for it as a fallback.
+		instanceEvents, err = svc.Providers.EventClient.SubscribeMessage(ctx, new(orthanc_bridgev1.InstanceReceivedEvent))
 		if err != nil {
 			slog.Error("failed to subscribe to InstanceReceivedEvent", "error", err)
 		}
+
+		stableEvents, err = svc.Providers.EventClient.SubscribeMessage(ctx, new(bridgev1.StudyStableEvent))
+		if err != nil {
+			slog.Error("failed to subscribe to StudyStableEvent", "error", err)
+		}
 	}
 
 	go func() {
@@ -76,7 +92,7 @@ func (svc *Service) watchRecentStudies(ctx context.Context) {
 
 			qidoReq.FilterTags[dicomweb.StudyDate] = []string{fmt.Sprintf("%s-%s", start.Format("20060102"), now.Format("20060102"))}
 
-			studies, err := svc.fetchStudies(ctx, qidoReq)
+			studies, err := svc.fetchStudies(ctx, clients, qidoReq)
 			if err != nil {
 				slog.Error("failed to fetch recent studies", "error", err)
 			} else {
@@ -89,7 +105,8 @@ func (svc *Service) watchRecentStudies(ctx context.Context) {
 
 			select {
 			case <-ticker.C:
-			case <-events:
+			case <-instanceEvents:
+			case <-stableEvents:
 			}
 		}
 
@@ -106,11 +123,8 @@ func New(ctx context.Context, p *config.Providers) *Service {
 	return svc
 }
 
-func (svc *Service) ListStudies(ctx context.Context, req *connect.Request[v1.ListStudiesRequest]) (*connect.Response[v1.ListStudiesResponse], error) {
-	if svc.DICOMWebClient == nil {
-		return nil, connect.NewError(connect.CodeUnavailable, fmt.Errorf("no dicomweb client configured"))
-	}
-
+// studyQuery translates a ListStudiesRequest into a QIDO study search.
+func studyQuery(m *v1.ListStudiesRequest) dicomweb.QIDORequest {
 	qidoReq := dicomweb.QIDORequest{
 		Type:       dicomweb.Study,
 		FilterTags: make(map[string][]string),
@@ -125,8 +139,6 @@ func (svc *Service) ListStudies(ctx context.Context, req *connect.Request[v1.Lis
 		},
 	}
 
-	m := req.Msg
-
 	if dr := m.GetDateRange(); dr != nil && dr.From != nil && dr.To != nil {
 		from := dr.From.AsTimeInLocation(time.Local)
 		to := dr.To.AsTimeInLocation(time.Local)
@@ -168,17 +180,34 @@ func (svc *Service) ListStudies(ctx context.Context, req *connect.Request[v1.Lis
 		qidoReq.FilterTags[values.Tag] = values.Value
 	}
 
-	res, err := svc.DICOMWebClient.Query(ctx, qidoReq)
+	return qidoReq
+}
+
+func (svc *Service) ListStudies(ctx context.Context, req *connect.Request[v1.ListStudiesRequest]) (*connect.Response[v1.ListStudiesResponse], error) {
+	qidoReq := studyQuery(req.Msg)
+
+	if req.Header().Get(InstanceHeader) == AllInstances {
+		return svc.listFederatedStudies(ctx, qidoReq)
+	}
+
+	clients, err := svc.instanceFromRequest(req)
 	if err != nil {
-		if re, ok := err.(*dicomweb.ResponseError); ok {
-			body, _ := io.ReadAll(re.Response.Body)
-			slog.Error("failed to query for studies", "error", err, "response", string(body))
+		return nil, err
+	}
+
+	if svc.isIndexed(clients.Name) {
+		studies, total, err := svc.queryIndex(ctx, clients.Name, qidoReq)
+		if err != nil {
+			return nil, err
 		}
 
-		return nil, fmt.Errorf("failed to query for studies: %w", err)
+		return connect.NewResponse(&orthanc_bridgev1.ListStudiesResponse{
+			Studies:    studies,
+			TotalCount: total,
+		}), nil
 	}
 
-	studies, err := svc.fetchStudies(ctx, qidoReq)
+	studies, err := svc.fetchStudies(ctx, clients, qidoReq)
 	if err != nil {
 		return nil, err
 	}
@@ -188,16 +217,13 @@ func (svc *Service) ListStudies(ctx context.Context, req *connect.Request[v1.Lis
 		TotalCount: int64(len(studies)),
 	}
 
-	if len(res) > 0 && len(response.Studies) == 0 {
-		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to convert response, no studies available"))
-	}
-
 	return connect.NewResponse(response), nil
 }
 
 func (svc *Service) DownloadStudy(ctx context.Context, req *connect.Request[v1.DownloadStudyRequest]) (*connect.Response[v1.DownloadStudyResponse], error) {
-	if svc.OrthancClient == nil {
-		return nil, connect.NewError(connect.CodeUnavailable, fmt.Errorf("no default orthanc instance configured"))
+	clients, err := svc.instanceFromRequest(req)
+	if err != nil {
+		return nil, err
 	}
 
 	renderKinds := make([]orthanc.RenderKind, len(req.Msg.Types))
@@ -224,23 +250,19 @@ func (svc *Service) DownloadStudy(ctx context.Context, req *connect.Request[v1.D
 		renderKinds[idx] = v
 	}
 
-	// sort and compact
-	slices.SortFunc(renderKinds, func(a, b orthanc.RenderKind) int {
-		return int(b) - int(a)
-	})
-	renderKinds = slices.Compact(renderKinds)
-
-	if len(renderKinds) == 0 {
-		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("no valid render kinds specified"))
+	renderKinds, err = normalizeRenderKinds(renderKinds)
+	if err != nil {
+		return nil, err
 	}
 
-	ttl := time.Minute * 30
+	ttl := defaultExportTTL
 	if req.Msg.TimeToLive != nil {
 		ttl = req.Msg.TimeToLive.AsDuration()
 	}
 
 	archive, err := svc.Artifacts.Export(ctx, export.ExportOptions{
 		TTL:          ttl,
+		Instance:     clients.Name,
 		StudyUID:     req.Msg.StudyUid,
 		InstanceUIDs: req.Msg.InstanceUids,
 		Kinds:        renderKinds,
@@ -249,51 +271,43 @@ func (svc *Service) DownloadStudy(ctx context.Context, req *connect.Request[v1.D
 		return nil, err
 	}
 
-	accessUrl, _ := url.Parse(svc.Config.PublicURL)
-	accessUrl.Path = path.Join(accessUrl.Path, "download", archive.ID)
+	link, expires, err := svc.Artifacts.DownloadLink(svc.Config.PublicURL, archive, export.LinkOptions{})
+	if err != nil {
+		return nil, err
+	}
 
 	return connect.NewResponse(&v1.DownloadStudyResponse{
-		DownloadLink: accessUrl.String(),
-		ExpireTime:   timestamppb.New(archive.ExpiresAt),
+		DownloadLink: link,
+		ExpireTime:   timestamppb.New(expires),
 	}), nil
 }
 
 func (svc *Service) ShareStudy(ctx context.Context, req *connect.Request[v1.ShareStudyRequest]) (*connect.Response[v1.ShareStudyResponse], error) {
-	token := repo.ShareTokenPrefix + export.GetRandomString(48)
-
-	ttl := time.Hour * 24 * 30
-
-	if req.Msg.ValidDuration.IsValid() {
-		ttl = req.Msg.ValidDuration.AsDuration()
+	clients, err := svc.instanceFromRequest(req)
+	if err != nil {
+		return nil, err
 	}
 
-	share := repo.StudyShare{
-		Token:        token,
-		CreatedAt:    time.Now(),
-		Creator:      auth.From(ctx).ID,
-		ExpiresAt:    time.Now().Add(ttl),
-		StudyUID:     req.Msg.StudyUid,
-		InstanceUIDs: req.Msg.InstanceUids,
-	}
+	share := newStudyShare(ctx, clients.Name, req.Msg.StudyUid, req.Msg.InstanceUids, req.Msg.ValidDuration)
 
-	if err := svc.Repo.CreateStudyShare(ctx, share); err != nil {
+	if err := resolveShareScope(ctx, clients, &share); err != nil {
 		return nil, err
 	}
 
-	viewerUrl := fmt.Sprintf("%s/viewer/?StudyInstanceUIDs=%s&token=%s", svc.Config.PublicURL, req.Msg.StudyUid, token)
-
-	if len(share.InstanceUIDs) > 0 {
-		viewerUrl += "&initialSopInstanceUid=" + share.InstanceUIDs[0]
+	if err := svc.Repo.CreateStudyShare(ctx, share); err != nil {
+		return nil, err
 	}
 
 	return connect.NewResponse(&v1.ShareStudyResponse{
-		Token:     token,
-		ViewerUrl: viewerUrl,
+		Token:     share.Token,
+		ViewerUrl: viewerURL(svc.Config.PublicURL, share),
 	}), nil
 }
 
-func (svc *Service) fetchStudies(ctx context.Context, qidoReq dicomweb.QIDORequest) ([]*orthanc_bridgev1.Study, error) {
-	res, err := svc.DICOMWebClient.Query(ctx, qidoReq)
+func (svc *Service) fetchStudies(ctx context.Context, clients *config.InstanceClients, qidoReq dicomweb.QIDORequest) ([]*orthanc_bridgev1.Study, error) {
+	cli := clients.DICOMWebClient
+
+	res, err := cli.Query(ctx, qidoReq)
 	if err != nil {
 		if re, ok := err.(*dicomweb.ResponseError); ok {
 			body, _ := io.ReadAll(re.Response.Body)
@@ -324,64 +338,21 @@ func (svc *Service) fetchStudies(ctx context.Context, qidoReq dicomweb.QIDOReque
 			continue
 		}
 
-		// get series for the study
-		series, err := svc.DICOMWebClient.Query(ctx, dicomweb.QIDORequest{
-			Type:             dicomweb.Series,
-			StudyInstanceUID: study.StudyUid,
-			IncludeFields:    qidoReq.IncludeFields,
-		})
-		if err != nil {
-			slog.Info("failed to fetch study series", "id", study.StudyUid, "error", err)
-			continue
-		}
-
-		for _, s := range series {
-			merr := new(multierror.Error)
-
-			seriesPb := &v1.Series{
-				SeriesUid: parseFirstString(s, dicomweb.SeriesInstanceUID, merr),
-				Time:      timestamppb.New(parseDateAndTime(s, dicomweb.SeriesDate, dicomweb.SeriesTime, nil)),
-				Tags:      parseTags(s),
-			}
-
-			// bail out if there were errors
-			if err := merr.ErrorOrNil(); err != nil {
-				slog.Info("failed to convert series", "id", study.StudyUid, "series", seriesPb.SeriesUid, "error", err)
-				continue
-			}
-
-			instances, err := svc.DICOMWebClient.Query(ctx, dicomweb.QIDORequest{
-				Type:              dicomweb.Instance,
-				StudyInstanceUID:  study.StudyUid,
-				SeriesInstanceUID: seriesPb.SeriesUid,
-				IncludeFields:     qidoReq.IncludeFields,
-			})
-			if err != nil {
-				slog.Info("failed to fetch instances", "id", study.StudyUid, "series", seriesPb.SeriesUid, "error", err)
-				continue
-			}
-
-			for _, i := range instances {
-				merr := new(multierror.Error)
-
-				ipb := &v1.Instance{
-					InstanceUid: parseFirstString(i, dicomweb.SOPInstanceUID, merr),
-					Time:        timestamppb.New(parseDateAndTime(i, dicomweb.InstanceCreationDate, dicomweb.InstanceCreationTime, nil)),
-					Tags:        parseTags(i),
-				}
-
-				if err := merr.ErrorOrNil(); err != nil {
-					slog.Error("failed to convert instance", "id", study.StudyUid, "series", seriesPb.SeriesUid, "instance", ipb.InstanceUid, "error", err)
-					continue
-				}
+		response = append(response, study)
+	}
 
-				seriesPb.Instances = append(seriesPb.Instances, ipb)
-			}
+	// load the series and instances of all studies. Studies that failed
+	// to load are removed from the response.
+	loader := &studyLoader{
+		cli:           cli,
+		includeFields: qidoReq.IncludeFields,
+		workers:       svc.Config.StudyLoaderWorkers,
+	}
 
-			study.Series = append(study.Series, seriesPb)
-		}
+	response = loader.load(ctx, response)
 
-		response = append(response, study)
+	if len(res) > 0 && len(response) == 0 {
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to convert response, no studies available"))
 	}
 
 	// Sort the studies by time
@@ -393,6 +364,27 @@ func (svc *Service) fetchStudies(ctx context.Context, qidoReq dicomweb.QIDOReque
 }
 
 func (svc *Service) ListRecentStudies(ctx context.Context, req *connect.Request[emptypb.Empty]) (*connect.Response[orthanc_bridgev1.ListStudiesResponse], error) {
+	if name := svc.Config.DefaultInstance; svc.isIndexed(name) {
+		studies, total, err := svc.Repo.QueryIndexedStudies(ctx, repo.StudyIndexQuery{
+			Instance: name,
+			From:     time.Now().Add(-7 * 24 * time.Hour),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query study index: %w", err)
+		}
+
+		response := &orthanc_bridgev1.ListStudiesResponse{
+			Studies:    make([]*orthanc_bridgev1.Study, len(studies)),
+			TotalCount: total,
+		}
+
+		for idx, s := range studies {
+			response.Studies[idx] = indexedStudyToProto(s)
+		}
+
+		return connect.NewResponse(response), nil
+	}
+
 	svc.recentStudiesLock.RLock()
 	defer svc.recentStudiesLock.RUnlock()
 
@@ -0,0 +1,545 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/bufbuild/connect-go"
+	"github.com/tierklinik-dobersberg/apis/pkg/auth"
+	bridgev1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1/bridgev1connect"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/config"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dicomweb"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dicomweb/proxy"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/export"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/notify"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/repo"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// defaultShareTTL is used if a share request does not specify how
+	// long the share is valid.
+	defaultShareTTL = 30 * 24 * time.Hour
+
+	// shareDeliveryTimeout limits the time to deliver a share to a single
+	// recipient.
+	shareDeliveryTimeout = 30 * time.Second
+
+	defaultShareSubject  = "Your study images"
+	defaultShareTemplate = `You can view the images of your study until {{ .ExpiresAt.Format "02.01.2006" }} using the following link:
+
+{{ .ViewerURL }}
+`
+)
+
+// shareMessage is passed to share message templates.
+type shareMessage struct {
+	ViewerURL string
+	ExpiresAt time.Time
+	StudyUID  string
+	Recipient string
+}
+
+// TokenInvalidator removes a token from a cache of validated tokens. It is
+// implemented by *proxy.SingelHostProxy.
+type TokenInvalidator interface {
+	InvalidateToken(token string)
+}
+
+// ShareService implements the bridgev1connect.ShareServiceHandler
+// interface.
+type ShareService struct {
+	bridgev1connect.UnimplementedShareServiceHandler
+
+	*config.Providers
+
+	invalidators []TokenInvalidator
+}
+
+// NewShareService returns a new share service. Revoked or updated share
+// tokens are invalidated in all invalidators.
+func NewShareService(p *config.Providers, invalidators ...TokenInvalidator) *ShareService {
+	return &ShareService{
+		Providers:    p,
+		invalidators: invalidators,
+	}
+}
+
+func (svc *ShareService) CreateShare(ctx context.Context, req *connect.Request[bridgev1.CreateShareRequest]) (*connect.Response[bridgev1.CreateShareResponse], error) {
+	clients, err := resolveInstance(svc.Providers, req)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := make([]notify.Channel, len(req.Msg.Recipients))
+	if len(req.Msg.Recipients) > 0 && svc.ShareNotifier == nil {
+		return nil, connect.NewError(connect.CodeUnavailable, fmt.Errorf("share delivery not configured"))
+	}
+
+	for idx, r := range req.Msg.Recipients {
+		ch, err := notify.ChannelFor(r)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+
+		if !svc.ShareNotifier.Supports(ch) {
+			return nil, connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf("share delivery via %s not configured", ch))
+		}
+
+		channels[idx] = ch
+	}
+
+	subject, tmpl := svc.shareDefaults(req.Msg.Subject, req.Msg.MessageTemplate)
+
+	t, err := template.New("").Parse(tmpl)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid message template: %w", err))
+	}
+
+	share := newStudyShare(ctx, clients.Name, req.Msg.StudyUid, req.Msg.InstanceUids, req.Msg.ValidDuration)
+	share.SeriesUIDs = req.Msg.SeriesUids
+
+	if err := resolveShareScope(ctx, clients, &share); err != nil {
+		return nil, err
+	}
+
+	switch v := req.Msg.Protection.(type) {
+	case *bridgev1.CreateShareRequest_Pin:
+		if err := repo.ProtectStudyShare(&share, repo.ShareProtectionPIN, v.Pin); err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+
+	case *bridgev1.CreateShareRequest_RequireBirthDate:
+		if v.RequireBirthDate {
+			birthDate, err := patientBirthDate(ctx, clients, share.StudyUID)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := repo.ProtectStudyShare(&share, repo.ShareProtectionBirthDate, birthDate); err != nil {
+				return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+			}
+		}
+	}
+
+	share.MaxUses = int(req.Msg.MaxUses)
+	share.Recipients = req.Msg.Recipients
+	share.Subject = subject
+	share.MessageTemplate = tmpl
+
+	if err := svc.Repo.CreateStudyShare(ctx, share); err != nil {
+		return nil, err
+	}
+
+	url := viewerURL(svc.Config.PublicURL, share)
+
+	if len(share.Recipients) > 0 {
+		deliveries := svc.deliverShare(ctx, share, url, channels, t)
+
+		updated, err := svc.Repo.AddStudyShareDeliveries(ctx, share.Token, deliveries)
+		if err != nil {
+			slog.Error("failed to record share deliveries", "token", share.Token, "error", err)
+
+			share.Deliveries = deliveries
+		} else {
+			share = *updated
+		}
+	}
+
+	return connect.NewResponse(&bridgev1.CreateShareResponse{
+		Token:     share.Token,
+		ViewerUrl: url,
+		Share:     shareProto(share),
+	}), nil
+}
+
+// shareDefaults returns the subject and message template used to deliver a
+// share.
+func (svc *ShareService) shareDefaults(subject, tmpl string) (string, string) {
+	if cfg := svc.Config.ShareDelivery; cfg != nil {
+		if subject == "" {
+			subject = cfg.Subject
+		}
+
+		if tmpl == "" {
+			tmpl = cfg.Template
+		}
+	}
+
+	if subject == "" {
+		subject = defaultShareSubject
+	}
+
+	if tmpl == "" {
+		tmpl = defaultShareTemplate
+	}
+
+	return subject, tmpl
+}
+
+// deliverShare sends the viewer URL of share to each recipient and returns
+// the delivery results.
+func (svc *ShareService) deliverShare(ctx context.Context, share repo.StudyShare, url string, channels []notify.Channel, t *template.Template) []repo.ShareDelivery {
+	deliveries := make([]repo.ShareDelivery, len(share.Recipients))
+
+	for idx, recipient := range share.Recipients {
+		delivery := repo.ShareDelivery{
+			Recipient: recipient,
+			Channel:   string(channels[idx]),
+			Status:    string(notify.StatusFailed),
+		}
+
+		body := new(bytes.Buffer)
+		err := t.Execute(body, shareMessage{
+			ViewerURL: url,
+			ExpiresAt: share.ExpiresAt,
+			StudyUID:  share.StudyUID,
+			Recipient: recipient,
+		})
+
+		if err == nil {
+			var status notify.Status
+
+			deliveryCtx, cancel := context.WithTimeout(ctx, shareDeliveryTimeout)
+			status, err = svc.ShareNotifier.Notify(deliveryCtx, notify.Message{
+				Channel:   channels[idx],
+				Recipient: recipient,
+				Subject:   share.Subject,
+				Body:      body.String(),
+				Token:     share.Token,
+				ViewerURL: url,
+				ExpiresAt: share.ExpiresAt,
+			})
+			cancel()
+
+			delivery.Status = string(status)
+		} else {
+			err = fmt.Errorf("failed to render message: %w", err)
+		}
+
+		if err != nil {
+			slog.Error("failed to deliver study share", "token", share.Token, "recipient", recipient, "error", err)
+
+			delivery.Error = err.Error()
+		}
+
+		delivery.Time = time.Now()
+		deliveries[idx] = delivery
+	}
+
+	return deliveries
+}
+
+func (svc *ShareService) ListShares(ctx context.Context, req *connect.Request[bridgev1.ListSharesRequest]) (*connect.Response[bridgev1.ListSharesResponse], error) {
+	remote := auth.From(ctx)
+	if remote == nil {
+		return nil, connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("authentication required"))
+	}
+
+	// only admins may list the shares of other users.
+	creator := req.Msg.Creator
+	if !remote.Admin {
+		if creator != "" && creator != remote.ID {
+			return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("not allowed to list shares of other users"))
+		}
+
+		creator = remote.ID
+	}
+
+	shares, err := svc.Repo.ListStudyShares(ctx, repo.StudyShareQuery{
+		Creator:        creator,
+		StudyUID:       req.Msg.StudyUid,
+		IncludeInvalid: req.Msg.IncludeInvalid,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &bridgev1.ListSharesResponse{
+		Shares: make([]*bridgev1.Share, len(shares)),
+	}
+
+	for idx, share := range shares {
+		res.Shares[idx] = shareProto(share)
+
+		// the token grants access to the study so it is only returned to
+		// the creator of the share.
+		if share.Creator != remote.ID {
+			res.Shares[idx].Token = ""
+		}
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (svc *ShareService) RevokeShare(ctx context.Context, req *connect.Request[bridgev1.RevokeShareRequest]) (*connect.Response[bridgev1.RevokeShareResponse], error) {
+	if _, err := svc.ownedShare(ctx, req.Msg.Token); err != nil {
+		return nil, err
+	}
+
+	share, err := svc.Repo.RevokeStudyShare(ctx, req.Msg.Token)
+	if err != nil {
+		return nil, shareError(err)
+	}
+
+	svc.invalidate(share.Token)
+
+	return connect.NewResponse(&bridgev1.RevokeShareResponse{
+		Share: shareProto(*share),
+	}), nil
+}
+
+func (svc *ShareService) ListShareAccess(ctx context.Context, req *connect.Request[bridgev1.ListShareAccessRequest]) (*connect.Response[bridgev1.ListShareAccessResponse], error) {
+	if _, err := svc.ownedShare(ctx, req.Msg.Token); err != nil {
+		return nil, err
+	}
+
+	accesses, err := svc.Repo.ListShareAccess(ctx, req.Msg.Token, int(req.Msg.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	res := &bridgev1.ListShareAccessResponse{
+		Accesses: make([]*bridgev1.ShareAccess, len(accesses)),
+	}
+
+	for idx, a := range accesses {
+		res.Accesses[idx] = &bridgev1.ShareAccess{
+			Time:      timestamppb.New(a.Time),
+			ClientIp:  a.ClientIP,
+			UserAgent: a.UserAgent,
+			Resource:  shareResourceProto(a.Resource),
+			Path:      a.Path,
+		}
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (svc *ShareService) UpdateShareExpiration(ctx context.Context, req *connect.Request[bridgev1.UpdateShareExpirationRequest]) (*connect.Response[bridgev1.UpdateShareExpirationResponse], error) {
+	var expiresAt time.Time
+
+	switch v := req.Msg.Expiration.(type) {
+	case *bridgev1.UpdateShareExpirationRequest_ExpireTime:
+		expiresAt = v.ExpireTime.AsTime()
+	case *bridgev1.UpdateShareExpirationRequest_ValidDuration:
+		expiresAt = time.Now().Add(v.ValidDuration.AsDuration())
+	default:
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("missing expiration"))
+	}
+
+	if _, err := svc.ownedShare(ctx, req.Msg.Token); err != nil {
+		return nil, err
+	}
+
+	share, err := svc.Repo.UpdateStudyShareExpiry(ctx, req.Msg.Token, expiresAt)
+	if err != nil {
+		return nil, shareError(err)
+	}
+
+	// the proxies cache share tokens until they expire so the token must be
+	// re-validated if the expiration has been shortened.
+	svc.invalidate(share.Token)
+
+	return connect.NewResponse(&bridgev1.UpdateShareExpirationResponse{
+		Share: shareProto(*share),
+	}), nil
+}
+
+// ownedShare returns the share with the given token if it has been created
+// by the calling user or the user is an admin.
+func (svc *ShareService) ownedShare(ctx context.Context, token string) (*repo.StudyShare, error) {
+	remote := auth.From(ctx)
+	if remote == nil {
+		return nil, connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("authentication required"))
+	}
+
+	share, err := svc.Repo.GetStudyShare(ctx, token)
+	if err != nil {
+		return nil, shareError(err)
+	}
+
+	if remote.ID != share.Creator && !remote.Admin {
+		return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("share has been created by a different user"))
+	}
+
+	return share, nil
+}
+
+func (svc *ShareService) invalidate(token string) {
+	for _, i := range svc.invalidators {
+		i.InvalidateToken(token)
+	}
+}
+
+// newStudyShare returns a new share for the given study. Instances may be
+// set to share individual instances of the study only.
+func newStudyShare(ctx context.Context, instance, studyUid string, instanceUids []string, validDuration *durationpb.Duration) repo.StudyShare {
+	ttl := defaultShareTTL
+	if validDuration.IsValid() {
+		ttl = validDuration.AsDuration()
+	}
+
+	return repo.StudyShare{
+		Token:        repo.ShareTokenPrefix + export.GetRandomString(48),
+		CreatedAt:    time.Now(),
+		Creator:      auth.From(ctx).ID,
+		Instance:     instance,
+		ExpiresAt:    time.Now().Add(ttl),
+		StudyUID:     studyUid,
+		InstanceUIDs: instanceUids,
+	}
+}
+
+// viewerURL returns the URL that opens share in the viewer. For protected
+// shares the URL of the unlock page is returned.
+func viewerURL(publicURL string, share repo.StudyShare) string {
+	if share.IsProtected() {
+		return proxy.UnlockURL(publicURL, share)
+	}
+
+	return proxy.ViewerURL(publicURL, share)
+}
+
+// resolveShareScope ensures that all series and instances of a scoped share
+// belong to the study and records the series of the shared instances.
+func resolveShareScope(ctx context.Context, clients *config.InstanceClients, share *repo.StudyShare) error {
+	if !share.IsScoped() {
+		return nil
+	}
+
+	res, err := clients.DICOMWebClient.Query(ctx, dicomweb.QIDORequest{
+		Type:             dicomweb.Instance,
+		StudyInstanceUID: share.StudyUID,
+		IncludeFields:    []string{dicomweb.SeriesInstanceUID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query study instances: %w", err)
+	}
+
+	instanceSeries := make(map[string]string, len(res))
+	studySeries := make(map[string]struct{})
+
+	for _, r := range res {
+		instance, _ := r.GetAsString(dicomweb.SOPInstanceUID)
+		series, _ := r.GetAsString(dicomweb.SeriesInstanceUID)
+
+		instanceSeries[instance] = series
+		studySeries[series] = struct{}{}
+	}
+
+	for _, series := range share.SeriesUIDs {
+		if _, ok := studySeries[series]; !ok {
+			return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("series %q is not part of study %q", series, share.StudyUID))
+		}
+	}
+
+	share.InstanceSeriesUIDs = nil
+	for _, instance := range share.InstanceUIDs {
+		series, ok := instanceSeries[instance]
+		if !ok {
+			return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("instance %q is not part of study %q", instance, share.StudyUID))
+		}
+
+		if !slices.Contains(share.InstanceSeriesUIDs, series) {
+			share.InstanceSeriesUIDs = append(share.InstanceSeriesUIDs, series)
+		}
+	}
+
+	return nil
+}
+
+// patientBirthDate returns the PatientBirthDate of the given study.
+func patientBirthDate(ctx context.Context, clients *config.InstanceClients, studyUid string) (string, error) {
+	res, err := clients.DICOMWebClient.Query(ctx, dicomweb.QIDORequest{
+		Type:             dicomweb.Study,
+		StudyInstanceUID: studyUid,
+		IncludeFields:    []string{dicomweb.PatientBirthDate},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to query study: %w", err)
+	}
+
+	if len(res) == 0 {
+		return "", connect.NewError(connect.CodeNotFound, fmt.Errorf("study %q not found", studyUid))
+	}
+
+	birthDate, _ := res[0].GetAsString(dicomweb.PatientBirthDate)
+	if birthDate == "" {
+		return "", connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf("study %q does not have a patient birth date", studyUid))
+	}
+
+	return birthDate, nil
+}
+
+func shareError(err error) error {
+	if errors.Is(err, repo.ErrNotFound) {
+		return connect.NewError(connect.CodeNotFound, err)
+	}
+
+	return err
+}
+
+func shareProto(share repo.StudyShare) *bridgev1.Share {
+	pb := &bridgev1.Share{
+		Token:        share.Token,
+		CreateTime:   timestamppb.New(share.CreatedAt),
+		Creator:      share.Creator,
+		Instance:     share.Instance,
+		StudyUid:     share.StudyUID,
+		InstanceUids: share.InstanceUIDs,
+		Recipients:   share.Recipients,
+		SeriesUids:   share.SeriesUIDs,
+		Valid:        share.IsValid(),
+		MaxUses:      int32(share.MaxUses),
+		Uses:         int32(share.Uses),
+	}
+
+	if !share.ExpiresAt.IsZero() {
+		pb.ExpireTime = timestamppb.New(share.ExpiresAt)
+	}
+
+	if !share.RevokedAt.IsZero() {
+		pb.RevokeTime = timestamppb.New(share.RevokedAt)
+	}
+
+	switch share.Protection {
+	case repo.ShareProtectionPIN:
+		pb.Protection = bridgev1.ShareProtection_SHARE_PROTECTION_PIN
+	case repo.ShareProtectionBirthDate:
+		pb.Protection = bridgev1.ShareProtection_SHARE_PROTECTION_BIRTH_DATE
+	}
+
+	for _, d := range share.Deliveries {
+		pb.Deliveries = append(pb.Deliveries, &bridgev1.ShareDelivery{
+			Recipient: d.Recipient,
+			Channel:   notify.ChannelProto(notify.Channel(d.Channel)),
+			Status:    notify.StatusProto(notify.Status(d.Status)),
+			Error:     d.Error,
+			Time:      timestamppb.New(d.Time),
+		})
+	}
+
+	return pb
+}
+
+func shareResourceProto(resource string) bridgev1.ShareResource {
+	switch resource {
+	case repo.ShareResourceQIDO:
+		return bridgev1.ShareResource_SHARE_RESOURCE_QIDO
+	case repo.ShareResourceWADO:
+		return bridgev1.ShareResource_SHARE_RESOURCE_WADO
+	case repo.ShareResourceRendered:
+		return bridgev1.ShareResource_SHARE_RESOURCE_RENDERED
+	default:
+		return bridgev1.ShareResource_SHARE_RESOURCE_UNSPECIFIED
+	}
+}
@@ -2,12 +2,22 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/bufbuild/connect-go"
 	"github.com/hashicorp/go-multierror"
+	"github.com/suyashkumar/dicom"
+	customerv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/customer/v1"
+	dicomv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/dicom/v1"
 	orthanc_bridgev1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1"
 	"github.com/tierklinik-dobersberg/apis/pkg/log"
+	bridgev1 "github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/gen/go/tkd/orthanc_bridge/v1/bridgev1connect"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/config"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/worklist"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 func (svc *Service) GetWorklistEntries(ctx context.Context, req *connect.Request[orthanc_bridgev1.GetWorklistEntriesRequest]) (*connect.Response[orthanc_bridgev1.GetWorklistEntriesResponse], error) {
@@ -43,3 +53,204 @@ func (svc *Service) GetWorklistEntries(ctx context.Context, req *connect.Request
 
 	return connect.NewResponse(res), nil
 }
+
+// WorklistService implements the bridgev1connect.WorklistServiceHandler
+// interface.
+type WorklistService struct {
+	bridgev1connect.UnimplementedWorklistServiceHandler
+
+	*config.Providers
+}
+
+func NewWorklistService(p *config.Providers) *WorklistService {
+	return &WorklistService{
+		Providers: p,
+	}
+}
+
+func (svc *WorklistService) CreateWorklistEntry(ctx context.Context, req *connect.Request[bridgev1.CreateWorklistEntryRequest]) (*connect.Response[bridgev1.CreateWorklistEntryResponse], error) {
+	if svc.Worklist == nil {
+		return nil, connect.NewError(connect.CodeUnavailable, fmt.Errorf("worklist not configured"))
+	}
+
+	m := req.Msg
+
+	customer, patient, err := svc.getCustomerAndPatient(ctx, m.CustomerId, m.PatientId)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := svc.Worklist.CreateEntry(customer, patient, worklist.Request{
+		Modality:       m.Modality,
+		ScheduledTime:  m.ScheduledTime.AsTime().Local(),
+		StationAETitle: m.ScheduledStationAeTitle,
+		Description:    m.Description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worklist entry: %w", err)
+	}
+
+	pb, err := entry.ToProto()
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&bridgev1.CreateWorklistEntryResponse{
+		Entry: pb,
+	}), nil
+}
+
+func (svc *WorklistService) DeleteWorklistEntry(ctx context.Context, req *connect.Request[bridgev1.DeleteWorklistEntryRequest]) (*connect.Response[emptypb.Empty], error) {
+	if svc.Worklist == nil {
+		return nil, connect.NewError(connect.CodeUnavailable, fmt.Errorf("worklist not configured"))
+	}
+
+	if err := svc.Worklist.DeleteEntry(req.Msg.Name); err != nil {
+		return nil, worklistError(err)
+	}
+
+	return connect.NewResponse(new(emptypb.Empty)), nil
+}
+
+func (svc *WorklistService) UpdateWorklistEntry(ctx context.Context, req *connect.Request[bridgev1.UpdateWorklistEntryRequest]) (*connect.Response[bridgev1.UpdateWorklistEntryResponse], error) {
+	if svc.Worklist == nil {
+		return nil, connect.NewError(connect.CodeUnavailable, fmt.Errorf("worklist not configured"))
+	}
+
+	elements := make([]*dicom.Element, 0, len(req.Msg.Elements))
+	for _, pb := range req.Msg.Elements {
+		el, err := worklist.ElementFromProto(pb)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+
+		elements = append(elements, el)
+	}
+
+	entry, err := svc.Worklist.UpdateEntry(req.Msg.Name, elements)
+	if err != nil {
+		return nil, worklistError(err)
+	}
+
+	pb, err := entry.ToProto()
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&bridgev1.UpdateWorklistEntryResponse{
+		Entry: pb,
+	}), nil
+}
+
+func (svc *WorklistService) TestWorklistRules(ctx context.Context, req *connect.Request[bridgev1.TestWorklistRulesRequest]) (*connect.Response[bridgev1.TestWorklistRulesResponse], error) {
+	if svc.Worklist == nil {
+		return nil, connect.NewError(connect.CodeUnavailable, fmt.Errorf("worklist not configured"))
+	}
+
+	m := req.Msg
+
+	customer, patient := m.Customer, m.Patient
+	if m.CustomerId != "" || m.PatientId != "" {
+		var err error
+
+		customer, patient, err = svc.getCustomerAndPatient(ctx, m.CustomerId, m.PatientId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if customer == nil || patient == nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("either customer_id and patient_id or customer and patient must be set"))
+	}
+
+	scheduledTime := time.Now()
+	if m.ScheduledTime.IsValid() {
+		scheduledTime = m.ScheduledTime.AsTime().Local()
+	}
+
+	ds, err := svc.Worklist.TestRules(customer, patient, worklist.Request{
+		Modality:       m.Modality,
+		ScheduledTime:  scheduledTime,
+		StationAETitle: m.ScheduledStationAeTitle,
+		Description:    m.Description,
+	}, m.Script)
+
+	res := &bridgev1.TestWorklistRulesResponse{
+		Elements: make([]*dicomv1.Element, 0, len(ds.Elements)),
+	}
+
+	if err := svc.Worklist.RuleError(); err != nil {
+		res.ReloadError = err.Error()
+	}
+
+	if err != nil {
+		var merr *multierror.Error
+		if errors.As(err, &merr) {
+			for _, e := range merr.Errors {
+				res.Errors = append(res.Errors, e.Error())
+			}
+		} else {
+			res.Errors = append(res.Errors, err.Error())
+		}
+	}
+
+	for _, el := range ds.Elements {
+		pb, err := worklist.ElementProto(el)
+		if err != nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("%s: %s", el.Tag, err))
+			continue
+		}
+
+		res.Elements = append(res.Elements, pb)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+// worklistError converts errors returned by the worklist package to
+// connect errors.
+func worklistError(err error) error {
+	if errors.Is(err, worklist.ErrEntryNotFound) {
+		return connect.NewError(connect.CodeNotFound, err)
+	}
+
+	if errors.Is(err, worklist.ErrInvalidName) {
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	return err
+}
+
+func (svc *WorklistService) getCustomerAndPatient(ctx context.Context, customerId, patientId string) (*customerv1.Customer, *customerv1.Patient, error) {
+	customerRes, err := svc.Clients.CustomerService.SearchCustomer(ctx, connect.NewRequest(&customerv1.SearchCustomerRequest{
+		Queries: []*customerv1.CustomerQuery{
+			{
+				Query: &customerv1.CustomerQuery_Id{
+					Id: customerId,
+				},
+			},
+		},
+	}))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch customer: %w", err)
+	}
+
+	if len(customerRes.Msg.Results) == 0 {
+		return nil, nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("customer %q not found", customerId))
+	}
+
+	patientRes, err := svc.Clients.PatientService.GetPatientsByCustomer(ctx, connect.NewRequest(&customerv1.GetPatientsByCustomerRequest{
+		CustomerId: customerId,
+	}))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch patients: %w", err)
+	}
+
+	for _, p := range patientRes.Msg.Patients {
+		if p.PatientId == patientId {
+			return customerRes.Msg.Results[0].Customer, p, nil
+		}
+	}
+
+	return nil, nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("patient %q not found for customer %q", patientId, customerId))
+}
@@ -0,0 +1,402 @@
+package worklist
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/tag"
+	"github.com/suyashkumar/dicom/pkg/uid"
+	customerv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/customer/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dimse"
+)
+
+var (
+	// ErrEntryNotFound is returned if a worklist entry does not exist.
+	ErrEntryNotFound = errors.New("worklist entry not found")
+
+	// ErrInvalidName is returned if a worklist entry name is invalid.
+	ErrInvalidName = errors.New("invalid worklist entry name")
+)
+
+// stepTags holds the attributes that belong to an item of the
+// ScheduledProcedureStepSequence.
+var stepTags = []tag.Tag{
+	tag.Modality,
+	tag.ScheduledStationAETitle,
+	tag.ScheduledStationName,
+	tag.ScheduledProcedureStepLocation,
+	tag.ScheduledProcedureStepStartDate,
+	tag.ScheduledProcedureStepStartTime,
+	tag.ScheduledProcedureStepEndDate,
+	tag.ScheduledProcedureStepEndTime,
+	tag.ScheduledPerformingPhysicianName,
+	tag.ScheduledProcedureStepDescription,
+	tag.ScheduledProcedureStepID,
+	tag.ScheduledProcedureStepStatus,
+}
+
+// Request describes a procedure that should be scheduled for a patient.
+type Request struct {
+	Modality       string
+	ScheduledTime  time.Time
+	StationAETitle string
+	Description    string
+}
+
+// CreateEntry generates a new worklist entry for the patient using the
+// registered rules and writes it to the target directory.
+func (wl *Worklist) CreateEntry(customer *customerv1.Customer, patient *customerv1.Patient, req Request) (Entry, error) {
+	ds, err := newDataset(customer, patient, req)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to prepare dataset: %w", err)
+	}
+
+	ds, err = wl.Generate(customer, patient, ds)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to execute rules: %w", err)
+	}
+
+	name, err := newEntryName()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	path := filepath.Join(wl.targetDirectory, name)
+	if err := writeEntry(path, ds); err != nil {
+		return Entry{}, err
+	}
+
+	// read the entry back so callers get exactly what has been written
+	// to disk.
+	ds, err = wl.readEntry(path)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if wl.onEntryCreate != nil {
+		wl.onEntryCreate(path, ds)
+	}
+
+	return Entry{
+		Path:    name,
+		Dataset: ds,
+	}, nil
+}
+
+// TestRules generates a worklist dataset for the patient like CreateEntry
+// but does not write it to the target directory. If script is set it is
+// used as the only rule file, otherwise the active rule set is used. Rule
+// errors are returned together with the dataset generated so far.
+func (wl *Worklist) TestRules(customer *customerv1.Customer, patient *customerv1.Patient, req Request, script string) (dicom.Dataset, error) {
+	rs := wl.rules.Load()
+
+	if script != "" {
+		var err error
+
+		rs, err = compileRules(map[string]string{"script.js": script}, wl.ruleTimeout)
+		if err != nil {
+			return dicom.Dataset{}, err
+		}
+	}
+
+	ds, err := newDataset(customer, patient, req)
+	if err != nil {
+		return dicom.Dataset{}, fmt.Errorf("failed to prepare dataset: %w", err)
+	}
+
+	ds, err = rs.generate(customer, patient, ds)
+
+	sortElements(ds.Elements)
+
+	return ds, err
+}
+
+// DeleteEntry removes the worklist entry with the given name.
+func (wl *Worklist) DeleteEntry(name string) error {
+	path, err := wl.entryPath(name)
+	if err != nil {
+		return err
+	}
+
+	wl.entryLock.Lock()
+	defer wl.entryLock.Unlock()
+
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrEntryNotFound
+		}
+
+		return fmt.Errorf("failed to remove worklist entry: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateEntry replaces or adds the given elements to the worklist entry with
+// the given name. Attributes of the scheduled procedure step are updated in
+// the first item of the ScheduledProcedureStepSequence.
+func (wl *Worklist) UpdateEntry(name string, elements []*dicom.Element) (Entry, error) {
+	path, err := wl.entryPath(name)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	wl.entryLock.Lock()
+	defer wl.entryLock.Unlock()
+
+	ds, err := wl.readEntry(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Entry{}, ErrEntryNotFound
+		}
+
+		return Entry{}, err
+	}
+
+	// drop the file meta information, it is re-created by writeEntry
+	ds.Elements = slices.DeleteFunc(ds.Elements, func(el *dicom.Element) bool {
+		return el.Tag.Group == tag.MetadataGroup
+	})
+
+	var stepElements, datasetElements []*dicom.Element
+	for _, el := range elements {
+		if slices.Contains(stepTags, el.Tag) {
+			stepElements = append(stepElements, el)
+		} else {
+			datasetElements = append(datasetElements, el)
+		}
+	}
+
+	ds = mergeElements(ds, datasetElements)
+
+	if len(stepElements) > 0 {
+		ds, err = updateStep(ds, stepElements)
+		if err != nil {
+			return Entry{}, err
+		}
+	}
+
+	if err := writeEntry(path, ds); err != nil {
+		return Entry{}, err
+	}
+
+	ds, err = wl.readEntry(path)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if wl.onEntryUpdate != nil {
+		wl.onEntryUpdate(path, ds)
+	}
+
+	return Entry{
+		Path:    name,
+		Dataset: ds,
+	}, nil
+}
+
+// updateStep merges elements into the first item of the
+// ScheduledProcedureStepSequence which is created if it does not exist.
+func updateStep(ds dicom.Dataset, elements []*dicom.Element) (dicom.Dataset, error) {
+	var step []*dicom.Element
+
+	if seq, err := ds.FindElementByTag(tag.ScheduledProcedureStepSequence); err == nil {
+		if items, ok := seq.Value.GetValue().([]*dicom.SequenceItemValue); ok && len(items) > 0 {
+			step = items[0].GetValue().([]*dicom.Element)
+		}
+	}
+
+	step = mergeElements(dicom.Dataset{Elements: step}, elements).Elements
+
+	seq, err := dicom.NewElement(tag.ScheduledProcedureStepSequence, [][]*dicom.Element{step})
+	if err != nil {
+		return ds, err
+	}
+
+	return mergeElements(ds, []*dicom.Element{seq}), nil
+}
+
+// entryPath returns the path of the worklist entry with the given name and
+// makes sure it does not point outside of the target directory.
+func (wl *Worklist) entryPath(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || !strings.HasSuffix(name, ".wl") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	return filepath.Join(wl.targetDirectory, name), nil
+}
+
+// newDataset returns the initial worklist dataset that is passed to the
+// worklist rules.
+func newDataset(customer *customerv1.Customer, patient *customerv1.Patient, req Request) (dicom.Dataset, error) {
+	studyUid, err := dimse.NewUID()
+	if err != nil {
+		return dicom.Dataset{}, err
+	}
+
+	accessionNumber := req.ScheduledTime.Format("20060102") + strings.ToUpper(randomHex(3))
+
+	step := []*dicom.Element{
+		stringElement(tag.Modality, req.Modality),
+		stringElement(tag.ScheduledProcedureStepStartDate, req.ScheduledTime.Format("20060102")),
+		stringElement(tag.ScheduledProcedureStepStartTime, req.ScheduledTime.Format("150405")),
+		stringElement(tag.ScheduledProcedureStepID, accessionNumber),
+	}
+
+	if req.StationAETitle != "" {
+		step = append(step, stringElement(tag.ScheduledStationAETitle, req.StationAETitle))
+	}
+
+	if req.Description != "" {
+		step = append(step, stringElement(tag.ScheduledProcedureStepDescription, req.Description))
+	}
+
+	stepSequence, err := dicom.NewElement(tag.ScheduledProcedureStepSequence, [][]*dicom.Element{step})
+	if err != nil {
+		return dicom.Dataset{}, err
+	}
+
+	elements := []*dicom.Element{
+		stringElement(tag.SpecificCharacterSet, "ISO_IR 192"),
+		stringElement(tag.AccessionNumber, accessionNumber),
+		stringElement(tag.StudyInstanceUID, studyUid),
+		stringElement(tag.RequestedProcedureID, accessionNumber),
+		stringElement(tag.PatientName, patient.GetPatientName()),
+		stringElement(tag.PatientID, patient.GetPatientId()),
+		stringElement(tag.ResponsiblePerson, customer.GetLastName()+"^"+customer.GetFirstName()),
+		stepSequence,
+	}
+
+	if req.Description != "" {
+		elements = append(elements, stringElement(tag.RequestedProcedureDescription, req.Description))
+	}
+
+	if s := patient.GetSpecies(); s != "" {
+		elements = append(elements, stringElement(tag.PatientSpeciesDescription, s))
+	}
+
+	if b := patient.GetBreed(); b != "" {
+		elements = append(elements, stringElement(tag.PatientBreedDescription, b))
+	}
+
+	if bd := patient.GetBirthday(); bd != nil && bd.Year > 0 {
+		elements = append(elements, stringElement(tag.PatientBirthDate, bd.AsTime().Format("20060102")))
+	}
+
+	switch patient.GetGender() {
+	case customerv1.PatientGender_PATIENT_GENDER_MALE, customerv1.PatientGender_PATIENT_GENDER_MALE_CASTRATED:
+		elements = append(elements, stringElement(tag.PatientSex, "M"))
+	case customerv1.PatientGender_PATIENT_GENDER_FEMALE, customerv1.PatientGender_PATIENT_GENDER_FEMALE_CASTRATED:
+		elements = append(elements, stringElement(tag.PatientSex, "F"))
+	default:
+		elements = append(elements, stringElement(tag.PatientSex, "O"))
+	}
+
+	return dicom.Dataset{Elements: elements}, nil
+}
+
+// writeEntry writes ds as a DICOM file to path. The file is written to a
+// temporary file first and renamed afterwards so readers never see a
+// partially written entry.
+func writeEntry(path string, ds dicom.Dataset) error {
+	instanceUid, err := dimse.NewUID()
+	if err != nil {
+		return err
+	}
+
+	meta := []*dicom.Element{
+		mustElement(tag.FileMetaInformationVersion, []byte{0x00, 0x01}),
+		stringElement(tag.MediaStorageSOPClassUID, uid.ModalityWorklistInformationFind),
+		stringElement(tag.MediaStorageSOPInstanceUID, instanceUid),
+		stringElement(tag.TransferSyntaxUID, uid.ExplicitVRLittleEndian),
+	}
+
+	ds = dicom.Dataset{
+		Elements: append(meta, ds.Elements...),
+	}
+
+	// DICOM requires data elements to be sorted by tag
+	sortElements(ds.Elements)
+
+	buf := new(bytes.Buffer)
+	if err := dicom.Write(buf, ds); err != nil {
+		return fmt.Errorf("failed to encode worklist entry: %w", err)
+	}
+
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write worklist entry: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		defer os.Remove(tmp)
+
+		return fmt.Errorf("failed to write worklist entry: %w", err)
+	}
+
+	return nil
+}
+
+func sortElements(elements []*dicom.Element) {
+	slices.SortStableFunc(elements, func(a, b *dicom.Element) int {
+		return cmp.Or(
+			cmp.Compare(a.Tag.Group, b.Tag.Group),
+			cmp.Compare(a.Tag.Element, b.Tag.Element),
+		)
+	})
+
+	for _, el := range elements {
+		if el.Value.ValueType() != dicom.Sequences {
+			continue
+		}
+
+		for _, item := range el.Value.GetValue().([]*dicom.SequenceItemValue) {
+			sortElements(item.GetValue().([]*dicom.Element))
+		}
+	}
+}
+
+func stringElement(t tag.Tag, value string) *dicom.Element {
+	return mustElement(t, []string{value})
+}
+
+func mustElement(t tag.Tag, value any) *dicom.Element {
+	el, err := dicom.NewElement(t, value)
+	if err != nil {
+		// all tags are well-known so this can only happen due to a programming
+		// error.
+		panic(fmt.Sprintf("failed to create element %s: %s", t, err))
+	}
+
+	return el
+}
+
+// newEntryName returns a unique file name for a new worklist entry.
+func newEntryName() (string, error) {
+	suffix := randomHex(4)
+	if suffix == "" {
+		return "", fmt.Errorf("failed to generate worklist entry name")
+	}
+
+	return fmt.Sprintf("%s-%s.wl", time.Now().Format("20060102-150405"), suffix), nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,66 @@
+package worklist
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/tag"
+	customerv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/customer/v1"
+)
+
+// TestEntryCallbacks makes sure creating and updating an entry are reported
+// exactly once using the matching callback.
+func TestEntryCallbacks(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		created []string
+		updated []string
+	)
+
+	wl, err := New(t.TempDir(), t.TempDir(), 0,
+		func(path string, _ dicom.Dataset) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			created = append(created, path)
+		},
+		func(path string, _ dicom.Dataset) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			updated = append(updated, path)
+		},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("failed to create worklist: %s", err)
+	}
+
+	entry, err := wl.CreateEntry(&customerv1.Customer{LastName: "Mustermann"}, &customerv1.Patient{PatientName: "Rex"}, Request{
+		Modality:      "CR",
+		ScheduledTime: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create entry: %s", err)
+	}
+
+	if _, err := wl.UpdateEntry(entry.Path, []*dicom.Element{stringElement(tag.PatientName, "Max")}); err != nil {
+		t.Fatalf("failed to update entry: %s", err)
+	}
+
+	// give the file system watcher time to report the writes
+	time.Sleep(200 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(created) != 1 {
+		t.Errorf("expected one created entry, got %v", created)
+	}
+
+	if len(updated) != 1 {
+		t.Errorf("expected one updated entry, got %v", updated)
+	}
+}
@@ -0,0 +1,415 @@
+package worklist
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/tag"
+)
+
+// Find returns all worklist entries that match the keys of a Modality
+// Worklist C-FIND identifier. Each result only contains the attributes
+// requested by query. Matching follows DICOM PS3.4 C.2.2.2: single value,
+// wildcard, list of UID, range (DA, TM and DT) and sequence matching are
+// supported.
+func (wl *Worklist) Find(query dicom.Dataset) ([]dicom.Dataset, error) {
+	entries, err := wl.ListEntries()
+	if err != nil && len(entries) == 0 {
+		return nil, err
+	}
+
+	var results []dicom.Dataset
+	for _, e := range entries {
+		elements, ok := matchElements(query.Elements, e.Dataset.Elements)
+		if !ok {
+			continue
+		}
+
+		// the character set is always returned so the SCU can decode
+		// the result.
+		if el, err := e.Dataset.FindElementByTag(tag.SpecificCharacterSet); err == nil {
+			elements = mergeElements(dicom.Dataset{Elements: elements}, []*dicom.Element{el}).Elements
+		}
+
+		sortElements(elements)
+
+		results = append(results, dicom.Dataset{
+			Elements: elements,
+		})
+	}
+
+	return results, nil
+}
+
+// matchElements matches all keys against elements. If all keys match, the
+// requested attributes are returned.
+func matchElements(keys []*dicom.Element, elements []*dicom.Element) ([]*dicom.Element, bool) {
+	ds := dicom.Dataset{
+		Elements: elements,
+	}
+
+	result := make([]*dicom.Element, 0, len(keys))
+	for _, key := range keys {
+		if key.Tag.Group == tag.MetadataGroup || key.Tag == tag.SpecificCharacterSet {
+			continue
+		}
+
+		el, err := ds.FindElementByTag(key.Tag)
+		if err != nil {
+			el = nil
+		}
+
+		if key.Value.ValueType() == dicom.Sequences {
+			seq, ok := matchSequence(key, el)
+			if !ok {
+				return nil, false
+			}
+
+			result = append(result, seq)
+
+			continue
+		}
+
+		if el == nil {
+			if !isUniversal(key) {
+				return nil, false
+			}
+
+			result = append(result, emptyElement(key))
+
+			continue
+		}
+
+		if !matchValue(key, el) {
+			return nil, false
+		}
+
+		result = append(result, el)
+	}
+
+	return result, true
+}
+
+// matchSequence matches the first item of a sequence key against all items
+// of el. The returned sequence holds all matching items reduced to the
+// requested attributes.
+func matchSequence(key *dicom.Element, el *dicom.Element) (*dicom.Element, bool) {
+	keyItems, _ := key.Value.GetValue().([]*dicom.SequenceItemValue)
+
+	var keyElements []*dicom.Element
+	if len(keyItems) > 0 {
+		keyElements, _ = keyItems[0].GetValue().([]*dicom.Element)
+	}
+
+	// a sequence without item keys matches all items and returns the
+	// complete sequence.
+	if len(keyElements) == 0 {
+		if el == nil {
+			return emptyElement(key), true
+		}
+
+		return el, true
+	}
+
+	var items []*dicom.SequenceItemValue
+	if el != nil {
+		items, _ = el.Value.GetValue().([]*dicom.SequenceItemValue)
+	}
+
+	if len(items) == 0 {
+		for _, k := range keyElements {
+			if !isUniversal(k) {
+				return nil, false
+			}
+		}
+
+		return emptyElement(key), true
+	}
+
+	var matched [][]*dicom.Element
+	for _, item := range items {
+		elements, _ := item.GetValue().([]*dicom.Element)
+
+		if result, ok := matchElements(keyElements, elements); ok {
+			matched = append(matched, result)
+		}
+	}
+
+	if len(matched) == 0 {
+		return nil, false
+	}
+
+	seq, err := dicom.NewElement(key.Tag, matched)
+	if err != nil {
+		return nil, false
+	}
+
+	return seq, true
+}
+
+// isUniversal reports whether key matches any value.
+func isUniversal(key *dicom.Element) bool {
+	values := elementStrings(key)
+
+	for _, v := range values {
+		if v != "" && strings.Trim(v, "*") != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchValue(key *dicom.Element, el *dicom.Element) bool {
+	if isUniversal(key) {
+		return true
+	}
+
+	keyValues := elementStrings(key)
+	values := elementStrings(el)
+
+	switch vr := key.RawValueRepresentation; vr {
+	case "DA", "TM", "DT":
+		for _, v := range values {
+			if matchDateTime(vr, keyValues[0], v) {
+				return true
+			}
+		}
+
+	case "UI":
+		// list of UID matching
+		for _, k := range keyValues {
+			for _, v := range values {
+				if k == v {
+					return true
+				}
+			}
+		}
+
+	default:
+		k := keyValues[0]
+
+		// person names are matched case-insensitive since modalities
+		// often send upper case names.
+		if vr == "PN" {
+			k = strings.ToUpper(k)
+		}
+
+		for _, v := range values {
+			if vr == "PN" {
+				v = strings.ToUpper(v)
+			}
+
+			if strings.ContainsAny(k, "*?") {
+				if matchWildcard(k, v) {
+					return true
+				}
+			} else if k == v {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchDateTime matches a DA, TM or DT value against a single value or a
+// range key like "20240101-20240131", "-20240131" or "20240101-".
+func matchDateTime(vr string, key string, value string) bool {
+	value = normalizeDateTime(vr, value, '0')
+
+	sep := rangeSeparator(vr, key)
+	if sep < 0 {
+		return normalizeDateTime(vr, key, '0') == value
+	}
+
+	lower, upper := key[:sep], key[sep+1:]
+
+	if lower != "" && value < normalizeDateTime(vr, lower, '0') {
+		return false
+	}
+
+	if upper != "" && value > normalizeDateTime(vr, upper, '9') {
+		return false
+	}
+
+	return true
+}
+
+// rangeSeparator returns the index of the "-" that separates the bounds of
+// a range key or -1 if key is a single value. DT values may end in a UTC
+// offset like "-0500" which must not be taken as the separator.
+func rangeSeparator(vr string, key string) int {
+	if vr != "DT" {
+		return strings.IndexByte(key, '-')
+	}
+
+	for idx := 0; idx < len(key); idx++ {
+		if key[idx] != '-' {
+			continue
+		}
+
+		if isUTCOffset(key[:idx], key[idx+1:]) {
+			idx += 4
+			continue
+		}
+
+		return idx
+	}
+
+	return -1
+}
+
+// isUTCOffset reports whether the "-" between before and after starts the
+// UTC offset of the DT value before. Offsets have four digits and are only
+// used with values that include at least the hour.
+func isUTCOffset(before string, after string) bool {
+	if len(before) < 10 || !isDigit(before[len(before)-1]) {
+		return false
+	}
+
+	if len(after) < 4 || (len(after) > 4 && after[4] != '-') {
+		return false
+	}
+
+	for _, c := range []byte(after[:4]) {
+		if !isDigit(c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// normalizeDateTime removes separators, fractions and UTC offsets from value and pads it
+// to the full length of the VR so values can be compared lexically.
+func normalizeDateTime(vr string, value string, pad byte) string {
+	value = strings.TrimSpace(value)
+
+	var length int
+	switch vr {
+	case "DA":
+		length = 8
+
+		// older versions of the standard used YYYY.MM.DD
+		value = strings.ReplaceAll(value, ".", "")
+	case "TM":
+		length = 6
+
+		// older versions of the standard used HH:MM:SS.frac
+		value, _, _ = strings.Cut(value, ".")
+		value = strings.ReplaceAll(value, ":", "")
+	default:
+		length = 14
+
+		// the UTC offset is ignored when comparing values
+		if l := len(value); l >= 5 && (value[l-5] == '+' || value[l-5] == '-') {
+			value = value[:l-5]
+		}
+
+		value, _, _ = strings.Cut(value, ".")
+	}
+
+	if len(value) > length {
+		return value[:length]
+	}
+
+	return value + strings.Repeat(string(pad), length-len(value))
+}
+
+// matchWildcard matches value against a pattern where "*" matches any
+// sequence of characters and "?" matches a single character.
+func matchWildcard(pattern string, value string) bool {
+	p, v := []rune(pattern), []rune(value)
+
+	// index of the last "*" in pattern and the position in value it
+	// matched up to.
+	star, match := -1, 0
+
+	pi, vi := 0, 0
+	for vi < len(v) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == v[vi]):
+			pi++
+			vi++
+		case pi < len(p) && p[pi] == '*':
+			star, match = pi, vi
+			pi++
+		case star >= 0:
+			pi = star + 1
+			match++
+			vi = match
+		default:
+			return false
+		}
+	}
+
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+
+	return pi == len(p)
+}
+
+// elementStrings returns the values of el as trimmed strings.
+func elementStrings(el *dicom.Element) []string {
+	switch v := el.Value.GetValue().(type) {
+	case []string:
+		result := make([]string, len(v))
+		for idx, s := range v {
+			result[idx] = strings.TrimSpace(s)
+		}
+
+		return result
+
+	case []int:
+		result := make([]string, len(v))
+		for idx, i := range v {
+			result[idx] = fmt.Sprint(i)
+		}
+
+		return result
+
+	case []float64:
+		result := make([]string, len(v))
+		for idx, f := range v {
+			result[idx] = fmt.Sprint(f)
+		}
+
+		return result
+
+	default:
+		return nil
+	}
+}
+
+// emptyElement returns an element without value that is used as the
+// response for requested attributes that are not part of a worklist entry.
+func emptyElement(key *dicom.Element) *dicom.Element {
+	var value any
+
+	switch key.Value.ValueType() {
+	case dicom.Ints:
+		value = []int{}
+	case dicom.Floats:
+		value = []float64{}
+	case dicom.Bytes:
+		value = []byte{}
+	case dicom.Sequences:
+		value = [][]*dicom.Element{}
+	default:
+		value = []string{}
+	}
+
+	el, err := dicom.NewElement(key.Tag, value)
+	if err != nil {
+		return key
+	}
+
+	return el
+}
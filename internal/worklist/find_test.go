@@ -0,0 +1,226 @@
+package worklist
+
+import (
+	"testing"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/tag"
+)
+
+func TestMatchWildcard(t *testing.T) {
+	cases := []struct {
+		pattern string
+		value   string
+		match   bool
+	}{
+		{"*", "", true},
+		{"*", "anything", true},
+		{"MUSTER*", "MUSTERMANN", true},
+		{"*MANN", "MUSTERMANN", true},
+		{"M*R*N", "MUSTERMANN", true},
+		{"M?STERMANN", "MUSTERMANN", true},
+		{"M?STERMANN", "MSTERMANN", false},
+		{"MUSTER*", "MAX", false},
+		{"*X", "MAX^REX", true},
+		{"*A*A*", "BANANA", true},
+		{"A*", "", false},
+		{"?", "", false},
+		{"ÄR?T", "ÄRZT", true},
+	}
+
+	for _, c := range cases {
+		if got := matchWildcard(c.pattern, c.value); got != c.match {
+			t.Errorf("matchWildcard(%q, %q) = %t, expected %t", c.pattern, c.value, got, c.match)
+		}
+	}
+}
+
+func TestNormalizeDateTime(t *testing.T) {
+	cases := []struct {
+		vr       string
+		value    string
+		pad      byte
+		expected string
+	}{
+		{"DA", "20240101", '0', "20240101"},
+		{"DA", "2024.01.01", '0', "20240101"},
+		{"DA", "202401", '9', "20240199"},
+		{"TM", "1200", '0', "120000"},
+		{"TM", "12:00:30.123", '0', "120030"},
+		{"TM", "120030.123456", '9', "120030"},
+		{"DT", "2024", '0', "20240000000000"},
+		{"DT", "20240101120000.123456", '0', "20240101120000"},
+		{"DT", "20240101120000-0500", '0', "20240101120000"},
+		{"DT", "202401011200+0100", '9', "20240101120099"},
+		{"DT", " 20240101 ", '0', "20240101000000"},
+	}
+
+	for _, c := range cases {
+		if got := normalizeDateTime(c.vr, c.value, c.pad); got != c.expected {
+			t.Errorf("normalizeDateTime(%q, %q, %q) = %q, expected %q", c.vr, c.value, c.pad, got, c.expected)
+		}
+	}
+}
+
+func TestMatchDateTime(t *testing.T) {
+	cases := []struct {
+		vr    string
+		key   string
+		value string
+		match bool
+	}{
+		{"DA", "20240101", "20240101", true},
+		{"DA", "20240101", "20240102", false},
+		{"DA", "20240101-20240131", "20240115", true},
+		{"DA", "20240101-20240131", "20240201", false},
+		{"DA", "-20240131", "20231231", true},
+		{"DA", "20240101-", "20231231", false},
+		{"TM", "0800-1200", "1130", true},
+		{"TM", "0800-1200", "120030", true},
+		{"TM", "0800-1200", "1300", false},
+		{"DT", "20240101120000-0500", "20240101120000", true},
+		{"DT", "20240101120000-0500", "20240101130000", false},
+		{"DT", "20240101120000-0500-20240101140000-0500", "20240101130000", true},
+		{"DT", "20240101120000-0500-20240101140000-0500", "20240101150000", false},
+		{"DT", "20240101120000-0500-", "20240102000000", true},
+		{"DT", "-20240101140000+0100", "20240101150000", false},
+		{"DT", "2023-2024", "20240615000000", true},
+		{"DT", "2023-2024", "20250101000000", false},
+		{"DT", "2024010112-2024010113", "20240101123000", true},
+	}
+
+	for _, c := range cases {
+		if got := matchDateTime(c.vr, c.key, c.value); got != c.match {
+			t.Errorf("matchDateTime(%q, %q, %q) = %t, expected %t", c.vr, c.key, c.value, got, c.match)
+		}
+	}
+}
+
+func TestMatchElements(t *testing.T) {
+	entry := []*dicom.Element{
+		stringElement(tag.PatientName, "Rex^Mustermann"),
+		stringElement(tag.PatientID, "1234"),
+		stringElement(tag.AccessionNumber, "ACC1"),
+		stringElement(tag.StudyInstanceUID, "1.2.3"),
+		mustElement(tag.ScheduledProcedureStepSequence, [][]*dicom.Element{
+			{
+				stringElement(tag.Modality, "CR"),
+				stringElement(tag.ScheduledProcedureStepStartDate, "20240115"),
+				stringElement(tag.ScheduledStationAETitle, "XRAY"),
+			},
+		}),
+	}
+
+	step := func(elements ...*dicom.Element) *dicom.Element {
+		return mustElement(tag.ScheduledProcedureStepSequence, [][]*dicom.Element{elements})
+	}
+
+	cases := []struct {
+		name     string
+		keys     []*dicom.Element
+		match    bool
+		returned []tag.Tag
+	}{
+		{
+			name:     "universal keys",
+			keys:     []*dicom.Element{stringElement(tag.PatientName, ""), stringElement(tag.PatientID, "")},
+			match:    true,
+			returned: []tag.Tag{tag.PatientName, tag.PatientID},
+		},
+		{
+			name:     "case insensitive person name wildcard",
+			keys:     []*dicom.Element{stringElement(tag.PatientName, "REX*")},
+			match:    true,
+			returned: []tag.Tag{tag.PatientName},
+		},
+		{
+			name:  "single value mismatch",
+			keys:  []*dicom.Element{stringElement(tag.PatientID, "4321")},
+			match: false,
+		},
+		{
+			name:     "list of UID",
+			keys:     []*dicom.Element{mustElement(tag.StudyInstanceUID, []string{"1.2.4", "1.2.3"})},
+			match:    true,
+			returned: []tag.Tag{tag.StudyInstanceUID},
+		},
+		{
+			name:     "missing attribute with universal key",
+			keys:     []*dicom.Element{stringElement(tag.OtherPatientIDs, "")},
+			match:    true,
+			returned: []tag.Tag{tag.OtherPatientIDs},
+		},
+		{
+			name:  "missing attribute with value",
+			keys:  []*dicom.Element{stringElement(tag.OtherPatientIDs, "1")},
+			match: false,
+		},
+		{
+			name: "sequence with date range",
+			keys: []*dicom.Element{step(
+				stringElement(tag.Modality, "CR"),
+				stringElement(tag.ScheduledProcedureStepStartDate, "20240101-20240131"),
+			)},
+			match:    true,
+			returned: []tag.Tag{tag.ScheduledProcedureStepSequence},
+		},
+		{
+			name: "sequence mismatch",
+			keys: []*dicom.Element{step(
+				stringElement(tag.ScheduledStationAETitle, "US"),
+			)},
+			match: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result, ok := matchElements(c.keys, entry)
+			if ok != c.match {
+				t.Fatalf("expected match to be %t, got %t", c.match, ok)
+			}
+
+			if len(result) != len(c.returned) {
+				t.Fatalf("expected %d returned attributes, got %d", len(c.returned), len(result))
+			}
+
+			for idx, el := range result {
+				if el.Tag != c.returned[idx] {
+					t.Errorf("expected attribute %d to be %s, got %s", idx, c.returned[idx], el.Tag)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchElementsReducesSequenceItems(t *testing.T) {
+	entry := []*dicom.Element{
+		mustElement(tag.ScheduledProcedureStepSequence, [][]*dicom.Element{
+			{
+				stringElement(tag.Modality, "CR"),
+				stringElement(tag.ScheduledStationAETitle, "XRAY"),
+			},
+		}),
+	}
+
+	keys := []*dicom.Element{
+		mustElement(tag.ScheduledProcedureStepSequence, [][]*dicom.Element{
+			{stringElement(tag.Modality, "")},
+		}),
+	}
+
+	result, ok := matchElements(keys, entry)
+	if !ok || len(result) != 1 {
+		t.Fatalf("expected the sequence to match")
+	}
+
+	items, _ := result[0].Value.GetValue().([]*dicom.SequenceItemValue)
+	if len(items) != 1 {
+		t.Fatalf("expected one sequence item, got %d", len(items))
+	}
+
+	elements, _ := items[0].GetValue().([]*dicom.Element)
+	if len(elements) != 1 || elements[0].Tag != tag.Modality {
+		t.Errorf("expected the sequence item to only contain the modality, got %v", elements)
+	}
+}
@@ -0,0 +1,191 @@
+package worklist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/tag"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/orthanc"
+)
+
+// housekeepingInterval defines how often expired worklist entries are
+// removed.
+const housekeepingInterval = 10 * time.Minute
+
+// StartHousekeeping periodically removes all worklist entries that have been
+// scheduled more than maxAge ago. The loop stops when ctx is cancelled.
+func (wl *Worklist) StartHousekeeping(ctx context.Context, maxAge time.Duration) {
+	go func() {
+		ticker := time.NewTicker(housekeepingInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := wl.RemoveExpired(time.Now().Add(-maxAge)); err != nil {
+				slog.Error("failed to remove expired worklist entries", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RemoveExpired removes all worklist entries that are scheduled before
+// threshold.
+func (wl *Worklist) RemoveExpired(threshold time.Time) error {
+	entries, err := wl.ListEntries()
+	if err != nil && len(entries) == 0 {
+		return err
+	}
+
+	merr := new(multierror.Error)
+	for _, e := range entries {
+		scheduled, ok := scheduledTime(e.Dataset)
+		if !ok || !scheduled.Before(threshold) {
+			continue
+		}
+
+		slog.Info("removing expired worklist entry", "name", e.Path, "scheduled", scheduled)
+
+		if err := wl.DeleteEntry(e.Path); err != nil {
+			merr.Errors = append(merr.Errors, fmt.Errorf("%s: %w", e.Path, err))
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// CompleteStudy removes all worklist entries that match either the
+// accession number or the study instance UID of a received study. It
+// returns the names of all removed entries.
+func (wl *Worklist) CompleteStudy(accessionNumber, studyUid string) ([]string, error) {
+	if accessionNumber == "" && studyUid == "" {
+		return nil, nil
+	}
+
+	entries, err := wl.ListEntries()
+	if err != nil && len(entries) == 0 {
+		return nil, err
+	}
+
+	var removed []string
+
+	merr := new(multierror.Error)
+	for _, e := range entries {
+		matches := (accessionNumber != "" && stringValue(e.Dataset, tag.AccessionNumber) == accessionNumber) ||
+			(studyUid != "" && stringValue(e.Dataset, tag.StudyInstanceUID) == studyUid)
+
+		if !matches {
+			continue
+		}
+
+		// the entry file is removed so keep a record of the completed
+		// procedure in the service logs.
+		scheduled, _ := scheduledTime(e.Dataset)
+		slog.Info("worklist entry completed",
+			"name", e.Path,
+			"accessionNumber", stringValue(e.Dataset, tag.AccessionNumber),
+			"studyUid", stringValue(e.Dataset, tag.StudyInstanceUID),
+			"patientId", stringValue(e.Dataset, tag.PatientID),
+			"patientName", stringValue(e.Dataset, tag.PatientName),
+			"responsiblePerson", stringValue(e.Dataset, tag.ResponsiblePerson),
+			"scheduled", scheduled,
+		)
+
+		if err := wl.DeleteEntry(e.Path); err != nil {
+			merr.Errors = append(merr.Errors, fmt.Errorf("%s: %w", e.Path, err))
+			continue
+		}
+
+		removed = append(removed, e.Path)
+	}
+
+	return removed, merr.ErrorOrNil()
+}
+
+// CompletionHandler returns a handler for the orthanc change feed that
+// completes worklist entries once a matching study has been received.
+func (wl *Worklist) CompletionHandler(cli *orthanc.Client) func(context.Context, []orthanc.ChangeResult) error {
+	return func(ctx context.Context, changes []orthanc.ChangeResult) error {
+		for _, change := range changes {
+			if change.ChangeType != orthanc.ChangeNewStudy {
+				continue
+			}
+
+			study, err := cli.GetStudy(ctx, change.ID)
+			if err != nil {
+				if errors.Is(err, orthanc.ErrNotFound) {
+					continue
+				}
+
+				return fmt.Errorf("failed to fetch study %q: %w", change.ID, err)
+			}
+
+			if _, err := wl.CompleteStudy(study.MainDicomTags["AccessionNumber"], study.MainDicomTags["StudyInstanceUID"]); err != nil {
+				slog.Error("failed to complete worklist entries", "study", change.ID, "error", err)
+			}
+		}
+
+		return nil
+	}
+}
+
+// scheduledTime returns the start time of the first scheduled procedure
+// step of a worklist entry.
+func scheduledTime(ds dicom.Dataset) (time.Time, bool) {
+	seq, err := ds.FindElementByTag(tag.ScheduledProcedureStepSequence)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	items, ok := seq.Value.GetValue().([]*dicom.SequenceItemValue)
+	if !ok || len(items) == 0 {
+		return time.Time{}, false
+	}
+
+	step := dicom.Dataset{
+		Elements: items[0].GetValue().([]*dicom.Element),
+	}
+
+	date := stringValue(step, tag.ScheduledProcedureStepStartDate)
+	if date == "" {
+		return time.Time{}, false
+	}
+
+	tm := stringValue(step, tag.ScheduledProcedureStepStartTime)
+	if len(tm) >= 6 {
+		if t, err := time.ParseInLocation("20060102150405", date+tm[:6], time.Local); err == nil {
+			return t, true
+		}
+	}
+
+	t, err := time.ParseInLocation("20060102", date, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+func stringValue(ds dicom.Dataset, t tag.Tag) string {
+	el, err := ds.FindElementByTag(t)
+	if err != nil {
+		return ""
+	}
+
+	values, ok := el.Value.GetValue().([]string)
+	if !ok || len(values) == 0 {
+		return ""
+	}
+
+	return strings.TrimSpace(values[0])
+}
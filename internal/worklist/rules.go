@@ -0,0 +1,310 @@
+package worklist
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/hashicorp/go-multierror"
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/tag"
+	customerv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/customer/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dicomweb"
+)
+
+// ruleReloadDelay is used to debounce file system events so rules are only
+// reloaded once if multiple files are changed at once.
+const ruleReloadDelay = 500 * time.Millisecond
+
+// defaultRuleTimeout is used if no execution timeout is configured.
+const defaultRuleTimeout = 5 * time.Second
+
+type rule struct {
+	name string
+	exec goja.Callable
+}
+
+type ruleFile struct {
+	name    string
+	program *goja.Program
+}
+
+// ruleSet holds the compiled rule files. Each call to generate executes the
+// rule files in a fresh goja runtime so global state set by rules for one
+// entry, like patient data, never leaks into another one.
+type ruleSet struct {
+	files   []ruleFile
+	timeout time.Duration
+}
+
+// ruleRuntime is a goja runtime together with the rules registered by the
+// rule files.
+type ruleRuntime struct {
+	rt    *goja.Runtime
+	rules []rule
+}
+
+// loadRules compiles all rule files in dir and executes them once to make
+// sure all rule files can be executed.
+func loadRules(dir string, timeout time.Duration) (*ruleSet, error) {
+	sources := make(map[string]string)
+
+	if dir != "" {
+		rulesFs := os.DirFS(dir)
+
+		ruleFiles, err := fs.Glob(rulesFs, "*.js")
+		if err != nil {
+			return nil, fmt.Errorf("failed to search for rules: %w", err)
+		}
+
+		for _, f := range ruleFiles {
+			content, err := fs.ReadFile(rulesFs, f)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read rule file %q: %w", f, err)
+			}
+
+			sources[f] = string(content)
+		}
+	}
+
+	return compileRules(sources, timeout)
+}
+
+// compileRules compiles the given rule sources, indexed by file name, and
+// executes them once. Rule files are executed in lexical order of
+// their names so rules are always registered in a deterministic order.
+func compileRules(sources map[string]string, timeout time.Duration) (*ruleSet, error) {
+	if timeout <= 0 {
+		timeout = defaultRuleTimeout
+	}
+
+	rs := &ruleSet{
+		timeout: timeout,
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(sources)) {
+		p, err := goja.Compile(name, sources[name], true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile rule %q: %w", name, err)
+		}
+
+		rs.files = append(rs.files, ruleFile{
+			name:    name,
+			program: p,
+		})
+	}
+
+	if _, err := rs.newRuntime(); err != nil {
+		return nil, err
+	}
+
+	return rs, nil
+}
+
+// newRuntime creates a new goja runtime and executes all rule files.
+func (rs *ruleSet) newRuntime() (*ruleRuntime, error) {
+	r := &ruleRuntime{
+		rt: goja.New(),
+	}
+
+	r.rt.Set("rule", r.registerRule)
+	r.rt.Set("tag", tagElement)
+	r.rt.Set("std", newStdlib(r.rt))
+
+	for name := range dicomweb.TagNames {
+		// some keywords like "Date" would shadow JavaScript builtins
+		if r.rt.Get(name) != nil {
+			continue
+		}
+
+		t, err := findTag(name)
+		if err != nil {
+			slog.Debug("failed to find tag", "name", name)
+			continue
+		}
+
+		r.rt.Set(name, t)
+	}
+
+	for _, f := range rs.files {
+		err := rs.withTimeout(r.rt, func() error {
+			_, err := r.rt.RunProgram(f.program)
+			return err
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute rule file %q: %w", f.name, err)
+		}
+	}
+
+	return r, nil
+}
+
+// withTimeout executes fn and interrupts the runtime if fn does not return
+// within the configured timeout.
+func (rs *ruleSet) withTimeout(rt *goja.Runtime, fn func() error) error {
+	interrupted := make(chan struct{})
+
+	timer := time.AfterFunc(rs.timeout, func() {
+		rt.Interrupt(fmt.Sprintf("execution timeout of %s exceeded", rs.timeout))
+		close(interrupted)
+	})
+
+	err := fn()
+
+	// if the timer already fired, wait for the interrupt to be set so it
+	// is not set again after being cleared.
+	if !timer.Stop() {
+		<-interrupted
+	}
+
+	rt.ClearInterrupt()
+
+	return err
+}
+
+func (r *ruleRuntime) registerRule(name string, exec goja.Callable) {
+	r.rules = append(r.rules, rule{
+		name: name,
+		exec: exec,
+	})
+}
+
+func tagElement(name string, value any) (*dicom.Element, error) {
+	t, err := findTag(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := tag.Find(t)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := convertValue(info.VRs[0], value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return dicom.NewElement(t, data)
+}
+
+func (rs *ruleSet) generate(customer *customerv1.Customer, patient *customerv1.Patient, ds dicom.Dataset) (dicom.Dataset, error) {
+	r, err := rs.newRuntime()
+	if err != nil {
+		return ds, fmt.Errorf("failed to prepare runtime: %w", err)
+	}
+
+	merr := new(multierror.Error)
+
+	for _, rule := range r.rules {
+		var result goja.Value
+
+		err := rs.withTimeout(r.rt, func() error {
+			var err error
+
+			result, err = rule.exec(
+				goja.Undefined(),
+				r.rt.ToValue(customer),
+				r.rt.ToValue(patient),
+				r.rt.ToValue(ds),
+			)
+
+			return err
+		})
+
+		if err != nil {
+			merr.Errors = append(merr.Errors, fmt.Errorf("%s: %w", rule.name, err))
+
+			// interrupted runtimes may be left in an inconsistent state
+			var ierr *goja.InterruptedError
+			if errors.As(err, &ierr) {
+				break
+			}
+
+			continue
+		}
+
+		if result == nil || goja.IsUndefined(result) || goja.IsNull(result) {
+			continue
+		}
+
+		var resultSet []*dicom.Element
+		if err := r.rt.ExportTo(result, &resultSet); err != nil {
+			merr.Errors = append(merr.Errors, fmt.Errorf("failed to parse rule result %q: %w", rule.name, err))
+			continue
+		}
+
+		ds = mergeElements(ds, resultSet)
+	}
+
+	return ds, merr.ErrorOrNil()
+}
+
+// scheduleReload reloads all rules after ruleReloadDelay. Calling
+// scheduleReload again before the rules have been reloaded resets the
+// delay.
+func (wl *Worklist) scheduleReload() {
+	wl.reloadLock.Lock()
+	defer wl.reloadLock.Unlock()
+
+	if wl.reloadTimer != nil {
+		wl.reloadTimer.Stop()
+	}
+
+	wl.reloadTimer = time.AfterFunc(ruleReloadDelay, func() {
+		_ = wl.ReloadRules()
+	})
+}
+
+// ReloadRules loads all rule files from the rules directory and replaces
+// the active rule set. If a rule file cannot be compiled or executed the
+// active rule set is kept and the error is returned.
+func (wl *Worklist) ReloadRules() error {
+	rs, err := loadRules(wl.rulesDirectory, wl.ruleTimeout)
+
+	wl.reloadLock.Lock()
+	wl.ruleErr = err
+	wl.reloadLock.Unlock()
+
+	if err != nil {
+		slog.Error("failed to reload worklist rules, keeping previous rules active", "error", err)
+
+		return err
+	}
+
+	wl.rules.Store(rs)
+
+	slog.Info("worklist rules reloaded", "files", len(rs.files))
+
+	return nil
+}
+
+// RuleError returns the error of the last rule reload, if any. A non-nil
+// error means that an older version of the rules is still active.
+func (wl *Worklist) RuleError() error {
+	wl.reloadLock.Lock()
+	defer wl.reloadLock.Unlock()
+
+	return wl.ruleErr
+}
+
+func findTag(name string) (tag.Tag, error) {
+	t, err := tag.FindByKeyword(name)
+	if err == nil {
+		return t.Tag, nil
+	}
+
+	t, err = tag.FindByName(name)
+	if err != nil {
+		return tag.Tag{}, err
+	}
+
+	return t.Tag, nil
+}
@@ -0,0 +1,44 @@
+package worklist
+
+import (
+	"testing"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/tag"
+	customerv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/customer/v1"
+)
+
+// TestRulesDoNotShareState makes sure globals set by a rule while
+// generating one entry are not visible while generating the next one.
+func TestRulesDoNotShareState(t *testing.T) {
+	rs, err := compileRules(map[string]string{
+		"leak.js": `
+var previous;
+
+rule("leak", function(customer, patient, ds) {
+	var result = [];
+	if (previous !== undefined) {
+		result.push(tag("PatientComments", previous));
+	}
+
+	previous = "seen";
+
+	return result;
+});
+`,
+	}, 0)
+	if err != nil {
+		t.Fatalf("failed to compile rules: %s", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		ds, err := rs.generate(&customerv1.Customer{}, &customerv1.Patient{}, dicom.Dataset{})
+		if err != nil {
+			t.Fatalf("failed to generate entry: %s", err)
+		}
+
+		if el, err := ds.FindElementByTag(tag.PatientComments); err == nil {
+			t.Fatalf("entry %d contains state of a previous entry: %v", i, el)
+		}
+	}
+}
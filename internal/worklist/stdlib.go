@@ -0,0 +1,103 @@
+package worklist
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dop251/goja"
+	commonv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/common/v1"
+	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dimse"
+)
+
+// DICOM date and time formats for DA, TM and DT value representations.
+const (
+	dicomDateLayout     = "20060102"
+	dicomTimeLayout     = "150405"
+	dicomDateTimeLayout = "20060102150405"
+)
+
+// newStdlib returns the "std" object that is available to rule files. It
+// provides helpers for formatting dates and generating DICOM UIDs:
+//
+//	std.now()                     the current time as a JS Date
+//	std.formatDate(value, layout) formats value using a Go time layout
+//	std.formatDA(value)           formats value as a DICOM date (DA)
+//	std.formatTM(value)           formats value as a DICOM time (TM)
+//	std.formatDT(value)           formats value as a DICOM date-time (DT)
+//	std.uid()                     generates a new, random DICOM UID
+//
+// value may be a JS Date, milliseconds since the unix epoch, an RFC3339
+// string or any protobuf date or timestamp. Note that fields of the
+// customer and patient keep their Go names, like patient.Birthday.
+func newStdlib(rt *goja.Runtime) *goja.Object {
+	std := rt.NewObject()
+
+	format := func(layout string) func(goja.Value) (string, error) {
+		return func(value goja.Value) (string, error) {
+			t, err := exportTime(value)
+			if err != nil {
+				return "", err
+			}
+
+			return t.Format(layout), nil
+		}
+	}
+
+	std.Set("now", func() goja.Value {
+		v, _ := rt.New(rt.Get("Date").ToObject(rt), rt.ToValue(time.Now().UnixMilli()))
+		return v
+	})
+
+	std.Set("formatDate", func(value goja.Value, layout string) (string, error) {
+		return format(layout)(value)
+	})
+
+	std.Set("formatDA", format(dicomDateLayout))
+	std.Set("formatTM", format(dicomTimeLayout))
+	std.Set("formatDT", format(dicomDateTimeLayout))
+	std.Set("uid", dimse.NewUID)
+
+	return std
+}
+
+// exportTime converts a JS value passed to one of the std date functions to
+// a time.Time in the local timezone.
+func exportTime(value goja.Value) (time.Time, error) {
+	if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		return time.Time{}, fmt.Errorf("missing date value")
+	}
+
+	switch v := value.Export().(type) {
+	case time.Time:
+		return v.Local(), nil
+
+	case int64:
+		return time.UnixMilli(v).Local(), nil
+
+	case float64:
+		return time.UnixMilli(int64(v)).Local(), nil
+
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date value %q: %w", v, err)
+		}
+
+		return t.Local(), nil
+
+	case *commonv1.Date:
+		if v == nil || v.Year == 0 {
+			return time.Time{}, fmt.Errorf("missing date value")
+		}
+
+		// dates do not have a time so they must not be shifted to
+		// another day by converting them from UTC.
+		return v.AsTimeInLocation(time.Local), nil
+
+	case interface{ AsTime() time.Time }:
+		return v.AsTime().Local(), nil
+
+	default:
+		return time.Time{}, fmt.Errorf("unsupported date value of type %T", v)
+	}
+}
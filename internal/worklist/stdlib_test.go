@@ -0,0 +1,62 @@
+package worklist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dop251/goja"
+	commonv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/common/v1"
+	customerv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/customer/v1"
+)
+
+func TestStdlibFormat(t *testing.T) {
+	// dates must not be shifted to the previous day west of UTC
+	local := time.Local
+	time.Local = time.FixedZone("UTC-5", -5*60*60)
+	defer func() { time.Local = local }()
+
+	rt := goja.New()
+	rt.Set("std", newStdlib(rt))
+	rt.Set("patient", &customerv1.Patient{
+		Birthday: &commonv1.Date{Year: 2020, Month: commonv1.Month_March, Day: 4},
+	})
+	rt.Set("unknown", &customerv1.Patient{
+		Birthday: &commonv1.Date{},
+	})
+
+	cases := []struct {
+		script   string
+		expected string
+		err      bool
+	}{
+		{script: `std.formatDA(patient.Birthday)`, expected: "20200304"},
+		{script: `std.formatDate(patient.Birthday, "02.01.2006")`, expected: "04.03.2020"},
+		{script: `std.formatDA(unknown.Birthday)`, err: true},
+		{script: `std.formatDA(patient.birthday)`, err: true},
+		{script: `std.formatDT("2024-01-02T03:04:05-05:00")`, expected: "20240102030405"},
+		{script: `std.formatTM(new Date(Date.UTC(2024, 0, 2, 8, 30, 0)))`, expected: "033000"},
+		{script: `std.formatDA(Date.UTC(2024, 0, 2, 8))`, expected: "20240102"},
+		{script: `std.formatDA("yesterday")`, err: true},
+	}
+
+	for _, c := range cases {
+		v, err := rt.RunString(c.script)
+
+		if c.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %v", c.script, v)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.script, err)
+			continue
+		}
+
+		if got := v.String(); got != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.script, c.expected, got)
+		}
+	}
+}
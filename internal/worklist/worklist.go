@@ -8,8 +8,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/dop251/goja"
 	"github.com/fsnotify/fsnotify"
 	"github.com/hashicorp/go-multierror"
 	"github.com/suyashkumar/dicom"
@@ -17,30 +19,39 @@ import (
 	customerv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/customer/v1"
 	dicomv1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/dicom/v1"
 	orthanc_bridgev1 "github.com/tierklinik-dobersberg/apis/gen/go/tkd/orthanc_bridge/v1"
-	"github.com/tierklinik-dobersberg/orthanc-bridge/internal/dicomweb"
 )
 
 type Worklist struct {
 	targetDirectory string
 	rulesDirectory  string
-	rt              *goja.Runtime
+	ruleTimeout     time.Duration
 	watcher         *fsnotify.Watcher
 
+	// rules holds the currently active rule set. It is replaced as a whole
+	// when the rule files change.
+	rules atomic.Pointer[ruleSet]
+
+	// reloadLock guards reloadTimer and ruleErr.
+	reloadLock  sync.Mutex
+	reloadTimer *time.Timer
+	ruleErr     error
+
+	// entryLock serializes modifications of existing worklist entries.
+	entryLock sync.Mutex
+
 	onEntryCreate OnCreateCallback
+	onEntryUpdate OnUpdateCallback
 	onEntryRemove OnRemoveCallback
-
-	rules []rule
 }
 
 type OnCreateCallback func(string, dicom.Dataset)
+type OnUpdateCallback func(string, dicom.Dataset)
 type OnRemoveCallback func(string)
 
-type rule struct {
-	name string
-	exec goja.Callable
-}
-
-func New(target, rulesDir string, onCreate OnCreateCallback, onRemove OnRemoveCallback) (*Worklist, error) {
+// New creates a new worklist that writes entries to target using the rules
+// from rulesDir. Each rule must return within ruleTimeout, if zero a default
+// timeout of 5 seconds is used.
+func New(target, rulesDir string, ruleTimeout time.Duration, onCreate OnCreateCallback, onUpdate OnUpdateCallback, onRemove OnRemoveCallback) (*Worklist, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create inotify watcher: %w", err)
@@ -49,15 +60,13 @@ func New(target, rulesDir string, onCreate OnCreateCallback, onRemove OnRemoveCa
 	wl := &Worklist{
 		targetDirectory: target,
 		rulesDirectory:  rulesDir,
+		ruleTimeout:     ruleTimeout,
 		watcher:         watcher,
 		onEntryCreate:   onCreate,
+		onEntryUpdate:   onUpdate,
 		onEntryRemove:   onRemove,
 	}
 
-	if err := wl.initRuntime(); err != nil {
-		return nil, fmt.Errorf("failed to initialize runtime: %w", err)
-	}
-
 	// verify target exists and is a directory
 	stat, err := os.Stat(target)
 	if err != nil {
@@ -67,34 +76,12 @@ func New(target, rulesDir string, onCreate OnCreateCallback, onRemove OnRemoveCa
 		return nil, fmt.Errorf("target %q is not a directory", target)
 	}
 
-	targetFs := os.DirFS(target)
-
-	// read all rule files
-	ruleFiles, err := fs.Glob(targetFs, "*.js")
+	rules, err := loadRules(rulesDir, ruleTimeout)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search for rules: %w", err)
-	}
-	rules := make(map[string]*goja.Program)
-	for _, f := range ruleFiles {
-		content, err := fs.ReadFile(targetFs, f)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read rule file %q: %w", f, err)
-		}
-
-		p, err := goja.Compile(f, string(content), true)
-		if err != nil {
-			return nil, fmt.Errorf("failed to compile rule %q: %w", f, err)
-		}
-
-		rules[f] = p
+		return nil, err
 	}
 
-	// run all rule files
-	for r, p := range rules {
-		if _, err := wl.rt.RunProgram(p); err != nil {
-			return nil, fmt.Errorf("failed to execute rule file %q: %w", r, err)
-		}
-	}
+	wl.rules.Store(rules)
 
 	// start watching rules and target directory
 	wl.watcher.Add(target)
@@ -123,8 +110,15 @@ func (wl *Worklist) watchFs() {
 	for e := range wl.watcher.Events {
 		slog.Info("received inotify event", "path", e.Name, "op", e.Op.String())
 
+		switch filepath.Dir(e.Name) {
 		// Worklist event
-		if strings.HasPrefix(e.Name, wl.targetDirectory) {
+		case filepath.Clean(wl.targetDirectory):
+			// entries are written to hidden temporary files first and
+			// reported by the worklist itself once they are complete.
+			if strings.HasPrefix(filepath.Base(e.Name), ".") {
+				continue
+			}
+
 			switch {
 			case strings.Contains(e.Op.String(), "CLOSE_WRITE"):
 				if wl.onEntryCreate != nil {
@@ -143,16 +137,16 @@ func (wl *Worklist) watchFs() {
 				}
 			}
 
-		} else
-
 		// Rules event
-		if strings.HasPrefix(e.Name, wl.rulesDirectory) {
-			switch {
-			case e.Has(fsnotify.Remove):
-				slog.Info("rule file has been removed", "file", e.Name)
+		case filepath.Clean(wl.rulesDirectory):
+			if filepath.Ext(e.Name) != ".js" {
+				continue
+			}
 
-			case strings.Contains(e.Op.String(), "CLOSE_WRITE"):
-				slog.Info("rule has been written", "file", e.Name)
+			if e.Has(fsnotify.Create) || e.Has(fsnotify.Write) || e.Has(fsnotify.Remove) || e.Has(fsnotify.Rename) {
+				slog.Info("rule file has been changed", "file", e.Name)
+
+				wl.scheduleReload()
 			}
 		}
 	}
@@ -167,7 +161,7 @@ func (e *Entry) ToProto() (*orthanc_bridgev1.WorklistEntry, error) {
 	elements := make([]*dicomv1.Element, 0, len(e.Dataset.Elements))
 
 	for _, el := range e.Dataset.Elements {
-		pb, err := dicomv1.ElementProto(el)
+		pb, err := ElementProto(el)
 		if err != nil {
 			return nil, err
 		}
@@ -211,86 +205,242 @@ func (wl *Worklist) ListEntries() ([]Entry, error) {
 	return entries, merr.ErrorOrNil()
 }
 
+// Generate executes all registered rules in the order they have been
+// registered. Each rule is called with the customer, the patient and the
+// dataset generated so far and may return a list of DICOM elements that
+// are added to the dataset, replacing existing elements with the same tag.
 func (wl *Worklist) Generate(customer *customerv1.Customer, patient *customerv1.Patient, ds dicom.Dataset) (dicom.Dataset, error) {
-	merr := new(multierror.Error)
+	return wl.rules.Load().generate(customer, patient, ds)
+}
 
-	for _, rule := range wl.rules {
-		this := wl.rt.ToValue(nil)
+// mergeElements returns a copy of ds with elements added. Elements of ds
+// with the same tag are replaced.
+func mergeElements(ds dicom.Dataset, elements []*dicom.Element) dicom.Dataset {
+	result := dicom.Dataset{
+		Elements: make([]*dicom.Element, 0, len(ds.Elements)+len(elements)),
+	}
 
-		args := []goja.Value{
-			wl.rt.ToValue(customer),
-			wl.rt.ToValue(patient),
-			wl.rt.ToValue(ds),
+	replaced := make(map[tag.Tag]*dicom.Element, len(elements))
+	for _, el := range elements {
+		if el != nil {
+			replaced[el.Tag] = el
 		}
+	}
 
-		result, err := rule.exec(this, args...)
-		if err != nil {
-			merr.Errors = append(merr.Errors, fmt.Errorf("%s: %w", rule.name, err))
-			continue
+	for _, el := range ds.Elements {
+		if _, ok := replaced[el.Tag]; !ok {
+			result.Elements = append(result.Elements, el)
 		}
+	}
 
-		if result != nil {
-			var resultSet []*dicom.Element
-			if err := wl.rt.ExportTo(result, resultSet); err != nil {
-				merr.Errors = append(merr.Errors, fmt.Errorf("failed to parse rule result %q: %w", rule.name, err))
+	for _, el := range elements {
+		if el != nil && replaced[el.Tag] == el {
+			result.Elements = append(result.Elements, el)
+		}
+	}
+
+	return result
+}
+
+// convertValue converts a value exported from the JavaScript runtime to
+// a type accepted by dicom.NewValue for the given value representation.
+func convertValue(vr string, value any) (any, error) {
+	var values []any
+
+	switch v := value.(type) {
+	case []any:
+		values = v
+	case []string, []int, []float64, []byte, [][]*dicom.Element:
+		return v, nil
+	default:
+		values = []any{v}
+	}
+
+	switch vr {
+	case "SQ":
+		items := make([][]*dicom.Element, 0, len(values))
+		for _, item := range values {
+			var elements []*dicom.Element
+
+			switch iv := item.(type) {
+			case []*dicom.Element:
+				elements = iv
+			case []any:
+				for _, e := range iv {
+					el, ok := e.(*dicom.Element)
+					if !ok {
+						return nil, fmt.Errorf("unexpected sequence item element of type %T", e)
+					}
+
+					elements = append(elements, el)
+				}
+			default:
+				return nil, fmt.Errorf("unexpected sequence item of type %T", item)
 			}
 
-			return dicom.Dataset{
-				Elements: resultSet,
-			}, merr.ErrorOrNil()
+			items = append(items, elements)
+		}
+
+		return items, nil
+
+	case "US", "UL", "SS", "SL", "SV", "UV":
+		ints := make([]int, 0, len(values))
+		for _, v := range values {
+			switch n := v.(type) {
+			case int64:
+				ints = append(ints, int(n))
+			case float64:
+				ints = append(ints, int(n))
+			case int:
+				ints = append(ints, n)
+			default:
+				return nil, fmt.Errorf("unexpected value of type %T for VR %s", v, vr)
+			}
+		}
+
+		return ints, nil
+
+	case "FL", "FD":
+		floats := make([]float64, 0, len(values))
+		for _, v := range values {
+			switch n := v.(type) {
+			case int64:
+				floats = append(floats, float64(n))
+			case float64:
+				floats = append(floats, n)
+			default:
+				return nil, fmt.Errorf("unexpected value of type %T for VR %s", v, vr)
+			}
+		}
+
+		return floats, nil
+
+	default:
+		strs := make([]string, 0, len(values))
+		for _, v := range values {
+			strs = append(strs, fmt.Sprint(v))
 		}
-	}
 
-	return dicom.Dataset{}, merr.ErrorOrNil()
+		return strs, nil
+	}
 }
 
-func (wl *Worklist) initRuntime() error {
-	rt := goja.New()
+// ElementProto converts a DICOM element to it's protobuf representation.
+// Contrary to dicomv1.ElementProto, the content of sequences is kept.
+func ElementProto(el *dicom.Element) (*dicomv1.Element, error) {
+	items, ok := el.Value.GetValue().([]*dicom.SequenceItemValue)
+	if !ok {
+		return dicomv1.ElementProto(el)
+	}
+
+	// populates the tag information but drops the sequence items
+	pb, err := dicomv1.ElementProto(el)
+	if err != nil {
+		return nil, err
+	}
+
+	sequences := &dicomv1.Sequences{
+		Values: make([]*dicomv1.SequenceItem, 0, len(items)),
+	}
 
-	wl.rt = rt
+	for _, item := range items {
+		elements, _ := item.GetValue().([]*dicom.Element)
 
-	wl.rt.Set("rule", wl.registerRule)
-	wl.rt.Set("tag", wl.tag)
+		values := make([]*dicomv1.Element, 0, len(elements))
+		for _, e := range elements {
+			epb, err := ElementProto(e)
+			if err != nil {
+				return nil, err
+			}
 
-	for name := range dicomweb.TagNames {
-		t, err := findTag(name)
-		if err != nil {
-			slog.Error("failed to find tag", "name", name)
-			continue
+			values = append(values, epb)
 		}
 
-		wl.rt.Set(name, t)
+		sequences.Values = append(sequences.Values, &dicomv1.SequenceItem{
+			Values: values,
+		})
 	}
 
-	return nil
-}
+	pb.Value = &dicomv1.Value{
+		Value: &dicomv1.Value_Sequences{
+			Sequences: sequences,
+		},
+	}
 
-func (wl *Worklist) registerRule(name string, exec goja.Callable) {
-	wl.rules = append(wl.rules, rule{
-		name: name,
-		exec: exec,
-	})
+	return pb, nil
 }
 
-func findTag(name string) (tag.Tag, error) {
-	t, err := tag.FindByKeyword(name)
-	if err == nil {
-		return t.Tag, nil
+// ElementFromProto converts a tkd.dicom.v1.Element to a DICOM element. The
+// value representation is always taken from the DICOM dictionary.
+func ElementFromProto(pb *dicomv1.Element) (*dicom.Element, error) {
+	t := tag.Tag{
+		Group:   uint16(pb.Tag >> 16),
+		Element: uint16(pb.Tag),
 	}
 
-	t, err = tag.FindByName(name)
+	var data any
+
+	switch v := pb.GetValue().GetValue().(type) {
+	case *dicomv1.Value_Strings:
+		data = v.Strings.GetValues()
+
+	case *dicomv1.Value_Bytes:
+		data = v.Bytes
+
+	case *dicomv1.Value_Ints:
+		ints := make([]int, 0, len(v.Ints.GetValues()))
+		for _, i := range v.Ints.GetValues() {
+			ints = append(ints, int(i))
+		}
+
+		data = ints
+
+	case *dicomv1.Value_Floats:
+		data = v.Floats.GetValues()
+
+	case *dicomv1.Value_SequenceItem:
+		item, err := elementsFromProto(v.SequenceItem.GetValues())
+		if err != nil {
+			return nil, err
+		}
+
+		data = [][]*dicom.Element{item}
+
+	case *dicomv1.Value_Sequences:
+		items := make([][]*dicom.Element, 0, len(v.Sequences.GetValues()))
+		for _, s := range v.Sequences.GetValues() {
+			item, err := elementsFromProto(s.GetValues())
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+
+		data = items
+
+	default:
+		return nil, fmt.Errorf("%s: unsupported or missing value", t)
+	}
+
+	el, err := dicom.NewElement(t, data)
 	if err != nil {
-		return tag.Tag{}, err
+		return nil, fmt.Errorf("%s: %w", t, err)
 	}
 
-	return t.Tag, nil
+	return el, nil
 }
 
-func (wl *Worklist) tag(name string, value any) (*dicom.Element, error) {
-	t, err := findTag(name)
-	if err != nil {
-		return nil, err
+func elementsFromProto(pbs []*dicomv1.Element) ([]*dicom.Element, error) {
+	elements := make([]*dicom.Element, 0, len(pbs))
+	for _, pb := range pbs {
+		el, err := ElementFromProto(pb)
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, el)
 	}
 
-	return dicom.NewElement(t, value)
+	return elements, nil
 }